@@ -18,12 +18,13 @@ package cmd
 
 import (
 	// standard
-	"bytes"
+	"cli/pkg/vault"
 	"encoding/json"
 	"fmt"
 	"os"
 	// external
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // UpdateADSetting command line arguments
@@ -32,134 +33,49 @@ const (
 	adSettingID              = "ad-setting-id"
 	adSettingUIDAttribute    = "uid-attribute"
 	adSettingType            = "type"
-    adSettingNetBIOSName     = "netbios-name"
+	adSettingNetBIOSName     = "netbios-name"
 	adSettingServiceAccount  = "service-account"
 	adSettingServicePassword = "service-password"
 	adSettingServersJSONFile = "servers-json-file"
 	adSettingRevision        = "revision"
 )
 
-type ADServer struct {
-	ServerURL   string `json:"server_url"`
-	TLS         bool   `json:"tls,omitempty"`
-	UserBaseDN  string `json:"user_base_dn,omitempty"`
-	GroupBaseDN string `json:"group_base_dn,omitempty"`
-	Timeout     int    `json:"timeout,omitempty"`
-	CACert      string `json:"cacert,omitempty"`
-}
-
-// UpdateADSetting REST API Request
-// (and possible for CreateADSetting in the future)
-type ADSetting struct {
-	Name            string          `json:"name,omitempty"`
-	ID              string          `json:"ad_setting_id,omitempty"`
-	Revision        int             `json:"revision,omitempty"`
-	ServiceAccount  string          `json:"service_account,omitempty"`
-	ServicePassword string          `json:"service_password,omitempty"`
-	UIDAttribute    string          `json:"uid_attribute,omitempty"`
-	Type            string          `json:"type,omitempty"`
-	NetBIOSName     string          `json:"netbios_name,omitempty"`
-	Servers         []ADServer `json:"servers,omitempty"`
-}
-
 // listADSettingsCmd represents the list-box command
 var listADSettingsCmd = &cobra.Command{
 	Use:   "list-ad-settings",
 	Short: "List all AD settings",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		// create request payload
-		if flags.Changed("prefix") {
-			prefix, _ := flags.GetString("prefix")
-			params["prefix"] = prefix
-		}
-		
-		if flags.Changed("filters") {
-			filters, _ := flags.GetString("filters")
-			params["filters"] = filters
-		}
-		
-		if flags.Changed("max-items") {
-			maxItems, _ := flags.GetInt("max-items")
-			params["max_items"] = maxItems
-		}
-		
-		if flags.Changed("field") {
-			fieldsArray, _ := flags.GetStringArray("field")
-			params["fields"] = fieldsArray
-		}
-		
-		if flags.Changed("next-token") {
-			nextToken, _ := flags.GetString("next-token")
-			params["next_token"] = nextToken
-		}
 
-		// JSONify
-		jsonParams, err := json.Marshal(params)
+		response, err := GetVault().ListADSettings(Idempotent(cmd.Context()),
+			listOptions(flags, "field"))
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		// now POST
-		endpoint := GetEndPoint("", "1.0", "ListADSettings")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			// type assertion
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAD Settings not found\n")
-				os.Exit(5)
-			}
-
-			fmt.Println("\n" + retStr + "\n")
-
-			// make a decision on what to exit with
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				os.Exit(3)
-			} else {
-				os.Exit(0)
-			}
+			return VaultError(err, "AD Settings not found")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
 func parseServersJSONFile(JSONFile string, v interface{}) error {
 	file, err := os.Open(JSONFile)
 	if err != nil {
-		fmt.Printf("Error opening file %s - %v\n", JSONFile, err)
-		return err
+		return Errorf(ExitError, "Error opening file %s - %v\n", JSONFile, err)
 	}
 	defer file.Close()
 
 	err = json.NewDecoder(file).Decode(v)
 	if err != nil {
-		fmt.Printf("Error decoding Servers JSON - %v\n", err)
-		return err
+		return Errorf(ExitError, "Error decoding Servers JSON - %v\n", err)
 	}
 	return nil
 }
 
-func parseCACertFile(Servers []ADServer) error {
+func parseCACertFile(Servers []vault.ADServer) error {
 	for idx := range Servers {
 		if Servers[idx].CACert != "" {
 			encoded, err := LoadAndEncodeCACertFile(Servers[idx].CACert)
 			if err != nil {
-				fmt.Printf("%v\n", err)
-				return err
+				return Errorf(ExitError, "%v\n", err)
 			}
 			Servers[idx].CACert = encoded
 		}
@@ -170,28 +86,31 @@ func parseCACertFile(Servers []ADServer) error {
 ////////////////////////////////////////////////////////////////////////////////
 // Commmand: update-ad-setting
 
-// UpdateADSetting REST API Response
-type updateADSettingResponse struct {
-	Name     string `json:"name"`
-	ID       string `json:"ad_setting_id"`
-	Revision int    `json:"revision"`
+// serviceAccountPassword returns the --service-password secret,
+// prompted for if a new service account is given without it
+func serviceAccountPassword(flags *pflag.FlagSet, serviceAccount string) (string, error) {
+	prompt := ""
+	if serviceAccount != "" && serviceAccount != "unset" {
+		prompt = "Service Password"
+	}
+	return CommandSecretFlag(flags, adSettingServicePassword, prompt, false)
 }
 
-func updateADSetting(cmd *cobra.Command, args []string) {
+func updateADSetting(cmd *cobra.Command, args []string) error {
 
-	var adSetting ADSetting
+	var adSetting vault.ADSetting
 
 	// Build adSetting from Command Line Arguments
 	flags := cmd.Flags()
 
-	serviceAccountReset := false
 	adSetting.ID, _ = flags.GetString(adSettingID)
 	adSetting.Revision, _ = flags.GetInt(adSettingRevision)
 	adSetting.ServiceAccount, _ = flags.GetString(adSettingServiceAccount)
-	if adSetting.ServiceAccount == "unset" {
-		serviceAccountReset = true
+	var err error
+	adSetting.ServicePassword, err = serviceAccountPassword(flags, adSetting.ServiceAccount)
+	if err != nil {
+		return err
 	}
-	adSetting.ServicePassword, _ = flags.GetString(adSettingServicePassword)
 	adSetting.UIDAttribute, _ = flags.GetString(adSettingUIDAttribute)
 	adSetting.Type, _ = flags.GetString(adSettingType)
 	adSetting.NetBIOSName, _ = flags.GetString(adSettingNetBIOSName)
@@ -199,55 +118,34 @@ func updateADSetting(cmd *cobra.Command, args []string) {
 	// Servers JSON
 	JSONFile, _ := flags.GetString(adSettingServersJSONFile)
 	if JSONFile != "" {
-		err := parseServersJSONFile(JSONFile, &adSetting.Servers)
-		if err != nil {
-			os.Exit(1)
+		if err := parseServersJSONFile(JSONFile, &adSetting.Servers); err != nil {
+			return err
 		}
 		// parse the CACert if provided
-		err = parseCACertFile(adSetting.Servers)
-		if err != nil {
-			os.Exit(1)
+		if err := parseCACertFile(adSetting.Servers); err != nil {
+			return err
 		}
 	}
 
 	// one of the AD Setting attribute must be updated
 	if adSetting.ServiceAccount == "" && adSetting.ServicePassword == "" &&
-        adSetting.UIDAttribute == "" && adSetting.NetBIOSName == "" &&
-	adSetting.Type == "" && len(adSetting.Servers) == 0 {
+		adSetting.UIDAttribute == "" && adSetting.NetBIOSName == "" &&
+		adSetting.Type == "" && len(adSetting.Servers) == 0 {
 		fmt.Printf("\nOne or more parameter(s) is missing. Usage below.\n\n")
 		cmd.Help()
-		os.Exit(1)
-	}
-
-	// Encode request param into JSON
-	jsonParams, err := json.Marshal(adSetting)
-	if err != nil {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
+		return &CommandError{Code: ExitUsage}
 	}
 
 	// this is handle if service account needs to be cleared
-	if serviceAccountReset {
-		params := JsonStrToMap(string(jsonParams))
-		params["service_account"] = ""
-
-		// JSONify
-		jsonParams, err = json.Marshal(params)
-		if (err != nil) {
-		    fmt.Println("Error building JSON request: ", err)
-		    os.Exit(1)
-		}
+	if adSetting.ServiceAccount == "unset" {
+		adSetting.ServiceAccount = ""
+		adSetting.ClearServiceAccount = true
 	}
 
 	// send request
-	var respData updateADSettingResponse
-	endpoint := GetEndPoint("", "1.0", "UpdateADSetting")
-	_, err = DoPost2(endpoint, GetCACertFile(),
-		AuthTokenKV(),
-		jsonParams, ContentTypeJSON, &respData, nil)
+	respData, err := GetVault().UpdateADSetting(cmd.Context(), adSetting)
 	if err != nil {
-		fmt.Printf("\nUpdating Active Directory Setting failed:\n\n%v\n", err)
-		os.Exit(1)
+		return OperationError("Updating Active Directory Setting failed:", err)
 	}
 
 	// print the response
@@ -255,14 +153,15 @@ func updateADSetting(cmd *cobra.Command, args []string) {
 	fmt.Printf("\nRevision : %d\n", respData.Revision)
 	fmt.Printf("Name     : %s\n", respData.Name)
 	fmt.Printf("ID       : %s\n", respData.ID)
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // Commmand: change-ad-domain
 
-func changeADDomain(cmd *cobra.Command, args []string) {
+func changeADDomain(cmd *cobra.Command, args []string) error {
 
-	var adSetting ADSetting
+	var adSetting vault.ADSetting
 
 	// Build adSetting from Command Line Arguments
 	flags := cmd.Flags()
@@ -270,40 +169,30 @@ func changeADDomain(cmd *cobra.Command, args []string) {
 	adSetting.Name, _ = flags.GetString(adDomainName)
 	adSetting.Type, _ = flags.GetString(adSettingType)
 	adSetting.ServiceAccount, _ = flags.GetString(adSettingServiceAccount)
-	adSetting.ServicePassword, _ = flags.GetString(adSettingServicePassword)
+	var err error
+	adSetting.ServicePassword, err = serviceAccountPassword(flags, adSetting.ServiceAccount)
+	if err != nil {
+		return err
+	}
 	adSetting.UIDAttribute, _ = flags.GetString(adSettingUIDAttribute)
 	adSetting.NetBIOSName, _ = flags.GetString(adSettingNetBIOSName)
 
 	// Servers JSON
 	JSONFile, _ := flags.GetString(adSettingServersJSONFile)
 	if JSONFile != "" {
-		err := parseServersJSONFile(JSONFile, &adSetting.Servers)
-		if err != nil {
-			os.Exit(1)
+		if err := parseServersJSONFile(JSONFile, &adSetting.Servers); err != nil {
+			return err
 		}
 		// parse the CACert if provided
-		err = parseCACertFile(adSetting.Servers)
-		if err != nil {
-			os.Exit(1)
+		if err := parseCACertFile(adSetting.Servers); err != nil {
+			return err
 		}
 	}
 
-	// Encode request param into JSON
-	jsonParams, err := json.Marshal(adSetting)
-	if err != nil {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
-	}
-
 	// send request
-	var respData updateADSettingResponse
-	endpoint := GetEndPoint("", "1.0", "ChangeADDomain")
-	_, err = DoPost2(endpoint, GetCACertFile(),
-		AuthTokenKV(),
-		jsonParams, ContentTypeJSON, &respData, nil)
+	respData, err := GetVault().ChangeADDomain(cmd.Context(), adSetting)
 	if err != nil {
-		fmt.Printf("\nChanging AD Domain failed:\n\n%v\n", err)
-		os.Exit(1)
+		return OperationError("Changing AD Domain failed:", err)
 	}
 
 	// print the response
@@ -311,6 +200,7 @@ func changeADDomain(cmd *cobra.Command, args []string) {
 	fmt.Printf("\nRevision : %d\n", respData.Revision)
 	fmt.Printf("Name     : %s\n", respData.Name)
 	fmt.Printf("ID       : %s\n", respData.ID)
+	return nil
 }
 
 func init() {
@@ -335,7 +225,7 @@ func init() {
 	var updateADSettingsCommand = &cobra.Command{
 		Use:   "update-ad-settings",
 		Short: "Update Active Directory Settings",
-		Run:   updateADSetting,
+		RunE:  updateADSetting,
 	}
 	updateADSettingsCommand.Flags().StringP(adSettingID, "a", "",
 		"Active Directory Setting ID or Name")
@@ -346,33 +236,34 @@ func init() {
 	updateADSettingsCommand.Flags().StringP(adSettingNetBIOSName, "n", "",
 		"Active Directory NetBIOS name")
 	updateADSettingsCommand.Flags().StringP(adSettingServiceAccount, "s", "",
-		"Active Directory Service Account User Name. " +
-	        "To clear, set it to \"unset\".")
+		"Active Directory Service Account User Name. "+
+			"To clear, set it to \"unset\".")
 	updateADSettingsCommand.Flags().StringP(adSettingServicePassword, "p", "",
-		"Active Directory Service Account Password")
+		"Active Directory Service Account Password. Prompted for if "+
+			"--"+adSettingServiceAccount+" is given."+SecretUsage)
 	updateADSettingsCommand.Flags().StringP(adSettingServersJSONFile, "j", "",
-		"Active Directory Domain Controller List JSON File. This is to be " +
-        "a array of JSON objects, each object representing a Domain Controller. " +
-        "Following keys are supported, \n" +
-        "server_url (mandatory) full url of the Domain Controller\n" +
-        "cacert (optional) path to CA Certificiate to verify with\n" +
-        "user_base_dn (optional) user base DN\n" +
-        "group_base_dn (optional) group base DN\n" +
-        "timeout (optional) connection timeout in seconds, defaults to 5 seconds\n" +
-        "tls (optional) enable StartTLS or not, defaults to false\n" +
-        "\n" +
-        "Example: \n" +
-        "\n" +
-        "[\n" +
-        "    {\n" +
-        "        \"server_url\": \"ldaps://dc1.mycompany.eng.com\",\n" +
-        "        \"cacert\": \"/root/cacert.pem\",\n" +
-        "        \"user_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n" +
-        "        \"group_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n" +
-        "        \"timeout\": 10,\n" +
-        "        \"tls\": false,\n" +
-        "    }\n" +
-        "]\n")
+		"Active Directory Domain Controller List JSON File. This is to be "+
+			"a array of JSON objects, each object representing a Domain Controller. "+
+			"Following keys are supported, \n"+
+			"server_url (mandatory) full url of the Domain Controller\n"+
+			"cacert (optional) path to CA Certificiate to verify with\n"+
+			"user_base_dn (optional) user base DN\n"+
+			"group_base_dn (optional) group base DN\n"+
+			"timeout (optional) connection timeout in seconds, defaults to 5 seconds\n"+
+			"tls (optional) enable StartTLS or not, defaults to false\n"+
+			"\n"+
+			"Example: \n"+
+			"\n"+
+			"[\n"+
+			"    {\n"+
+			"        \"server_url\": \"ldaps://dc1.mycompany.eng.com\",\n"+
+			"        \"cacert\": \"/root/cacert.pem\",\n"+
+			"        \"user_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n"+
+			"        \"group_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n"+
+			"        \"timeout\": 10,\n"+
+			"        \"tls\": false,\n"+
+			"    }\n"+
+			"]\n")
 	updateADSettingsCommand.Flags().IntP(adSettingRevision, "r", 0,
 		"Active Directory Setting Current Revision")
 	// mark mandatory fields as required
@@ -384,7 +275,7 @@ func init() {
 	var changeADDomainCommand = &cobra.Command{
 		Use:   "change-ad-domain",
 		Short: "Change Active Directory Domain",
-		Run:   changeADDomain,
+		RunE:  changeADDomain,
 	}
 	changeADDomainCommand.Flags().StringP(adDomainName, "d", "",
 		"Active Directory Domain Name")
@@ -395,33 +286,34 @@ func init() {
 	changeADDomainCommand.Flags().StringP(adSettingNetBIOSName, "n", "",
 		"Active Directory NetBIOS name")
 	changeADDomainCommand.Flags().StringP(adSettingServiceAccount, "s", "",
-		"Active Directory Service Account User Name. " +
-	        "To clear, set it to \"unset\".")
+		"Active Directory Service Account User Name. "+
+			"To clear, set it to \"unset\".")
 	changeADDomainCommand.Flags().StringP(adSettingServicePassword, "p", "",
-		"Active Directory Service Account Password")
+		"Active Directory Service Account Password. Prompted for if "+
+			"--"+adSettingServiceAccount+" is given."+SecretUsage)
 	changeADDomainCommand.Flags().StringP(adSettingServersJSONFile, "j", "",
-		"Active Directory Domain Controller List JSON File. This is to be " +
-        "a array of JSON objects, each object representing a Domain Controller. " +
-        "Following keys are supported, \n" +
-        "server_url (mandatory) full url of the Domain Controller\n" +
-        "cacert (optional) path to CA Certificiate to verify with\n" +
-        "user_base_dn (optional) user base DN\n" +
-        "group_base_dn (optional) group base DN\n" +
-        "timeout (optional) connection timeout in seconds, defaults to 5 seconds\n" +
-        "tls (optional) enable StartTLS or not, defaults to false\n" +
-        "\n" +
-        "Example: \n" +
-        "\n" +
-        "[\n" +
-        "    {\n" +
-        "        \"server_url\": \"ldaps://dc1.mycompany.eng.com\",\n" +
-        "        \"cacert\": \"/root/cacert.pem\",\n" +
-        "        \"user_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n" +
-        "        \"group_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n" +
-        "        \"timeout\": 10,\n" +
-        "        \"tls\": false,\n" +
-        "    }\n" +
-        "]\n")
+		"Active Directory Domain Controller List JSON File. This is to be "+
+			"a array of JSON objects, each object representing a Domain Controller. "+
+			"Following keys are supported, \n"+
+			"server_url (mandatory) full url of the Domain Controller\n"+
+			"cacert (optional) path to CA Certificiate to verify with\n"+
+			"user_base_dn (optional) user base DN\n"+
+			"group_base_dn (optional) group base DN\n"+
+			"timeout (optional) connection timeout in seconds, defaults to 5 seconds\n"+
+			"tls (optional) enable StartTLS or not, defaults to false\n"+
+			"\n"+
+			"Example: \n"+
+			"\n"+
+			"[\n"+
+			"    {\n"+
+			"        \"server_url\": \"ldaps://dc1.mycompany.eng.com\",\n"+
+			"        \"cacert\": \"/root/cacert.pem\",\n"+
+			"        \"user_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n"+
+			"        \"group_base_dn\": \"DC=mycompany,DC=eng,DC=com\",\n"+
+			"        \"timeout\": 10,\n"+
+			"        \"tls\": false,\n"+
+			"    }\n"+
+			"]\n")
 	// mark mandatory fields as required
 	changeADDomainCommand.MarkFlagRequired(adDomainName)
 	changeADDomainCommand.MarkFlagRequired(adSettingType)
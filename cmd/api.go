@@ -18,26 +18,24 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"mime/multipart"
 	"net/http"
 	"os"
-	"regexp"
-	"strings"
+	"sync"
+	"time"
 )
 
-// ContentTypeJSON defines JSON content type
-const (
-	ContentTypeJSON = "application/json"
-)
+// GetTLSConfig returns the TLS configuration verifying the vault
+// certificate chain with caCertPool. The certificate must also be
+// issued for the host name or IP address connected to, unless
+// skipHostnameVerify is set.
+func GetTLSConfig(caCertPool *x509.CertPool, skipHostnameVerify bool) *tls.Config {
+	if !skipHostnameVerify {
+		return &tls.Config{RootCAs: caCertPool}
+	}
 
-func GetTLSConfig(caCertPool *x509.CertPool) *tls.Config {
 	return &tls.Config{
 		InsecureSkipVerify: true, // Skip default verification
 		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
@@ -66,345 +64,117 @@ func GetTLSConfig(caCertPool *x509.CertPool) *tls.Config {
 	}
 }
 
-// DoPostFormData sends an API request
-func DoPostFormData(endpoint string,
-	cacert string,
-	headers map[string]string,
-	jsonParams []byte,
-	contentType string) (map[string]interface{}, error) {
-
-	params := map[string]interface{}{}
-	json.Unmarshal(jsonParams, &params)
-	values := map[string]io.Reader{}
-	
-	if _, keyPresent := params["public_key"]; keyPresent {
-		values["public_key"] = mustOpen(params["public_key"].(string))
-	}
-	if _, keyPresent := params["csv_file"]; keyPresent {
-		values["csv_file"] = mustOpen(params["csv_file"].(string))
-	}
-	if _, keyPresent := params["secret_type"]; keyPresent {
-		values["secret_type"] = strings.NewReader(params["secret_type"].(string))
-	}
-	var b bytes.Buffer
-	var err error
-	w := multipart.NewWriter(&b)
-	for key, r := range values {
-		var fw io.Writer
-		if x, ok := r.(io.Closer); ok {
-			defer x.Close()
-		}
-		if x, ok := r.(*os.File); ok {
-			if fw, err = w.CreateFormFile(key, x.Name()); err != nil {
-				// this is to avoid Go's unused error message
-				_ = fw
-				return nil, err
-			}
-		} else {
-			if fw, err = w.CreateFormField(key); err != nil {
-				// this is to avoid Go's unused error message
-				_ = fw
-				return nil, err
-			}
-		}
-		if _, err := io.Copy(fw, r); err != nil {
-			return nil, err
-		}
-	}
-	w.Close()
-	request, err := http.NewRequest("POST", endpoint, &b)
-	if err != nil {
-		return nil, err
-	}
-
-	// close connection once done
-	request.Close = true
-	request.Header.Set("Content-Type", w.FormDataContentType())
-	for header, value := range headers {
-		request.Header.Set(header, value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
-		// Create a CA certificate pool and add cacert to it
-		caCert, err := os.ReadFile(cacert)
-		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	client.Transport = tr
-	ret := map[string]interface{}{}
-	response, err := client.Do(request)
-	if err != nil {
-		return ret, err
-	} else {
-		data, _ := io.ReadAll(response.Body)
-		dst := &bytes.Buffer{}
-		if len(data) != 0 {
-			if err := json.Indent(dst, data, "", "  "); err != nil {
-				// probably this is not of json format, print & exit
-				fmt.Println("\n" + string(data) + "\n")
-				os.Exit(4)
-			}
-		}
-
-		ret["status"] = response.StatusCode
-		ret["data"] = dst
-		return ret, nil
-	}
+// ClientConfig holds the settings a Client is built from
+type ClientConfig struct {
+	// CACertFile is the PEM file the vault certificate is verified
+	// with. When empty, the vault certificate is not verified.
+	CACertFile string
+	// SkipHostnameVerify accepts a vault certificate issued by the CA
+	// for any host
+	SkipHostnameVerify bool
+	// Pins, if not empty, restricts the vault certificates accepted
+	// to those whose public key has one of the given SPKI pins (see
+	// SPKIPin)
+	Pins []string
+	// Certificates are the TLS client certificates presented to the
+	// vault, see LoadClientCertificate
+	Certificates []tls.Certificate
+	// Proxy is the URL of the HTTP proxy requests are sent through,
+	// optionally with user:password@ for proxy authentication. When
+	// empty, the proxy is taken from the environment (HTTPS_PROXY).
+	Proxy string
+	// NoProxy lists the hosts not to be reached through the proxy, in
+	// the format of the NO_PROXY environment variable, which applies
+	// when empty
+	NoProxy string
+	// ConnectTo redirects connections to other addresses
+	ConnectTo []ConnectTo
+	// Timeout bounds each request including reading the response
+	// body. Zero means no timeout.
+	Timeout time.Duration
+	// Retry controls retries of failed requests
+	Retry RetryPolicy
+	// Nodes lists the addresses of the vault cluster nodes in order
+	// of preference. A request for any of them fails over to the
+	// next node when its node cannot be reached.
+	Nodes []string
+	// Logf, if set, is called with progress messages such as
+	// failovers, retries and the node that served a request
+	Logf func(format string, args ...interface{})
 }
 
-func mustOpen(f string) *os.File {
-	r, err := os.Open(f)
-	if err != nil {
-		panic(err)
-	}
-	return r
+// Client sends requests to the vault. A Client is safe for concurrent
+// use and reuses connections across requests, so it should be built
+// once and shared.
+type Client struct {
+	httpClient *http.Client
+	retry      RetryPolicy
+	nodes      []string
+	log        func(format string, args ...interface{})
+
+	mu         sync.Mutex
+	activeNode int // index in nodes of the node that last answered
 }
 
-// DoGet sends an API request
-func DoGet(endpoint string,
-	cacert string,
-	headers map[string]string,
-	jsonParams []byte,
-	contentType string) (map[string]interface{}, error) {
-	request, _ := http.NewRequest("GET", endpoint, bytes.NewBuffer(jsonParams))
-
-	// close connection once done
-	request.Close = true
-	request.Header.Set("Content-Type", contentType)
-	for header, value := range headers {
-		request.Header.Set(header, value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
+// newTLSConfig returns the TLS configuration for config
+func newTLSConfig(config ClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: true}
+	if config.CACertFile != "" {
 		// Create a CA certificate pool and add cacert to it
-		caCert, err := os.ReadFile(cacert)
+		caCert, err := os.ReadFile(config.CACertFile)
 		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("Error reading CA Certificate: %v", err)
 		}
 
 		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	client.Transport = tr
-	ret := map[string]interface{}{}
-	response, err := client.Do(request)
-	if err != nil {
-		return ret, err
-	} else {
-		data, _ := io.ReadAll(response.Body)
-		dst := &bytes.Buffer{}
-		if len(data) != 0 {
-			if err := json.Indent(dst, data, "", "  "); err != nil {
-				// probably this is not of json format, print & exit
-				fmt.Println("\n" + string(data) + "\n")
-				os.Exit(4)
-			}
-		}
-
-		ret["status"] = response.StatusCode
-		ret["data"] = dst
-		return ret, nil
-	}
-}
-
-// DoDelete sends an API request
-func DoDelete(endpoint string,
-	cacert string,
-	headers map[string]string,
-	jsonParams []byte,
-	contentType string) (map[string]interface{}, error) {
-	request, _ := http.NewRequest("DELETE", endpoint, bytes.NewBuffer(jsonParams))
-
-	// close connection once done
-	request.Close = true
-	request.Header.Set("Content-Type", contentType)
-	for header, value := range headers {
-		request.Header.Set(header, value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
-		// Create a CA certificate pool and add cacert to it
-		caCert, err := os.ReadFile(cacert)
-		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No PEM certificates found in %s",
+				config.CACertFile)
 		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		tlsConfig = GetTLSConfig(caCertPool, config.SkipHostnameVerify)
 	}
 
-	client.Transport = tr
-	ret := map[string]interface{}{}
-	response, err := client.Do(request)
-	if err != nil {
-		return ret, err
-	} else {
-		data, _ := io.ReadAll(response.Body)
-		dst := &bytes.Buffer{}
-		if len(data) != 0 {
-			if err := json.Indent(dst, data, "", "  "); err != nil {
-				// probably this is not of json format, print & exit
-				fmt.Println("\n" + string(data) + "\n")
-				os.Exit(4)
-			}
-		}
-
-		ret["status"] = response.StatusCode
-		ret["data"] = dst
-		return ret, nil
+	if len(config.Pins) > 0 {
+		tlsConfig.VerifyConnection = verifyPins(config.Pins)
 	}
+	tlsConfig.Certificates = config.Certificates
+	return tlsConfig, nil
 }
 
-func DoPatch(endpoint string,
-	cacert string,
-	headers map[string]string,
-	jsonParams []byte,
-	contentType string) (map[string]interface{}, error) {
-	request, _ := http.NewRequest("PATCH", endpoint, bytes.NewBuffer(jsonParams))
-
-	request.Close = true
-	request.Header.Set("Content-Type", contentType)
-	for header, value := range headers {
-		request.Header.Set(header, value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
-		caCert, err := os.ReadFile(cacert)
-		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	client.Transport = tr
-	ret := map[string]interface{}{}
-	response, err := client.Do(request)
+// newTransport returns the HTTP transport for config
+func newTransport(config ClientConfig) (*http.Transport, error) {
+	tlsConfig, err := newTLSConfig(config)
 	if err != nil {
-		return ret, err
+		return nil, err
 	}
-	data, _ := io.ReadAll(response.Body)
-	dst := &bytes.Buffer{}
-	if len(data) != 0 {
-		if err := json.Indent(dst, data, "", "  "); err != nil {
-			fmt.Println("\n" + string(data) + "\n")
-			os.Exit(4)
-		}
+	proxy, err := proxyFunc(config)
+	if err != nil {
+		return nil, err
 	}
 
-	ret["status"] = response.StatusCode
-	ret["data"] = dst
-	return ret, nil
+	return &http.Transport{
+		Proxy:               proxy,
+		DialContext:         connectToDialer(config.ConnectTo),
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConnsPerHost: 8,
+	}, nil
 }
 
-// DoPost sends an API request
-func DoPost(endpoint string,
-	cacert string,
-	headers map[string]string,
-	jsonParams []byte,
-	contentType string) (map[string]interface{}, error) {
-	request, _ := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonParams))
-
-	// close connection once done
-	request.Close = true
-	request.Header.Set("Content-Type", contentType)
-	for header, value := range headers {
-		request.Header.Set(header, value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
-		// Create a CA certificate pool and add cacert to it
-		caCert, err := os.ReadFile(cacert)
-		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	client.Transport = tr
-	ret := map[string]interface{}{}
-	response, err := client.Do(request)
+// NewClient builds a Client from config
+func NewClient(config ClientConfig) (*Client, error) {
+	tr, err := newTransport(config)
 	if err != nil {
-		return ret, err
-	} else {
-		data, _ := io.ReadAll(response.Body)
-		dst := &bytes.Buffer{}
-		if len(data) != 0 {
-			if err := json.Indent(dst, data, "", "  "); err != nil {
-				// probably this is not of json format, print & exit
-				fmt.Println("\n" + string(data) + "\n")
-				os.Exit(4)
-			}
-		}
-
-		ret["status"] = response.StatusCode
-		ret["data"] = dst
-		return ret, nil
+		return nil, err
 	}
+
+	return &Client{
+		httpClient: &http.Client{Transport: tr, Timeout: config.Timeout},
+		retry:      config.Retry,
+		nodes:      config.Nodes,
+		log:        config.Logf,
+	}, nil
 }
 
 // APIError contains error details of API request failure
@@ -422,240 +192,3 @@ func (e APIError) Error() string {
 	}
 	return fmt.Sprintf("%s\n%s", e.RequestURL, e.HttpStatus)
 }
-
-func newAPIError(requestURL string, response *http.Response) APIError {
-
-	var apiError APIError
-	apiError.RequestURL = requestURL
-	apiError.HttpStatusCode = response.StatusCode
-	apiError.HttpStatus = response.Status
-	contentType := response.Header.Get("Content-Type")
-	if contentType == ContentTypeJSON {
-		apiError.ErrorJSON, _ = io.ReadAll(response.Body)
-	}
-	return apiError
-}
-
-// ResponseHandler can process the HTTP Response fully
-type ResponseHandler interface {
-	ProcessResponse(response *http.Response, responseData interface{},
-		request *http.Request, requestURL string) (interface{}, error)
-}
-
-// DoPost2 sends an API request
-func DoPost2(endpoint string,
-	cacert string,
-	headers map[string]string,
-	jsonParams []byte,
-	contentType string,
-	responseData interface{},
-	respCallback ResponseHandler) (interface{}, error) {
-
-	request, _ := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonParams))
-
-	// close connection once done
-	request.Close = true
-	request.Header.Set("Content-Type", contentType)
-	for header, value := range headers {
-		request.Header.Set(header, value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
-		// Create a CA certificate pool and add cacert to it
-		caCert, err := os.ReadFile(cacert)
-		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	client.Transport = tr
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-
-	// invoke ResponseHandler if provided
-	if cb, ok := respCallback.(ResponseHandler); ok {
-		return cb.ProcessResponse(response, responseData, request, endpoint)
-	}
-
-	switch response.StatusCode {
-	case http.StatusOK, http.StatusCreated:
-		if responseData == nil {
-			return io.ReadAll(response.Body)
-		}
-
-		contentType := response.Header.Get("Content-Type")
-		if contentType != ContentTypeJSON {
-			return nil, fmt.Errorf("Invalid Content-Type: %v", contentType)
-		}
-		return nil, json.NewDecoder(response.Body).Decode(responseData)
-
-	default:
-		return nil, newAPIError(endpoint, response)
-	}
-}
-
-// DoDownload sends an API request
-func DoDownload(endpoint string,
-	method string,
-	cacert string,
-	headers map[string]string,
-	jsonParams []byte) (string, error) {
-	request, _ := http.NewRequest(method, endpoint, bytes.NewBuffer(jsonParams))
-	// close connection once done
-	request.Close = true
-
-	for header, value := range headers {
-		request.Header.Set(header, value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
-		// Create a CA certificate pool and add cacert to it
-		caCert, err := os.ReadFile(cacert)
-		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	client.Transport = tr
-	response, err := client.Do(request)
-	if err != nil {
-		return "", err
-	} else {
-		// server responded with some error - capture & exit
-		if response.StatusCode != 200 {
-			data, _ := io.ReadAll(response.Body)
-			fmt.Println("\n" + string(data) + "\n")
-			os.Exit(3)
-		}
-
-		fname, err := GetValueFromKVString(
-			response.Header.Get("Content-Disposition"), "filename")
-		if err != nil {
-			return "", err
-		}
-
-		// create file
-		outFile, err := os.Create(fname)
-		if err != nil {
-			return "", err
-		}
-		defer outFile.Close()
-
-		// write body to file
-		_, err = io.Copy(outFile, response.Body)
-		if err != nil {
-			return "", err
-		}
-		return fname, nil
-	}
-}
-
-// DoGetDownload sends an API request
-func DoGetDownload(endpoint string,
-	cacert string,
-	headers map[string]string) (string, error) {
-	request, _ := http.NewRequest("GET", endpoint, nil)
-	// close connection once done
-	request.Close = true
-
-	for header, value := range headers {
-		request.Header.Set(string(header), value)
-	}
-
-	client := &http.Client{}
-	tr := &http.Transport{}
-	if cacert != "" {
-		// Create a CA certificate pool and add cacert to it
-		caCert, err := os.ReadFile(cacert)
-		if err != nil {
-			fmt.Println("Error reading CA Certificate: ", err)
-			os.Exit(1)
-		}
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM(caCert)
-		tr.TLSClientConfig = GetTLSConfig(caCertPool)
-	} else {
-		fmt.Println("\n###############################################################################\n" +
-			"Insecure request. Entrust Vault certificate not verified. \n" +
-			"It is strongly recommended to verify the same by specifying CA \n" +
-			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
-			"###############################################################################")
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	client.Transport = tr
-	response, err := client.Do(request)
-	if err != nil {
-		return "", err
-	} else {
-		// server responded with some error - capture & exit
-		if response.StatusCode != 200 {
-			data, _ := io.ReadAll(response.Body)
-			fmt.Println("\n" + string(data) + "\n")
-			os.Exit(3)
-		}
-
-		fname, err := GetValueFromKVString(
-			response.Header.Get("Content-Disposition"), "filename")
-		if err != nil {
-			return "", err
-		}
-
-		// create file
-		outFile, err := os.Create(fname)
-		if err != nil {
-			return "", err
-		}
-		defer outFile.Close()
-
-		// write body to file
-		_, err = io.Copy(outFile, response.Body)
-		if err != nil {
-			return "", err
-		}
-		return fname, nil
-	}
-}
-
-func GetValueFromKVString(kvString string, key string) (string, error) {
-	regex := fmt.Sprintf("(%s)=([a-z]+)", key)
-	re := regexp.MustCompile(regex)
-	result := re.FindAllStringSubmatchIndex(kvString, -1)
-	if len(result) == 0 || len(result[0]) < 5 {
-		errMsg := fmt.Sprintf("%s not found", key)
-		return "", errors.New(errMsg)
-	}
-	match := result[0]
-	return string(kvString[match[4]:]), nil
-}
@@ -0,0 +1,87 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// ErrClientCertPassword is returned by LoadClientCertificate when a
+// PKCS#12 client certificate cannot be decrypted with the password
+var ErrClientCertPassword = errors.New("incorrect client certificate password")
+
+// IsPKCS12 reports whether certFile holds a PKCS#12 (.p12/.pfx)
+// bundle rather than PEM data
+func IsPKCS12(certFile string) (bool, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Contains(data, []byte("-----BEGIN")), nil
+}
+
+// LoadClientCertificate loads the TLS client certificate presented to
+// the vault. certFile holds either a PEM certificate chain, whose
+// private key is read from the PEM keyFile, or a PKCS#12 bundle with
+// the key and chain, decrypted with password. keyFile may be omitted
+// if certFile holds both the PEM certificate and key.
+func LoadClientCertificate(certFile, keyFile, password string) (tls.Certificate, error) {
+	isPKCS12, err := IsPKCS12(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("Error reading client certificate - %v", err)
+	}
+
+	if !isPKCS12 {
+		if keyFile == "" {
+			keyFile = certFile
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return cert, fmt.Errorf("Error loading client certificate %s - %v",
+				certFile, err)
+		}
+		return cert, nil
+	}
+
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	key, leaf, caCerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return tls.Certificate{}, ErrClientCertPassword
+		}
+		return tls.Certificate{}, fmt.Errorf("Error decoding PKCS#12 client "+
+			"certificate %s - %v", certFile, err)
+	}
+
+	cert := tls.Certificate{
+		Certificate: [][]byte{leaf.Raw},
+		PrivateKey:  key,
+		Leaf:        leaf}
+	for _, caCert := range caCerts {
+		cert.Certificate = append(cert.Certificate, caCert.Raw)
+	}
+	return cert, nil
+}
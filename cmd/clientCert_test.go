@@ -0,0 +1,268 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/term"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const testClientCertPassword = "client secret"
+
+// testClientCert holds the files of a client certificate issued by a
+// test CA
+type testClientCert struct {
+	ca       *x509.Certificate
+	certFile string // PEM certificate
+	keyFile  string // PEM private key
+	pemFile  string // PEM certificate and key
+	p12File  string // PKCS#12 bundle with the CA, testClientCertPassword
+}
+
+// newTestClientCert issues a client certificate for user and writes
+// it in every format LoadClientCertificate reads
+func newTestClientCert(t *testing.T, user string) *testClientCert {
+	t.Helper()
+	issue := func(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (
+		*x509.Certificate, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parent == nil {
+			parent, parentKey = template, key
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(),
+			parentKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert, key
+	}
+	ca, caKey := issue(&x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Client CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, nil)
+	leaf, key := issue(&x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: user},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	p12, err := pkcs12.Modern.Encode(key, leaf, []*x509.Certificate{ca},
+		testClientCertPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	cert := &testClientCert{ca: ca,
+		certFile: filepath.Join(dir, "client.crt"),
+		keyFile:  filepath.Join(dir, "client.key"),
+		pemFile:  filepath.Join(dir, "client.pem"),
+		p12File:  filepath.Join(dir, "client.p12")}
+	for file, data := range map[string][]byte{
+		cert.certFile: certPEM,
+		cert.keyFile:  keyPEM,
+		cert.pemFile:  append(append([]byte{}, certPEM...), keyPEM...),
+		cert.p12File:  p12,
+	} {
+		if err = os.WriteFile(file, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cert
+}
+
+// newClientAuthServer starts a server requiring a client certificate
+// issued by ca, which answers with the common name of the certificate
+func newClientAuthServer(t *testing.T, ca *x509.Certificate) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+		}))
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	// the handshakes the tests expect to fail need not be logged
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// getUser returns the user the server authenticated a request with
+// cert, if not nil, as
+func getUser(t *testing.T, server *httptest.Server, cert *tls.Certificate) (string, error) {
+	t.Helper()
+	config := ClientConfig{}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+	client, _ := newTestClient(t, config)
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet,
+		server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.doWithRetry(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	user, err := io.ReadAll(response.Body)
+	return string(user), err
+}
+
+func TestLoadClientCertificate(t *testing.T) {
+	cert := newTestClientCert(t, "alice")
+	server := newClientAuthServer(t, cert.ca)
+
+	for _, test := range []struct {
+		name              string
+		certFile, keyFile string
+		chain             int
+	}{
+		{"pem", cert.certFile, cert.keyFile, 1},
+		{"pem-combined", cert.pemFile, "", 1},
+		{"pkcs12", cert.p12File, "", 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			loaded, err := LoadClientCertificate(test.certFile, test.keyFile,
+				testClientCertPassword)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(loaded.Certificate) != test.chain {
+				t.Errorf("chain of %d certificates, want %d", len(loaded.Certificate),
+					test.chain)
+			}
+			if user, err := getUser(t, server, &loaded); err != nil || user != "alice" {
+				t.Errorf("authenticated as %q, %v, want alice", user, err)
+			}
+		})
+	}
+
+	if _, err := getUser(t, server, nil); err == nil {
+		t.Error("the server accepted a request without a client certificate")
+	}
+}
+
+func TestLoadClientCertificateErrors(t *testing.T) {
+	cert := newTestClientCert(t, "alice")
+	other := newTestClientCert(t, "bob")
+
+	if isPKCS12, err := IsPKCS12(cert.p12File); err != nil || !isPKCS12 {
+		t.Errorf("IsPKCS12(%s) = %t, %v", cert.p12File, isPKCS12, err)
+	}
+	if isPKCS12, err := IsPKCS12(cert.pemFile); err != nil || isPKCS12 {
+		t.Errorf("IsPKCS12(%s) = %t, %v", cert.pemFile, isPKCS12, err)
+	}
+
+	for _, password := range []string{"", "wrong"} {
+		_, err := LoadClientCertificate(cert.p12File, "", password)
+		if !errors.Is(err, ErrClientCertPassword) {
+			t.Errorf("password %q: got %v, want %v", password, err, ErrClientCertPassword)
+		}
+	}
+	if _, err := LoadClientCertificate(cert.certFile, other.keyFile, ""); err == nil {
+		t.Error("loaded a certificate with the key of another")
+	}
+	if _, err := LoadClientCertificate(cert.certFile, "", ""); err == nil {
+		t.Error("loaded a PEM certificate without its key")
+	}
+	missing := filepath.Join(t.TempDir(), "missing.p12")
+	if _, err := LoadClientCertificate(missing, "", ""); err == nil {
+		t.Errorf("loaded the missing %s", missing)
+	}
+}
+
+func TestClientCertificatePassword(t *testing.T) {
+	cert := newTestClientCert(t, "alice")
+	info := tokenInfo{ClientCertFile: cert.p12File}
+	saved := gClientCertPassword
+	t.Cleanup(func() { gClientCertPassword = saved })
+
+	for _, test := range []struct {
+		name, flag, env string
+		ok, prompted    bool
+	}{
+		{"flag", testClientCertPassword, "wrong", true, false},
+		{"flag-wrong", "wrong", testClientCertPassword, false, false},
+		{"env", "", testClientCertPassword, true, false},
+		{"prompt", "", "", false, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if test.prompted && term.IsTerminal(0) {
+				t.Skip("the prompt would wait for a password from the terminal")
+			}
+			gClientCertPassword = test.flag
+			t.Setenv(ClientCertPasswordEnv, test.env)
+
+			var err error
+			stdout, _ := capture(t, func() {
+				_, err = loadClientCertificate(info)
+			})
+			// without a terminal the prompt reads an empty password
+			if test.ok != (err == nil) {
+				t.Errorf("got %v, want success %t", err, test.ok)
+			}
+			prompted := strings.Contains(stdout, "Password for client certificate")
+			if prompted != test.prompted {
+				t.Errorf("prompted %t, want %t: %q", prompted, test.prompted, stdout)
+			}
+		})
+	}
+}
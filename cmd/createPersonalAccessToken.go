@@ -17,27 +17,22 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
-	"os"
 	"time"
 )
 
 var createPersonalAccessTokenCmd = &cobra.Command{
 	Use:   "create-personal-access-token",
 	Short: "Create a Personal Access Token",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		name, _ := flags.GetString("name")
-		params["name"] = name
+		token := vault.PersonalAccessToken{}
+		token.Name, _ = flags.GetString("name")
 
 		if flags.Changed("description") {
 			description, _ := flags.GetString("description")
-			params["description"] = description
+			token.Description = &description
 		}
 
 		expiry, _ := flags.GetString("expiry")
@@ -46,42 +41,14 @@ var createPersonalAccessTokenCmd = &cobra.Command{
 		if e != nil {
 			panic("Can't parse time format\n")
 		}
-		params["expiry"] = thetime.Unix()
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
+		expiryTime := thetime.Unix()
+		token.Expiry = &expiryTime
 
-		endpoint := GetEndPoint("", "1.0", "CreatePersonalAccessToken")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().CreatePersonalAccessToken(cmd.Context(), token)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
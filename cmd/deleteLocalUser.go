@@ -18,74 +18,37 @@ package cmd
 
 import (
 	// standard
-	"os"
 	"fmt"
-	"bytes"
-	"encoding/json"
 	// external
 	"github.com/spf13/cobra"
 )
 
-
 // deleteUserCmd represents the delete-local-user command
 var deleteUserCmd = &cobra.Command{
-	Use:	"delete-local-user",
-	Short:	"Delete a Local User",
-	Run: func(cmd *cobra.Command, args []string) {
-	    flags := cmd.Flags()
-	    params := map[string]interface{}{}
-
-	    // create request payload
-	    user, _ := flags.GetString("user")
-	    charCheck(len(user))
-	    params["username"] = user
-
-		// JSONify
-	    jsonParams, err := json.Marshal(params)
-	    if (err != nil) {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
-	    }
-
-		// now POST
-	    endpoint := GetEndPoint("", "1.0", "DeleteLocalUser")
-	    ret, err := DoPost(endpoint,
-	                        	GetCACertFile(),
-								AuthTokenKV(),
-								jsonParams,
-								"application/json")
-	   if err != nil {
-	    	fmt.Printf("\nHTTP request failed: %s\n", err)
-	    	os.Exit(4)
-	   } else {
-	    	// type assertion
-	    	retBytes := ret["data"].(*bytes.Buffer)
-	    	retStatus := ret["status"].(int)
-	    	retStr := retBytes.String()
-
-	    	if (retStr == "" && retStatus == 404) {
-		    	fmt.Println("\nAction denied\n")
-		   		os.Exit(5)
-	    	}
-
-	    	// make a decision on what to exit with
-	    	retMap := JsonStrToMap(retStr)
-	    	if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-	    	} else {
-				fmt.Println("Local user successfully deleted for with username/ID", user, "\n")
-				os.Exit(0)
-	    	}
-        }
+	Use:   "delete-local-user",
+	Short: "Delete a Local User",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		user, _ := flags.GetString("user")
+		if err := charCheck(len(user)); err != nil {
+			return err
+		}
+
+		_, err := GetVault().DeleteLocalUser(cmd.Context(), user)
+		if err != nil {
+			return VaultError(err, "")
+		}
+		return PrintStatus(fmt.Sprintf(
+			"Local user successfully deleted for with username/ID %s\n\n", user),
+			map[string]string{"user": user, "status": "deleted"})
 	},
 }
 
 func init() {
-    rootCmd.AddCommand(deleteUserCmd)
-    deleteUserCmd.Flags().StringP("user", "u", "",
-                                  "Username of the user to be deleted.")
+	rootCmd.AddCommand(deleteUserCmd)
+	deleteUserCmd.Flags().StringP("user", "u", "",
+		"Username of the user to be deleted.")
 
-    // mark mandatory fields as required
-    deleteUserCmd.MarkFlagRequired("user")
+	// mark mandatory fields as required
+	deleteUserCmd.MarkFlagRequired("user")
 }
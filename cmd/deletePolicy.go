@@ -17,13 +17,6 @@ limitations under the License.
 package cmd
 
 import (
-	// standard
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
-
-	// external
 	"github.com/spf13/cobra"
 )
 
@@ -31,57 +24,16 @@ import (
 var deletePolicyCmd = &cobra.Command{
 	Use:   "delete-policy",
 	Short: "Delete Policy",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		// create request payload
 		policyid, _ := flags.GetString("policyid")
-		params["policy_id"] = policyid
-
-		// JSONify
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
 
-		// now POST
-		endpoint := GetEndPoint("", "1.0", "DeletePolicy")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		_, err := GetVault().DeleteAccessPolicy(cmd.Context(), policyid)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			// type assertion
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nPolicy not found\n")
-				os.Exit(5)
-			}
-
-			if retStatus == 200 {
-				fmt.Println("\nPolicy deleted successfully\n")
-				os.Exit(0)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-
-				// make a decision on what to exit with
-				retMap := JsonStrToMap(retStr)
-				if _, present := retMap["error"]; present {
-					os.Exit(3)
-				} else {
-					os.Exit(100)
-				}
-			}
+			return VaultError(err, "Policy not found")
 		}
+		return PrintStatus("\nPolicy deleted successfully\n\n",
+			map[string]string{"policy_id": policyid, "status": "deleted"})
 	},
 }
 
@@ -17,34 +17,27 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    // external
-    "github.com/spf13/cobra"
+	"github.com/spf13/cobra"
 )
 
-
 // downloadAuditCmd represents the download-audit command
 var downloadAuditCmd = &cobra.Command{
-    Use:   "download-audit",
-    Short: "Download audit log bundle",
-    Run: func(cmd *cobra.Command, args []string) {
-
-        // now GET
-        endpoint := GetEndPoint("", "1.0", "GetAuditBundle")
-        fname, err := DoGetDownload(endpoint, GetCACertFile(),
-                      AuthTokenKV())
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            fmt.Println("\nSuccessfully downloaded audit log bundle " +
-              "as - " + fname + "\n")
-        }
-    },
+	Use:   "download-audit",
+	Short: "Download audit log bundle",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := GetVault().DownloadAuditBundle(cmd.Context())
+		if err != nil {
+			return VaultError(err, "")
+		}
+		fname, err := SaveDownload(file)
+		if err != nil {
+			return OperationError("Saving audit log bundle failed:", err)
+		}
+		return PrintStatus("\nSuccessfully downloaded audit log bundle as - "+fname+"\n\n",
+			map[string]string{"file": fname})
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(downloadAuditCmd)
+	rootCmd.AddCommand(downloadAuditCmd)
 }
@@ -0,0 +1,209 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Exit codes of all commands. Scripts may rely on them, so existing
+// codes must not change meaning.
+const (
+	ExitSuccess          = 0
+	ExitError            = 1 // local failure, e.g. reading a file
+	ExitUsage            = 2 // invalid or missing options or arguments
+	ExitServerError      = 3 // the vault failed or rejected the request
+	ExitNetworkError     = 4 // the vault could not be reached
+	ExitPermissionDenied = 5 // the user may not perform the action
+	ExitNotFound         = 6 // the resource does not exist
+	ExitConflict         = 7 // the resource exists or its revision changed
+	ExitAuthExpired      = 8 // the session expired or the token is invalid
+)
+
+// Error formats of the --error-format option
+const (
+	ErrorFormatText = "text"
+	ErrorFormatJSON = "json"
+)
+
+var gErrorFormat = ErrorFormatText
+
+var exitCodeNames = map[int]string{
+	ExitError:            "error",
+	ExitUsage:            "usage",
+	ExitServerError:      "server_error",
+	ExitNetworkError:     "network_error",
+	ExitPermissionDenied: "permission_denied",
+	ExitNotFound:         "not_found",
+	ExitConflict:         "conflict",
+	ExitAuthExpired:      "auth_expired",
+}
+
+// CommandError is the error a command fails with. Message is printed
+// as commands always printed their errors, and Code is the exit code.
+type CommandError struct {
+	Code     int
+	Message  string
+	APIError *APIError // the failed request, if any
+}
+
+func (e *CommandError) Error() string {
+	return strings.TrimSpace(e.Message)
+}
+
+// errorReport is written to stderr with --error-format=json
+type errorReport struct {
+	ExitCode    int             `json:"exit_code"`
+	Error       string          `json:"error"`
+	Message     string          `json:"message,omitempty"`
+	URL         string          `json:"url,omitempty"`
+	Status      int             `json:"status,omitempty"`
+	HTTPStatus  string          `json:"http_status,omitempty"`
+	ServerError json.RawMessage `json:"server_error,omitempty"`
+}
+
+// ValidateErrorFormat checks the --error-format option
+func ValidateErrorFormat() error {
+	if gErrorFormat != ErrorFormatText && gErrorFormat != ErrorFormatJSON {
+		return fmt.Errorf("Invalid error format %q - use text or json", gErrorFormat)
+	}
+	return nil
+}
+
+// ExitCodeForStatus maps the HTTP status of a failed request to an
+// exit code
+func ExitCodeForStatus(status int) int {
+	switch status {
+	case http.StatusUnauthorized:
+		return ExitAuthExpired
+	case http.StatusForbidden:
+		return ExitPermissionDenied
+	case http.StatusNotFound:
+		return ExitNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return ExitConflict
+	}
+	return ExitServerError
+}
+
+// commandFailed returns the CommandError of a command failing with
+// code
+func commandFailed(code int, message string, apiError *APIError) error {
+	return &CommandError{Code: code, Message: message, APIError: apiError}
+}
+
+// Errorf returns the CommandError of a command failing with code and
+// the message formatted from format and args
+func Errorf(code int, format string, args ...interface{}) error {
+	return commandFailed(code, fmt.Sprintf(format, args...), nil)
+}
+
+// ReportError reports the failure of a command with err and returns
+// its exit code. The text format prints the message to stdout, as
+// commands always did.
+func ReportError(err error) int {
+	var cmdError *CommandError
+	if !errors.As(err, &cmdError) {
+		cmdError = &CommandError{Code: ExitError, Message: fmt.Sprintf("\n%v\n\n", err)}
+	}
+	if gErrorFormat == ErrorFormatJSON {
+		report := errorReport{
+			ExitCode: cmdError.Code,
+			Error:    exitCodeNames[cmdError.Code],
+			Message:  strings.TrimSpace(cmdError.Message)}
+		if apiError := cmdError.APIError; apiError != nil {
+			report.URL = apiError.RequestURL
+			report.Status = apiError.HttpStatusCode
+			report.HTTPStatus = apiError.HttpStatus
+			if json.Valid(apiError.ErrorJSON) {
+				report.ServerError = apiError.ErrorJSON
+				if report.Message == strings.TrimSpace(string(apiError.ErrorJSON)) {
+					// already given as server_error
+					report.Message = ""
+				}
+			}
+		}
+		out, _ := json.Marshal(report)
+		fmt.Fprintln(os.Stderr, string(out))
+	} else {
+		fmt.Print(cmdError.Message)
+	}
+	return cmdError.Code
+}
+
+// errorExitCode returns the exit code for err, or fallback if it is
+// neither an error response nor a network error
+func errorExitCode(err error, fallback int) (int, *APIError) {
+	var apiError APIError
+	var vaultError *vault.Error
+	var urlError *url.Error
+	var netError net.Error
+	switch {
+	case errors.As(err, &apiError):
+		return ExitCodeForStatus(apiError.HttpStatusCode), &apiError
+	case errors.As(err, &vaultError):
+		return ExitCodeForStatus(vaultError.StatusCode), vaultAPIError(vaultError)
+	case errors.Is(err, context.Canceled):
+		return ExitError, nil
+	case errors.As(err, &urlError), errors.As(err, &netError):
+		return ExitNetworkError, nil
+	}
+	return fallback, nil
+}
+
+// RequestFailedError is the error of a request that got no usable
+// response
+func RequestFailedError(err error) error {
+	code, apiError := errorExitCode(err, ExitServerError)
+	return commandFailed(code, fmt.Sprintf("\nHTTP request failed: %s\n", err), apiError)
+}
+
+// OperationError is the error of an operation that failed with err,
+// printed after prefix, e.g. "Login failed:"
+func OperationError(prefix string, err error) error {
+	code, apiError := errorExitCode(err, ExitError)
+	return commandFailed(code, fmt.Sprintf("\n%s\n%v\n", prefix, err), apiError)
+}
+
+// ErrorResponseError is the error of an answer with the error JSON of
+// apiError, e.g. to a download request
+func ErrorResponseError(apiError APIError) error {
+	code := ExitServerError
+	if apiError.HttpStatusCode >= 400 {
+		code = ExitCodeForStatus(apiError.HttpStatusCode)
+	}
+	return commandFailed(code, "\n"+string(apiError.ErrorJSON)+"\n\n", &apiError)
+}
+
+// InvalidResponseError is the error of an answer that cannot be parsed
+func InvalidResponseError(message string) error {
+	return commandFailed(ExitServerError, message, nil)
+}
+
+// UsageError is the error of invalid or missing options
+func UsageError(format string, args ...interface{}) error {
+	return Errorf(ExitUsage, format, args...)
+}
@@ -0,0 +1,97 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"net/http"
+	"strings"
+)
+
+// nodeIndex returns the position of host in the Client's node list,
+// or -1 if host is not a cluster node
+func (c *Client) nodeIndex(host string) int {
+	for idx, node := range c.nodes {
+		if strings.EqualFold(node, host) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// logf reports progress if the Client was configured with a Logf
+func (c *Client) logf(format string, args ...interface{}) {
+	if c.log != nil {
+		c.log(format, args...)
+	}
+}
+
+// doWithFailover sends request to the node that last answered and,
+// if it cannot be reached, to the other cluster nodes in order.
+// Requests for hosts outside the node list are sent as is.
+func (c *Client) doWithFailover(request *http.Request) (*http.Response, error) {
+	if c.nodeIndex(request.URL.Host) < 0 {
+		return c.httpClient.Do(request)
+	}
+
+	c.mu.Lock()
+	first := c.activeNode
+	c.mu.Unlock()
+
+	var lastErr error
+	for i := 0; i < len(c.nodes); i++ {
+		idx := (first + i) % len(c.nodes)
+		node := c.nodes[idx]
+
+		attempt := request
+		if i > 0 || node != request.URL.Host {
+			attempt = request.Clone(request.Context())
+			attempt.URL.Host = node
+			attempt.Host = ""
+			if request.GetBody != nil {
+				body, err := request.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attempt.Body = body
+			} else if request.Body != nil && i > 0 {
+				// the body was consumed by the failed attempt
+				break
+			}
+		}
+
+		response, err := c.httpClient.Do(attempt)
+		if err == nil {
+			c.mu.Lock()
+			c.activeNode = idx
+			c.mu.Unlock()
+			c.logf("Request served by node %s\n", node)
+			return response, nil
+		}
+		lastErr = err
+
+		// a request that may have reached the node is not sent again
+		// unless it is safe to repeat
+		if request.Context().Err() != nil || isCertificateError(err) ||
+			!(c.retry.RetryNonIdempotent || isIdempotent(request) || isDialError(err)) {
+			return nil, err
+		}
+		if i+1 < len(c.nodes) {
+			c.logf("Node %s unreachable - %v\n", node, err)
+		}
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,218 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testNode is a cluster node recording the requests it served
+type testNode struct {
+	*httptest.Server
+	host string
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+}
+
+// newTestNode starts a node answering with 200, or dropping the
+// connection without an answer if hangUp is set
+func newTestNode(t *testing.T, hangUp bool) *testNode {
+	node := &testNode{}
+	node.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			node.mu.Lock()
+			node.requests = append(node.requests, r)
+			node.bodies = append(node.bodies, string(body))
+			node.mu.Unlock()
+			if hangUp {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+			}
+		}))
+	t.Cleanup(node.Close)
+	address, _ := url.Parse(node.URL)
+	node.host = address.Host
+	return node
+}
+
+// newDownNode returns a node which cannot be reached
+func newDownNode(t *testing.T) *testNode {
+	node := newTestNode(t, false)
+	node.Close()
+	return node
+}
+
+func (n *testNode) served() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.requests)
+}
+
+// testLog collects the progress messages of a Client
+type testLog struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *testLog) logf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+// count returns the number of messages containing text
+func (l *testLog) count(text string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, message := range l.messages {
+		if strings.Contains(message, text) {
+			n++
+		}
+	}
+	return n
+}
+
+// newFailoverClient builds a Client for the cluster nodes whose
+// messages go to the returned log
+func newFailoverClient(t *testing.T, nodes ...string) (*Client, *testLog) {
+	t.Helper()
+	log := &testLog{}
+	client, _ := newTestClient(t, ClientConfig{Nodes: nodes, Logf: log.logf})
+	return client, log
+}
+
+func TestFailoverOrder(t *testing.T) {
+	const body = `{"tokenData":"123"}`
+	down1, down2, up := newDownNode(t), newDownNode(t), newTestNode(t, false)
+	client, log := newFailoverClient(t, down1.host, down2.host, up.host)
+
+	// a request that never reached a node fails over even if it is not
+	// idempotent, and the clone sent to the next node carries the body
+	status, err := send(t, client, context.Background(), http.MethodPost,
+		down1.URL+"/vault/1.0/Tokenize/", body)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("got %d, %v, want 200", status, err)
+	}
+	if up.served() != 1 {
+		t.Fatalf("%s served %d requests, want 1", up.host, up.served())
+	}
+	request := up.requests[0]
+	if request.Host != up.host || request.URL.Path != "/vault/1.0/Tokenize/" ||
+		up.bodies[0] != body {
+		t.Errorf("%s received %s %s with %q", up.host, request.Host, request.URL.Path,
+			up.bodies[0])
+	}
+	want := []string{
+		"Node " + down1.host + " unreachable",
+		"Node " + down2.host + " unreachable",
+		"Request served by node " + up.host,
+	}
+	if len(log.messages) != len(want) {
+		t.Fatalf("logged %q, want %q", log.messages, want)
+	}
+	for i, message := range want {
+		if !strings.HasPrefix(log.messages[i], message) {
+			t.Errorf("logged %q, want %q", log.messages, want)
+		}
+	}
+
+	// the node that answered is tried first from then on
+	log.messages = nil
+	status, err = send(t, client, context.Background(), http.MethodGet, down1.URL, "")
+	if err != nil || status != http.StatusOK || up.served() != 2 {
+		t.Fatalf("got %d, %v, %s served %d requests", status, err, up.host, up.served())
+	}
+	if log.count("unreachable") != 0 || log.count("served by node "+up.host) != 1 {
+		t.Errorf("logged %q, want the request served by %s at once", log.messages,
+			up.host)
+	}
+}
+
+func TestFailoverAllDown(t *testing.T) {
+	down1, down2 := newDownNode(t), newDownNode(t)
+	client, _ := newFailoverClient(t, down1.host, down2.host)
+
+	_, err := send(t, client, context.Background(), http.MethodGet, down1.URL, "")
+	if err == nil || !isDialError(err) {
+		t.Errorf("got %v, want the dial error of the last node", err)
+	}
+}
+
+func TestFailoverOtherHost(t *testing.T) {
+	down, other := newDownNode(t), newTestNode(t, false)
+	client, log := newFailoverClient(t, down.host)
+
+	// hosts outside the node list are sent as is
+	status, err := send(t, client, context.Background(), http.MethodGet, other.URL, "")
+	if err != nil || status != http.StatusOK || other.served() != 1 {
+		t.Fatalf("got %d, %v, %s served %d requests", status, err, other.host,
+			other.served())
+	}
+	if len(log.messages) != 0 {
+		t.Errorf("logged %q for a host outside the node list", log.messages)
+	}
+}
+
+func TestFailoverNonIdempotent(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		ctx      context.Context
+		method   string
+		failover bool
+	}{
+		{"post", context.Background(), http.MethodPost, false},
+		{"marked", Idempotent(context.Background()), http.MethodPost, true},
+		{"get", context.Background(), http.MethodGet, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// the first node drops the connection after receiving the
+			// request, which it may have carried out
+			hangUp, up := newTestNode(t, true), newTestNode(t, false)
+			client, _ := newFailoverClient(t, hangUp.host, up.host)
+
+			body := ""
+			if test.method == http.MethodPost {
+				body = "{}"
+			}
+			status, err := send(t, client, test.ctx, test.method, hangUp.URL, body)
+			if hangUp.served() != 1 {
+				t.Fatalf("%s received %d requests, want 1", hangUp.host, hangUp.served())
+			}
+			if test.failover {
+				if err != nil || status != http.StatusOK || up.served() != 1 {
+					t.Errorf("got %d, %v, %s served %d requests, want a failover", status,
+						err, up.host, up.served())
+				}
+			} else if err == nil || up.served() != 0 {
+				t.Errorf("got %d, %v, %s served %d requests, want no failover", status,
+					err, up.host, up.served())
+			}
+		})
+	}
+}
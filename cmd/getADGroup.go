@@ -17,74 +17,31 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // getADGroupCmd represents the get-ad-group command
 var getADGroupCmd = &cobra.Command{
-    Use:   "get-ad-group",
-    Short: "Search Active Directory group",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // create request payload
-        ADGroupname, _ := flags.GetString("name")
-        params["name"] = ADGroupname
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "GetADGroup")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nNo AD User(s) found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "get-ad-group",
+	Short: "Search Active Directory group",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		ADGroupname, _ := flags.GetString("name")
+
+		response, err := GetVault().GetADGroup(Idempotent(cmd.Context()), ADGroupname)
+		if err != nil {
+			return VaultError(err, "No AD User(s) found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(getADGroupCmd)
-    getADGroupCmd.Flags().StringP("name", "n", "",
-                                 "Prefix of the group name for searching")
+	rootCmd.AddCommand(getADGroupCmd)
+	getADGroupCmd.Flags().StringP("name", "n", "",
+		"Prefix of the group name for searching")
 
-    // mark mandatory fields as required
-    getADGroupCmd.MarkFlagRequired("name")
+	// mark mandatory fields as required
+	getADGroupCmd.MarkFlagRequired("name")
 }
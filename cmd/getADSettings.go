@@ -17,74 +17,31 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // getADSettingsCmd represents the get-ad-settings command
 var getADSettingsCmd = &cobra.Command{
-    Use:   "get-ad-settings",
-    Short: "Get AD Setting details",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // create request payload
-        ADSettingID, _ := flags.GetString("ad-setting-id")
-        params["ad_setting_id"] = ADSettingID
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "GetADSetting")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nAD setting not found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "get-ad-settings",
+	Short: "Get AD Setting details",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		ADSettingID, _ := flags.GetString("ad-setting-id")
+
+		response, err := GetVault().GetADSetting(Idempotent(cmd.Context()), ADSettingID)
+		if err != nil {
+			return VaultError(err, "AD setting not found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(getADSettingsCmd)
-    getADSettingsCmd.Flags().StringP("ad-setting-id", "a", "",
-                               "Id or name of the AD Setting to get")
+	rootCmd.AddCommand(getADSettingsCmd)
+	getADSettingsCmd.Flags().StringP("ad-setting-id", "a", "",
+		"Id or name of the AD Setting to get")
 
-    // mark mandatory fields as required
-    getADSettingsCmd.MarkFlagRequired("ad-setting-id")
+	// mark mandatory fields as required
+	getADSettingsCmd.MarkFlagRequired("ad-setting-id")
 }
@@ -17,74 +17,31 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // getADUserCmd represents the get-ad-user command
 var getADUserCmd = &cobra.Command{
-    Use:   "get-ad-user",
-    Short: "Search Active Directory users",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // create request payload
-        ADUsername, _ := flags.GetString("name")
-        params["name"] = ADUsername
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "GetADUser")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nNo AD User(s) found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "get-ad-user",
+	Short: "Search Active Directory users",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		ADUsername, _ := flags.GetString("name")
+
+		response, err := GetVault().GetADUser(Idempotent(cmd.Context()), ADUsername)
+		if err != nil {
+			return VaultError(err, "No AD User(s) found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(getADUserCmd)
-    getADUserCmd.Flags().StringP("name", "n", "",
-                                 "Prefix of the username for searching")
+	rootCmd.AddCommand(getADUserCmd)
+	getADUserCmd.Flags().StringP("name", "n", "",
+		"Prefix of the username for searching")
 
-    // mark mandatory fields as required
-    getADUserCmd.MarkFlagRequired("name")
+	// mark mandatory fields as required
+	getADUserCmd.MarkFlagRequired("name")
 }
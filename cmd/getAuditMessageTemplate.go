@@ -17,74 +17,31 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // getAuditMessageTemplateCmd represents the get-audit-message-template command
 var getAuditMessageTemplateCmd = &cobra.Command{
-    Use:   "get-audit-message-template",
-    Short: "Given a message id, get corresponding audit message template",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // create request payload
-        msgid, _ := flags.GetInt("msgid")
-        params["message_id"] = msgid
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "GetAuditMessageTemplate")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nAD message template not found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "get-audit-message-template",
+	Short: "Given a message id, get corresponding audit message template",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		msgid, _ := flags.GetInt("msgid")
+
+		response, err := GetVault().GetAuditMessageTemplate(Idempotent(cmd.Context()), msgid)
+		if err != nil {
+			return VaultError(err, "AD message template not found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(getAuditMessageTemplateCmd)
-    getAuditMessageTemplateCmd.Flags().IntP("msgid", "m", 0,
-                               "Message id of audit message template to fetch")
+	rootCmd.AddCommand(getAuditMessageTemplateCmd)
+	getAuditMessageTemplateCmd.Flags().IntP("msgid", "m", 0,
+		"Message id of audit message template to fetch")
 
-    // mark mandatory fields as required
-    getAuditMessageTemplateCmd.MarkFlagRequired("msgid")
+	// mark mandatory fields as required
+	getAuditMessageTemplateCmd.MarkFlagRequired("msgid")
 }
@@ -17,64 +17,23 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // getAuditSettingsCmd represents the get-audit-settings command
 var getAuditSettingsCmd = &cobra.Command{
-    Use:   "get-audit-settings",
-    Short: "Get audit settings",
-    Run: func(cmd *cobra.Command, args []string) {
-        params := map[string]interface{}{}
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "GetAuditSetting")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nAudit settings not found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "get-audit-settings",
+	Short: "Get audit settings",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response, err := GetVault().GetAuditSettings(Idempotent(cmd.Context()))
+		if err != nil {
+			return VaultError(err, "Audit settings not found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(getAuditSettingsCmd)
+	rootCmd.AddCommand(getAuditSettingsCmd)
 }
@@ -17,57 +17,21 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 var getPersonalAccessTokenCmd = &cobra.Command{
 	Use:   "get-personal-access-token",
 	Short: "Get Personal Access Token details",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		name, _ := flags.GetString("name")
-		params["name"] = name
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
 
-		endpoint := GetEndPoint("", "1.0", "GetPersonalAccessToken")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().GetPersonalAccessToken(Idempotent(cmd.Context()), name)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
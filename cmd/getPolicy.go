@@ -18,10 +18,7 @@ package cmd
 
 import (
 	// standard
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 
 	// external
 	"github.com/spf13/cobra"
@@ -31,57 +28,17 @@ import (
 var getPolicyCmd = &cobra.Command{
 	Use:   "get-policy",
 	Short: "Get Policy details",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
+		request := vault.PolicyVersion{}
+		request.PolicyID, _ = flags.GetString("policyid")
+		request.Version, _ = flags.GetInt("version")
 
-		// create request payload
-		policyid, _ := flags.GetString("policyid")
-		params["policy_id"] = policyid
-
-		if flags.Changed("version") {
-			version, _ := flags.GetInt("version")
-			params["version"] = version
-		}
-
-		// JSONify
-		jsonParams, err := json.Marshal(params)
+		response, err := GetVault().GetAccessPolicy(Idempotent(cmd.Context()), request)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		// now POST
-		endpoint := GetEndPoint("", "1.0", "GetPolicy")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			// type assertion
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nPolicy not found\n")
-				os.Exit(5)
-			}
-
-			fmt.Println("\n" + retStr + "\n")
-
-			// make a decision on what to exit with
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				os.Exit(3)
-			} else {
-				os.Exit(0)
-			}
+			return VaultError(err, "Policy not found")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -17,82 +17,36 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// standard
+	"cli/pkg/vault"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // listAuditMessageTemplatesCmd represents the list-audit-message command
 var listAuditMessageTemplatesCmd = &cobra.Command{
-    Use:   "list-audit-message-templates",
-    Short: "List available audit messages templates",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // create request payload
-        if flags.Changed("max-items") {
-            maxItems, _ := flags.GetInt("max-items")
-            params["max_items"] = maxItems
-        }
-        
-        if flags.Changed("next-token") {
-            nextToken, _ := flags.GetString("next-token")
-            params["next_token"] = nextToken
-        }
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "ListAuditMessageTemplates")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nAudit message templates not found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "list-audit-message-templates",
+	Short: "List available audit messages templates",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		options := vault.AuditListOptions{}
+		options.MaxItems, _ = flags.GetInt("max-items")
+		options.NextToken, _ = flags.GetString("next-token")
+
+		response, err := GetVault().ListAuditMessageTemplates(Idempotent(cmd.Context()), options)
+		if err != nil {
+			return VaultError(err, "Audit message templates not found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(listAuditMessageTemplatesCmd)
-    listAuditMessageTemplatesCmd.Flags().IntP("max-items", "m", 0,
-                               "Maximum number of items to include in " +
-                               "response")
-    listAuditMessageTemplatesCmd.Flags().StringP("next-token", "n", "",
-                                  "Token from which subsequent Audit " +
-                                  "messages would be listed")
+	rootCmd.AddCommand(listAuditMessageTemplatesCmd)
+	listAuditMessageTemplatesCmd.Flags().IntP("max-items", "m", 0,
+		"Maximum number of items to include in "+
+			"response")
+	listAuditMessageTemplatesCmd.Flags().StringP("next-token", "n", "",
+		"Token from which subsequent Audit "+
+			"messages would be listed")
 }
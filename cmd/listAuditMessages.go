@@ -18,13 +18,12 @@ package cmd
 
 import (
 	// standard
-
 	"bytes"
+	"cli/pkg/vault"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
 	"time"
 	// external
 	"github.com/spf13/cobra"
@@ -36,31 +35,24 @@ const (
 	listAuditOptionLocalTime   = "local-time"
 )
 
-type auditMessage struct {
-	CreatedAt   string                 `json:"created_at"`
-	UserContext string                 `json:"user_context"`
-	Message     string                 `json:"message"`
-	Info        map[string]interface{} `json:"info,omitempty"`
-}
-
-type listAuditMessagesResponse struct {
-	Messages []auditMessage `json:"audit_messages"`
-}
-
-func printAuditMessages(data []byte, cmd *cobra.Command) {
+func printAuditMessages(response *vault.AuditMessages, cmd *cobra.Command) error {
 	flags := cmd.Flags()
+	data := response.JSON()
+
+	if !DefaultOutput() {
+		return PrintResult(string(data))
+	}
 
 	// JSON output
 	if ok, _ := flags.GetBool(listAuditOptionJSONOutput); ok {
 		dst := &bytes.Buffer{}
 		if err := json.Indent(dst, data, "", "  "); err != nil {
 			// probably this is not of json format, print & exit
-			fmt.Println(string(data))
-			os.Exit(4)
+			return InvalidResponseError(string(data) + "\n")
 		} else {
 			fmt.Println(dst.String())
 		}
-		return
+		return nil
 	}
 
 	// text line-by-line custom output
@@ -70,16 +62,10 @@ func printAuditMessages(data []byte, cmd *cobra.Command) {
 	// (timestamp user-context message)
 	// name: value
 	// name: value
-	var resp listAuditMessagesResponse
-	err := json.Unmarshal(data, &resp)
-	if err != nil {
-		fmt.Printf("\nInvalid response - %v\n", err)
-		return
-	}
-
 	includeInfo, _ := flags.GetBool(listAuditOptionIncludeInfo)
 	localTime, _ := flags.GetBool(listAuditOptionLocalTime)
 	var location *time.Location
+	var err error
 	if localTime {
 		location, err = time.LoadLocation("Local")
 		if err != nil {
@@ -88,7 +74,7 @@ func printAuditMessages(data []byte, cmd *cobra.Command) {
 		}
 	}
 
-	for _, msg := range resp.Messages {
+	for _, msg := range response.Messages {
 		var createdAt time.Time
 		createdAt, err = time.Parse(time.RFC3339, msg.CreatedAt)
 		if err != nil {
@@ -111,70 +97,38 @@ func printAuditMessages(data []byte, cmd *cobra.Command) {
 			fmt.Printf("%s: %v\n", name, value)
 		}
 	}
+	return nil
 }
 
-func listAuditMessageAPI(cmd *cobra.Command, args []string) {
+func listAuditMessageAPI(cmd *cobra.Command, args []string) error {
 	flags := cmd.Flags()
-	params := map[string]interface{}{}
 
-	// create request payload
-	if flags.Changed("filters") {
-		filters, _ := flags.GetString("filters")
-		params["filters"] = filters
-	}
+	options := vault.AuditListOptions{}
+	options.Filters, _ = flags.GetString("filters")
+	options.MaxItems, _ = flags.GetInt("max-items")
+	options.Fields, _ = flags.GetStringArray("field")
+	options.NextToken, _ = flags.GetString("next-token")
 
-	if flags.Changed("max-items") {
-		maxItems, _ := flags.GetInt("max-items")
-		params["max_items"] = maxItems
-	}
-
-	if flags.Changed("field") {
-		fieldsArray, _ := flags.GetStringArray("field")
-		params["fields"] = fieldsArray
-	}
-
-	if flags.Changed("next-token") {
-		nextToken, _ := flags.GetString("next-token")
-		params["next_token"] = nextToken
-	}
-
-	// JSONify
-	jsonParams, err := json.Marshal(params)
+	response, err := GetVault().ListAuditMessages(Idempotent(cmd.Context()), options)
 	if err != nil {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
-	}
-
-	// now POST
-	endpoint := GetEndPoint("", "1.0", "ListAuditMessages")
-	ret, err := DoPost2(endpoint, GetCACertFile(),
-		AuthTokenKV(),
-		jsonParams, ContentTypeJSON, nil, nil)
-	if err != nil {
-		var apiError APIError
-		if errors.As(err, &apiError) && apiError.HttpStatusCode == http.StatusNotFound {
-			fmt.Println("\nAudit messages not found\n")
-			os.Exit(5)
-		}
-		fmt.Printf("\nHTTP request failed:\n%v\n", err)
-		os.Exit(4)
-	} else {
-		data := ret.([]byte)
-		if len(data) == 0 {
-			fmt.Printf("\nEmpty response\n")
-			os.Exit(3)
-		} else {
-			printAuditMessages(data, cmd)
-			os.Exit(0)
+		var vaultError *vault.Error
+		if errors.As(err, &vaultError) && vaultError.StatusCode == http.StatusNotFound {
+			return commandFailed(ExitNotFound, "\nAudit messages not found\n\n",
+				vaultAPIError(vaultError))
 		}
+		return OperationError("HTTP request failed:", err)
+	}
+	if len(response.JSON()) == 0 {
+		return InvalidResponseError("\nEmpty response\n")
 	}
+	return printAuditMessages(response, cmd)
 }
 
 // listAuditMessagesCmd represents the list-audit-message command
 var listAuditMessagesCmd = &cobra.Command{
 	Use:   "list-audit-messages",
 	Short: "List all audit messages",
-	Run:   listAuditMessageAPI,
+	RunE:  listAuditMessageAPI,
 }
 
 func init() {
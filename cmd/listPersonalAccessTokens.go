@@ -17,45 +17,18 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"fmt"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 var listPersonalAccessTokensCmd = &cobra.Command{
 	Use:   "list-personal-access-tokens",
 	Short: "List Personal Access Tokens",
-	Run: func(cmd *cobra.Command, args []string) {
-
-		endpoint := GetEndPoint("", "1.0", "ListPersonalAccessTokens")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			nil,
-			"application/json")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response, err := GetVault().ListPersonalAccessTokens(Idempotent(cmd.Context()))
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
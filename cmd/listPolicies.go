@@ -17,104 +17,38 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // listPoliciesCmd represents the list-policy command
 var listPoliciesCmd = &cobra.Command{
-    Use:   "list-policies",
-    Short: "List all Policies",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // create request payload
-        if flags.Changed("prefix") {
-            prefix, _ := flags.GetString("prefix")
-            params["prefix"] = prefix
-        }
-        
-        if flags.Changed("filters") {
-            filters, _ := flags.GetString("filters")
-            params["filters"] = filters
-        }
-        
-        if flags.Changed("max-items") {
-            maxItems, _ := flags.GetInt("max-items")
-            params["max_items"] = maxItems
-        }
-        
-        if flags.Changed("field") {
-            fieldsArray, _ := flags.GetStringArray("field")
-            params["fields"] = fieldsArray
-        }
-        
-        if flags.Changed("next-token") {
-            nextToken, _ := flags.GetString("next-token")
-            params["next_token"] = nextToken
-        }
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "ListPolicies")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nPolicies not found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "list-policies",
+	Short: "List all Policies",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+
+		response, err := GetVault().ListAccessPolicies(Idempotent(cmd.Context()), listOptions(flags, "field"))
+		if err != nil {
+			return VaultError(err, "Policies not found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(listPoliciesCmd)
-    listPoliciesCmd.Flags().StringP("prefix", "p", "",
-                                 "Prefix to list Policies with")
-    listPoliciesCmd.Flags().StringP("filters", "l", "",
-                                 "Conditional expression to list filtered " +
-                                 "policies")
-    listPoliciesCmd.Flags().IntP("max-items", "m", 0,
-                              "Maximum number of items to include " +
-                              "in the response")
-    listPoliciesCmd.Flags().StringArrayP("field", "f", []string{},
-                                      "Policy field to include in the response")
-    listPoliciesCmd.Flags().StringP("next-token", "n", "",
-                                 "Token from which subsequent Policies would " +
-                                 "be listed")
+	rootCmd.AddCommand(listPoliciesCmd)
+	listPoliciesCmd.Flags().StringP("prefix", "p", "",
+		"Prefix to list Policies with")
+	listPoliciesCmd.Flags().StringP("filters", "l", "",
+		"Conditional expression to list filtered "+
+			"policies")
+	listPoliciesCmd.Flags().IntP("max-items", "m", 0,
+		"Maximum number of items to include "+
+			"in the response")
+	listPoliciesCmd.Flags().StringArrayP("field", "f", []string{},
+		"Policy field to include in the response")
+	listPoliciesCmd.Flags().StringP("next-token", "n", "",
+		"Token from which subsequent Policies would "+
+			"be listed")
 }
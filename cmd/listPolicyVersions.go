@@ -17,12 +17,6 @@ limitations under the License.
 package cmd
 
 import (
-	// standard
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
-
 	// external
 	"github.com/spf13/cobra"
 )
@@ -31,54 +25,15 @@ import (
 var listPolicyVersionsCmd = &cobra.Command{
 	Use:   "list-policy-versions",
 	Short: "List versions of a given Policy",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		// create request payload
-		if flags.Changed("policyid") {
-			policyId, _ := flags.GetString("policyid")
-			params["policy_id"] = policyId
-		}
+		policyId, _ := flags.GetString("policyid")
 
-		// JSONify
-		jsonParams, err := json.Marshal(params)
+		response, err := GetVault().ListPolicyVersions(Idempotent(cmd.Context()), policyId)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		// now POST
-		endpoint := GetEndPoint("", "1.0", "ListPolicyVersions")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			// type assertion
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nPolicies not found\n")
-				os.Exit(5)
-			}
-
-			fmt.Println("\n" + retStr + "\n")
-
-			// make a decision on what to exit with
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				os.Exit(3)
-			} else {
-				os.Exit(0)
-			}
+			return VaultError(err, "Policies not found")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -0,0 +1,379 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/itchyny/gojq"
+	"github.com/jmespath/go-jmespath"
+	"go.yaml.in/yaml/v3"
+)
+
+// Output formats of the --output option. The default prints the server
+// JSON indented and surrounded by blank lines.
+const (
+	OutputFormatDefault = ""
+	OutputFormatJSON    = "json"
+	OutputFormatYAML    = "yaml"
+	OutputFormatTable   = "table"
+	OutputFormatCSV     = "csv"
+)
+
+var gOutputFormat string
+
+var gQuery string
+
+// defaultColumns lists the table and CSV columns shown for a list of
+// resources, by the name of the list in the response. Columns missing
+// from the response are left out.
+var defaultColumns = []struct {
+	resource string
+	columns  []string
+}{
+	{"key", []string{"name", "guid", "keyGuid", "key_guid", "cipher", "state",
+		"version", "created_at"}},
+	{"polic", []string{"name", "policyName", "policy_name", "id", "version",
+		"description", "created_at"}},
+	{"user", []string{"name", "username", "user_name", "email", "role",
+		"auth_type", "enabled", "created_at"}},
+	{"token", []string{"name", "description", "expiry", "revoked",
+		"created_at"}},
+	{"audit", []string{"created_at", "user_context", "message"}},
+	{"ad_setting", []string{"id", "name", "type", "service_account",
+		"uid_attribute"}},
+}
+
+// ValidateOutputOptions checks the --output and --query options
+func ValidateOutputOptions() error {
+	switch gOutputFormat {
+	case OutputFormatDefault, OutputFormatJSON, OutputFormatYAML,
+		OutputFormatTable, OutputFormatCSV:
+	default:
+		return fmt.Errorf("Invalid output format %q - use json, yaml, table or csv",
+			gOutputFormat)
+	}
+	if gQuery != "" {
+		if _, err := compileQuery(gQuery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isJQ reports whether query is a jq filter rather than a JMESPath
+// expression, which never starts with a dot
+func isJQ(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), ".")
+}
+
+func compileQuery(query string) (func(interface{}) (interface{}, error), error) {
+	if isJQ(query) {
+		parsed, err := gojq.Parse(query)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid jq query %q - %v", query, err)
+		}
+		code, err := gojq.Compile(parsed)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid jq query %q - %v", query, err)
+		}
+		return func(data interface{}) (interface{}, error) {
+			results := []interface{}{}
+			iter := code.Run(data)
+			for {
+				value, ok := iter.Next()
+				if !ok {
+					break
+				}
+				if err, ok := value.(error); ok {
+					return nil, err
+				}
+				results = append(results, value)
+			}
+			if len(results) == 1 {
+				return results[0], nil
+			}
+			return results, nil
+		}, nil
+	}
+
+	compiled, err := jmespath.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid JMESPath query %q - %v", query, err)
+	}
+	return compiled.Search, nil
+}
+
+// DefaultOutput reports whether results are printed as returned by
+// the server, so that commands may add their own messages
+func DefaultOutput() bool {
+	return gOutputFormat == OutputFormatDefault && gQuery == ""
+}
+
+// PrintStatus prints message for commands the vault answers without a
+// result, or with --output or --query the JSON of result instead, so
+// that scripts get the outcome in the format they asked for
+func PrintStatus(message string, result interface{}) error {
+	if DefaultOutput() {
+		fmt.Print(message)
+		return nil
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return Errorf(ExitError, "\nError formatting output - %v\n", err)
+	}
+	return PrintResult(string(data))
+}
+
+// PrintResult prints the JSON result of a command in the format chosen
+// with --output, after applying --query
+func PrintResult(result string) error {
+	if DefaultOutput() {
+		fmt.Println("\n" + result + "\n")
+		return nil
+	}
+	if err := writeResult(os.Stdout, []byte(result)); err != nil {
+		return Errorf(ExitError, "\nError formatting output - %v\n", err)
+	}
+	return nil
+}
+
+func writeResult(w io.Writer, result []byte) error {
+	var data interface{}
+	if err := json.Unmarshal(result, &data); err != nil {
+		return err
+	}
+	if gQuery != "" {
+		query, err := compileQuery(gQuery)
+		if err != nil {
+			return err
+		}
+		if data, err = query(data); err != nil {
+			return fmt.Errorf("Query %q failed - %v", gQuery, err)
+		}
+	}
+
+	switch gOutputFormat {
+	case OutputFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(integralNumbers(data)); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case OutputFormatTable:
+		columns, rows := tabulate(data)
+		return writeTable(w, columns, rows)
+	case OutputFormatCSV:
+		columns, rows := tabulate(data)
+		return writeCSV(w, columns, rows)
+	}
+
+	// json, also the default once a query is applied
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if gOutputFormat == OutputFormatDefault {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// integralNumbers converts whole JSON numbers to integers, which YAML
+// would otherwise show in exponent notation
+func integralNumbers(data interface{}) interface{} {
+	switch value := data.(type) {
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < 1<<53 {
+			return int64(value)
+		}
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = integralNumbers(v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = integralNumbers(v)
+		}
+	}
+	return data
+}
+
+// formatCell renders a value as a table or CSV cell
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	out, _ := json.Marshal(value)
+	return string(out)
+}
+
+// listOfObjects returns data as a list of objects, if it is one
+func listOfObjects(data interface{}) ([]map[string]interface{}, bool) {
+	list, ok := data.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	rows := []map[string]interface{}{}
+	for _, item := range list {
+		row, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		rows = append(rows, row)
+	}
+	return rows, true
+}
+
+// tabulate returns the columns and rows data is shown with in a table.
+// A list of objects, on its own or as the only list in an object such
+// as {"keys": [...], "next_token": ...}, gives a row per object; other
+// objects give a row per field.
+func tabulate(data interface{}) ([]string, [][]string) {
+	resource := ""
+	rows, ok := listOfObjects(data)
+	if object, isObject := data.(map[string]interface{}); isObject {
+		found := 0
+		for name, value := range object {
+			if list, isList := listOfObjects(value); isList {
+				resource, rows, ok = name, list, true
+				found++
+			}
+		}
+		if found != 1 {
+			resource, rows, ok = "", nil, false
+		}
+		if !ok {
+			names := make([]string, 0, len(object))
+			for name := range object {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			table := [][]string{}
+			for _, name := range names {
+				table = append(table, []string{name, formatCell(object[name])})
+			}
+			return []string{"field", "value"}, table
+		}
+	}
+	if !ok {
+		if list, isList := data.([]interface{}); isList {
+			table := [][]string{}
+			for _, value := range list {
+				table = append(table, []string{formatCell(value)})
+			}
+			return []string{"value"}, table
+		}
+		return []string{"value"}, [][]string{{formatCell(data)}}
+	}
+
+	columns := tableColumns(resource, rows)
+	table := [][]string{}
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, column := range columns {
+			cells[i] = formatCell(row[column])
+		}
+		table = append(table, cells)
+	}
+	return columns, table
+}
+
+// tableColumns returns the default columns for the rows of resource,
+// or all fields of the rows if it has no defaults
+func tableColumns(resource string, rows []map[string]interface{}) []string {
+	present := map[string]bool{}
+	for _, row := range rows {
+		for name := range row {
+			present[name] = true
+		}
+	}
+
+	resource = strings.ToLower(resource)
+	for _, defaults := range defaultColumns {
+		if resource == "" || !strings.Contains(resource, defaults.resource) {
+			continue
+		}
+		columns := []string{}
+		for _, column := range defaults.columns {
+			if present[column] {
+				columns = append(columns, column)
+			}
+		}
+		if len(columns) > 0 {
+			return columns
+		}
+	}
+
+	columns := make([]string, 0, len(present))
+	for name := range present {
+		columns = append(columns, name)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+func writeTable(w io.Writer, columns []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = strings.ToUpper(column)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			// keep each row on one line
+			cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, columns []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,114 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var errPinMismatch = errors.New("does not match any pinned key")
+
+// SPKIPin returns the pin of cert - the base64 encoded SHA-256 digest
+// of its DER encoded SubjectPublicKeyInfo. Unlike a certificate
+// fingerprint, the pin survives renewal of the certificate with the
+// same key.
+func SPKIPin(cert *x509.Certificate) string {
+	digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// NormalizePin accepts a pin with or without the "sha256/" prefix
+// used by HTTP public key pinning and returns it without the prefix
+func NormalizePin(pin string) (string, error) {
+	pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+	digest, err := base64.StdEncoding.DecodeString(pin)
+	if err != nil || len(digest) != sha256.Size {
+		return "", fmt.Errorf("Invalid SPKI pin %q - expected base64 "+
+			"encoded SHA-256 digest", pin)
+	}
+	return pin, nil
+}
+
+// verifyPins returns a tls.Config VerifyConnection callback accepting
+// only a vault certificate matching one of pins
+func verifyPins(pins []string) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("vault sent no certificate")
+		}
+		pin := SPKIPin(state.PeerCertificates[0])
+		for _, allowed := range pins {
+			if pin == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("vault certificate public key (sha256/%s) %w",
+			pin, errPinMismatch)
+	}
+}
+
+// isCertificateError reports whether err is a failure to verify the
+// vault certificate, which retrying will not fix
+func isCertificateError(err error) bool {
+	var verificationError *tls.CertificateVerificationError
+	var hostnameError x509.HostnameError
+	var authorityError x509.UnknownAuthorityError
+	var invalidError x509.CertificateInvalidError
+	return errors.Is(err, errPinMismatch) ||
+		errors.As(err, &verificationError) ||
+		errors.As(err, &hostnameError) ||
+		errors.As(err, &authorityError) ||
+		errors.As(err, &invalidError)
+}
+
+// FetchPeerCertificate connects to the vault node at address
+// (host or host:port) and returns the certificate it presents, after
+// verifying it as a Client built from config would, except for pins.
+func FetchPeerCertificate(ctx context.Context, config ClientConfig,
+	address string) (*x509.Certificate, error) {
+	config.Pins = nil
+	tr, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	defer tr.CloseIdleConnections()
+
+	// any response will do, the certificate is all that is needed
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead,
+		"https://"+address+"/", nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := tr.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+
+	if response.TLS == nil || len(response.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("%s sent no certificate", address)
+	}
+	return response.TLS.PeerCertificates[0], nil
+}
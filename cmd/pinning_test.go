@@ -0,0 +1,207 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tlsTestServer is a TLS server with a self-signed certificate
+type tlsTestServer struct {
+	*httptest.Server
+	cert   *x509.Certificate
+	caFile string // PEM file of cert
+}
+
+// newTLSTestServer starts a server whose certificate is issued for
+// hosts, host names or IP addresses
+func newTLSTestServer(t *testing.T, hosts ...string) *tlsTestServer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "Test Vault"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &tlsTestServer{Server: httptest.NewUnstartedServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {}))}
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{
+		{Certificate: [][]byte{der}, PrivateKey: key}}}
+	// the handshakes the tests expect to fail need not be logged
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	if server.cert, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+	server.caFile = filepath.Join(t.TempDir(), "cacert.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err = os.WriteFile(server.caFile, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+// get sends a GET request to the server through a Client built from
+// config, which must not retry it if the certificate is refused
+func (s *tlsTestServer) get(t *testing.T, config ClientConfig) error {
+	t.Helper()
+	config.Retry = testRetryPolicy
+	client, transport := newTestClient(t, config)
+	_, err := send(t, client, context.Background(), http.MethodGet, s.URL, "")
+	if err != nil && transport.count() > 1 {
+		t.Errorf("certificate error %v retried %d times", err, transport.count()-1)
+	}
+	return err
+}
+
+func TestNormalizePin(t *testing.T) {
+	digest := sha256.Sum256([]byte("public key"))
+	pin := base64.StdEncoding.EncodeToString(digest[:])
+
+	for _, value := range []string{pin, "sha256/" + pin, " " + pin + "\n"} {
+		if normalized, err := NormalizePin(value); err != nil || normalized != pin {
+			t.Errorf("NormalizePin(%q) = %q, %v, want %q", value, normalized, err, pin)
+		}
+	}
+	for _, value := range []string{"", "not base64!", pin[:20],
+		base64.StdEncoding.EncodeToString(digest[:16])} {
+		if normalized, err := NormalizePin(value); err == nil {
+			t.Errorf("NormalizePin(%q) = %q, want an error", value, normalized)
+		}
+	}
+}
+
+func TestPins(t *testing.T) {
+	server := newTLSTestServer(t, "localhost", "127.0.0.1")
+	other := newTLSTestServer(t, "localhost", "127.0.0.1")
+
+	for _, config := range []ClientConfig{
+		{CACertFile: server.caFile, Pins: []string{SPKIPin(server.cert)}},
+		// pins alone, as saved by trust on first use
+		{Pins: []string{SPKIPin(other.cert), SPKIPin(server.cert)}},
+	} {
+		if err := server.get(t, config); err != nil {
+			t.Errorf("pins %q: %v", config.Pins, err)
+		}
+	}
+
+	for _, config := range []ClientConfig{
+		{CACertFile: server.caFile, Pins: []string{SPKIPin(other.cert)}},
+		{Pins: []string{SPKIPin(other.cert)}},
+	} {
+		err := server.get(t, config)
+		if !errors.Is(err, errPinMismatch) || !isCertificateError(err) {
+			t.Errorf("pins %q: got %v, want a pin mismatch", config.Pins, err)
+		}
+	}
+}
+
+func TestSkipHostnameVerify(t *testing.T) {
+	// a certificate for another host than the 127.0.0.1 connected to
+	server := newTLSTestServer(t, "vault.test")
+	other := newTLSTestServer(t, "vault.test")
+
+	var hostnameError x509.HostnameError
+	err := server.get(t, ClientConfig{CACertFile: server.caFile})
+	if !errors.As(err, &hostnameError) || !isCertificateError(err) {
+		t.Errorf("got %v, want a host name mismatch", err)
+	}
+
+	config := ClientConfig{CACertFile: server.caFile, SkipHostnameVerify: true}
+	if err = server.get(t, config); err != nil {
+		t.Errorf("SkipHostnameVerify: %v", err)
+	}
+
+	// the certificate must still be issued by the CA
+	config.CACertFile = other.caFile
+	if err = server.get(t, config); err == nil || !isCertificateError(err) {
+		t.Errorf("SkipHostnameVerify with another CA: got %v, want a certificate error",
+			err)
+	}
+
+	// and match the pins
+	config = ClientConfig{CACertFile: server.caFile, SkipHostnameVerify: true,
+		Pins: []string{SPKIPin(other.cert)}}
+	if err = server.get(t, config); !errors.Is(err, errPinMismatch) {
+		t.Errorf("SkipHostnameVerify with another pin: got %v, want a pin mismatch", err)
+	}
+}
+
+func TestFetchPeerCertificate(t *testing.T) {
+	server := newTLSTestServer(t, "localhost", "127.0.0.1")
+	other := newTLSTestServer(t, "localhost", "127.0.0.1")
+
+	// the certificate is fetched whatever the pins, to pin it
+	config := ClientConfig{CACertFile: server.caFile, Pins: []string{SPKIPin(other.cert)}}
+	cert, err := FetchPeerCertificate(context.Background(), config,
+		server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.Equal(server.cert) {
+		t.Errorf("fetched %s, want the server certificate", SPKIPin(cert))
+	}
+
+	// but still verified
+	config.CACertFile = other.caFile
+	if _, err = FetchPeerCertificate(context.Background(), config,
+		server.Listener.Addr().String()); !isCertificateError(err) {
+		t.Errorf("got %v, want a certificate error", err)
+	}
+}
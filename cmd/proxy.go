@@ -0,0 +1,130 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ConnectTo redirects connections for Host:Port to
+// ConnectHost:ConnectPort, e.g. to reach a vault node by IP address
+// while its certificate is still verified against its host name. An
+// empty Host or Port matches any host or port; an empty ConnectHost
+// or ConnectPort keeps the original one.
+type ConnectTo struct {
+	Host        string
+	Port        string
+	ConnectHost string
+	ConnectPort string
+}
+
+// splitConnectTo splits s at the colons outside of IPv6 brackets
+func splitConnectTo(s string) []string {
+	parts := []string{}
+	depth := 0
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// ParseConnectTo parses HOST:PORT:CONNECT-HOST:CONNECT-PORT, the
+// format of curl's --connect-to option. IPv6 addresses are enclosed
+// in brackets.
+func ParseConnectTo(s string) (ConnectTo, error) {
+	parts := splitConnectTo(s)
+	if len(parts) != 4 {
+		return ConnectTo{}, fmt.Errorf("Invalid connect-to %q - expected "+
+			"HOST:PORT:CONNECT-HOST:CONNECT-PORT", s)
+	}
+	return ConnectTo{
+		Host:        strings.Trim(parts[0], "[]"),
+		Port:        parts[1],
+		ConnectHost: strings.Trim(parts[2], "[]"),
+		ConnectPort: parts[3]}, nil
+}
+
+// connectToDialer returns a DialContext function applying connectTo
+func connectToDialer(connectTo []ConnectTo) func(ctx context.Context,
+	network, address string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return dialer.DialContext(ctx, network, address)
+		}
+		for _, entry := range connectTo {
+			if (entry.Host != "" && !strings.EqualFold(entry.Host, host)) ||
+				(entry.Port != "" && entry.Port != port) {
+				continue
+			}
+			if entry.ConnectHost != "" {
+				host = entry.ConnectHost
+			}
+			if entry.ConnectPort != "" {
+				port = entry.ConnectPort
+			}
+			break
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(host, port))
+	}
+}
+
+// proxyFunc returns the http.Transport Proxy function for config. The
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables apply
+// unless overridden by config.Proxy and config.NoProxy.
+func proxyFunc(config ClientConfig) (func(*http.Request) (*url.URL, error), error) {
+	proxyConfig := httpproxy.FromEnvironment()
+	if config.Proxy != "" {
+		proxyURL := config.Proxy
+		if !strings.Contains(proxyURL, "://") {
+			proxyURL = "http://" + proxyURL
+		}
+		if _, err := url.Parse(proxyURL); err != nil {
+			return nil, fmt.Errorf("Invalid proxy URL %q - %v", config.Proxy, err)
+		}
+		proxyConfig.HTTPProxy = proxyURL
+		proxyConfig.HTTPSProxy = proxyURL
+	}
+	if config.NoProxy != "" {
+		proxyConfig.NoProxy = config.NoProxy
+	}
+
+	proxyForURL := proxyConfig.ProxyFunc()
+	return func(request *http.Request) (*url.URL, error) {
+		return proxyForURL(request.URL)
+	}, nil
+}
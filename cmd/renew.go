@@ -17,64 +17,35 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// standard
+	"fmt"
+	// external
+	"github.com/spf13/cobra"
 )
 
-type renewAccessToken struct {
-	Token      string `json:"access_token"`
-	Expiration string `json:"expires_at"`
-}
-
 // renewCmd represents the renew command
 var renewCmd = &cobra.Command{
-    Use:   "renew",
-    Short: "Renew Access Token",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-	    var respData renewAccessToken
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "Renew")
-	    _, err = DoPost2(endpoint, GetCACertFile(),
-        AuthTokenKV(),
-		    jsonParams, ContentTypeJSON, &respData, nil)
-	    if err != nil {
-		    fmt.Printf("\nSession Renew failed:\n\n%v\n", err)
-		    os.Exit(1)
-        }
-
-        // save access token to a file
-        tokenFile, _ := flags.GetString(loginOptionTokenFile)
-        tokenFile, err = SaveAccessToken(tokenFile,
-                                               respData.Token,
-                                               GetServer(),
-                                               GetCACertFile())
-        if err != nil {
-            fmt.Printf("\nError saving access token to %s - %v\n", tokenFile, err)
-            os.Exit(4)
-        }
-
-        fmt.Printf("\nSession is renewed.\nThe login session expires at %s.\n",
-                formatLoginExpiration(respData.Expiration))
-        fmt.Printf("New Access Token is saved in %s.\n", tokenFile)
-        fmt.Printf("\n")
-    },
+	Use:   "renew",
+	Short: "Renew Access Token",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := RenewSession(cmd.Context())
+		if err != nil {
+			return OperationError("Session Renew failed:\n", err)
+		}
+
+		expiresAt, err := formatLoginExpiration(session.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		tokenFile, _ := tokenFilePath(gAccessTokenFile)
+		return PrintStatus(fmt.Sprintf("\nSession is renewed.\n"+
+			"The login session expires at %s.\n"+
+			"New Access Token is saved in %s.\n\n", expiresAt, tokenFile),
+			map[string]string{"status": "renewed", "expires_at": session.ExpiresAt,
+				"token_file": tokenFile})
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(renewCmd)
+	rootCmd.AddCommand(renewCmd)
 }
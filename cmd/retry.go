@@ -0,0 +1,189 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries requests that failed at
+// the transport level or were answered with 429, 502, 503 or 504.
+//
+// GET requests and requests whose context is marked with Idempotent
+// are retried automatically. Any other request is retried only if it
+// never reached the server, unless RetryNonIdempotent is set.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	// Zero disables retries.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry. It doubles
+	// with every retry up to MaxBackoff; a random jitter of up to half
+	// the wait is subtracted.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxElapsed bounds the time spent retrying a single request.
+	// Zero means no bound.
+	MaxElapsed time.Duration
+	// RetryNonIdempotent retries every request as if it was marked
+	// with Idempotent
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by the CLI
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     8 * time.Second,
+		MaxElapsed:     30 * time.Second,
+	}
+}
+
+type idempotentKey struct{}
+
+// Idempotent marks requests sent with the returned context as safe to
+// repeat, e.g. lookups and tokenize or encrypt operations which do not
+// change state on the vault.
+func Idempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey{}, true)
+}
+
+func isIdempotent(request *http.Request) bool {
+	if request.Method == http.MethodGet || request.Method == http.MethodHead {
+		return true
+	}
+	marked, _ := request.Context().Value(idempotentKey{}).(bool)
+	return marked
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isDialError reports whether err happened while connecting, i.e.
+// before any part of the request was sent
+func isDialError(err error) bool {
+	var opError *net.OpError
+	return errors.As(err, &opError) && opError.Op == "dial"
+}
+
+// backoff returns the wait before retry number attempt (0 based)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 0; i < attempt && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait - time.Duration(rand.Int63n(int64(wait)/2+1))
+}
+
+// retryAfter parses the Retry-After header of response, which holds
+// either a number of seconds or an HTTP date
+func retryAfter(response *http.Response) (time.Duration, bool) {
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// doWithRetry sends request, retrying it as allowed by the Client's
+// RetryPolicy. The last response or error is returned once retries
+// are exhausted.
+func (c *Client) doWithRetry(request *http.Request) (*http.Response, error) {
+	policy := c.retry
+	idempotent := policy.RetryNonIdempotent || isIdempotent(request)
+	ctx := request.Context()
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.doWithFailover(request)
+
+		retryable := false
+		var wait time.Duration
+		if err != nil {
+			retryable = ctx.Err() == nil && !isCertificateError(err) &&
+				(idempotent || isDialError(err))
+			wait = policy.backoff(attempt)
+		} else if isRetryableStatus(response.StatusCode) && idempotent {
+			retryable = true
+			var ok bool
+			if wait, ok = retryAfter(response); !ok {
+				wait = policy.backoff(attempt)
+			}
+		}
+
+		if !retryable || attempt >= policy.MaxRetries ||
+			(request.Body != nil && request.GetBody == nil) ||
+			(policy.MaxElapsed > 0 && time.Since(start)+wait > policy.MaxElapsed) {
+			return response, err
+		}
+
+		if response != nil {
+			response.Body.Close()
+			c.logf("%s - retrying in %v\n", response.Status, wait.Round(time.Millisecond))
+		} else {
+			c.logf("%v - retrying in %v\n", err, wait.Round(time.Millisecond))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		// the body of the previous attempt has been consumed
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			request = request.Clone(ctx)
+			request.Body = body
+		}
+	}
+}
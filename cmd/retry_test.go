@@ -0,0 +1,287 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries quickly so that tests do not wait
+var testRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     4 * time.Millisecond,
+}
+
+// testTransport counts the attempts of a Client at sending requests
+type testTransport struct {
+	http.RoundTripper
+	attempts int32
+}
+
+func (t *testTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.attempts, 1)
+	return t.RoundTripper.RoundTrip(request)
+}
+
+// count returns the number of attempts so far
+func (t *testTransport) count() int {
+	return int(atomic.LoadInt32(&t.attempts))
+}
+
+// newTestClient builds a Client from config whose attempts are
+// counted by the returned transport
+func newTestClient(t *testing.T, config ClientConfig) (*Client, *testTransport) {
+	t.Helper()
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := &testTransport{RoundTripper: client.httpClient.Transport}
+	client.httpClient.Transport = transport
+	return client, transport
+}
+
+// send sends a request with body, if not empty, through client and
+// returns the status code of the response
+func send(t *testing.T, client *Client, ctx context.Context, method, url,
+	body string) (int, error) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.doWithRetry(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	return response.StatusCode, nil
+}
+
+// failingServer answers the first failures requests with status and
+// the following ones with 200. Each request must carry body.
+func failingServer(t *testing.T, failures int, status int, header http.Header,
+	body string) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			received, _ := io.ReadAll(r.Body)
+			if string(received) != body {
+				t.Errorf("request %d carried %q, want %q", atomic.LoadInt32(&requests)+1,
+					received, body)
+			}
+			if int(atomic.AddInt32(&requests, 1)) <= failures {
+				for name, values := range header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(status)
+			}
+		}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff: 400 * time.Millisecond}
+	for attempt, want := range []time.Duration{100, 200, 400, 400, 400} {
+		want *= time.Millisecond
+		for i := 0; i < 100; i++ {
+			wait := policy.backoff(attempt)
+			if wait < want/2 || wait > want {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, wait,
+					want/2, want)
+			}
+		}
+	}
+
+	if wait := (RetryPolicy{}).backoff(2); wait != 0 {
+		t.Errorf("backoff without InitialBackoff = %v, want 0", wait)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for _, test := range []struct {
+		value    string
+		min, max time.Duration
+		ok       bool
+	}{
+		{"", 0, 0, false},
+		{"3", 3 * time.Second, 3 * time.Second, true},
+		{"0", 0, 0, true},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat),
+			58 * time.Second, time.Minute, true},
+		{"Sun, 06 Nov 1994 08:49:37 GMT", 0, 0, true},
+		{"-1", 0, 0, false},
+		{"soon", 0, 0, false},
+	} {
+		response := &http.Response{Header: http.Header{}}
+		if test.value != "" {
+			response.Header.Set("Retry-After", test.value)
+		}
+		wait, ok := retryAfter(response)
+		if ok != test.ok || wait < test.min || wait > test.max {
+			t.Errorf("Retry-After %q: got %v, %t, want %v to %v, %t", test.value, wait,
+				ok, test.min, test.max, test.ok)
+		}
+	}
+}
+
+func TestRetryStatus(t *testing.T) {
+	server, requests := failingServer(t, 2, http.StatusServiceUnavailable, nil, "")
+	client, transport := newTestClient(t, ClientConfig{Retry: testRetryPolicy})
+
+	status, err := send(t, client, context.Background(), http.MethodGet, server.URL, "")
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("got %d, %v, want 200", status, err)
+	}
+	if *requests != 3 || transport.count() != 3 {
+		t.Errorf("server received %d of %d requests, want 3", *requests,
+			transport.count())
+	}
+}
+
+func TestRetryAfterHonoured(t *testing.T) {
+	server, requests := failingServer(t, 1, http.StatusTooManyRequests,
+		http.Header{"Retry-After": {"1"}}, "")
+	client, _ := newTestClient(t, ClientConfig{Retry: testRetryPolicy})
+
+	start := time.Now()
+	status, err := send(t, client, context.Background(), http.MethodGet, server.URL, "")
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("got %d, %v, want 200", status, err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want the 1s of Retry-After", elapsed)
+	}
+	if *requests != 2 {
+		t.Errorf("server received %d requests, want 2", *requests)
+	}
+
+	// Retry-After beyond MaxElapsed gives up at once
+	server, requests = failingServer(t, 1, http.StatusTooManyRequests,
+		http.Header{"Retry-After": {"60"}}, "")
+	policy := testRetryPolicy
+	policy.MaxElapsed = time.Second
+	client, _ = newTestClient(t, ClientConfig{Retry: policy})
+	status, err = send(t, client, context.Background(), http.MethodGet, server.URL, "")
+	if err != nil || status != http.StatusTooManyRequests || *requests != 1 {
+		t.Errorf("got %d, %v after %d requests, want 429 after 1", status, err, *requests)
+	}
+}
+
+func TestRetryMaxRetries(t *testing.T) {
+	server, requests := failingServer(t, 10, http.StatusBadGateway, nil, "")
+	client, _ := newTestClient(t, ClientConfig{Retry: testRetryPolicy})
+
+	status, err := send(t, client, context.Background(), http.MethodGet, server.URL, "")
+	if err != nil || status != http.StatusBadGateway {
+		t.Fatalf("got %d, %v, want the last 502", status, err)
+	}
+	if *requests != int32(testRetryPolicy.MaxRetries+1) {
+		t.Errorf("server received %d requests, want %d", *requests,
+			testRetryPolicy.MaxRetries+1)
+	}
+
+	// statuses other than 429, 502, 503 and 504 are not retried
+	server, requests = failingServer(t, 10, http.StatusInternalServerError, nil, "")
+	status, err = send(t, client, context.Background(), http.MethodGet, server.URL, "")
+	if err != nil || status != http.StatusInternalServerError || *requests != 1 {
+		t.Errorf("got %d, %v after %d requests, want 500 after 1", status, err, *requests)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	const body = `{"tokenData":"123"}`
+	for _, test := range []struct {
+		name     string
+		ctx      context.Context
+		policy   RetryPolicy
+		requests int32
+		status   int
+	}{
+		{"unmarked", context.Background(), testRetryPolicy, 1,
+			http.StatusServiceUnavailable},
+		{"marked", Idempotent(context.Background()), testRetryPolicy, 2, http.StatusOK},
+		{"policy", context.Background(), RetryPolicy{MaxRetries: 3,
+			InitialBackoff: time.Millisecond, RetryNonIdempotent: true}, 2, http.StatusOK},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// the retried request must carry the body again
+			server, requests := failingServer(t, 1, http.StatusServiceUnavailable, nil,
+				body)
+			client, _ := newTestClient(t, ClientConfig{Retry: test.policy})
+
+			status, err := send(t, client, test.ctx, http.MethodPost, server.URL, body)
+			if err != nil || status != test.status || *requests != test.requests {
+				t.Errorf("got %d, %v after %d requests, want %d after %d", status, err,
+					*requests, test.status, test.requests)
+			}
+		})
+	}
+}
+
+func TestRetryDialError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	client, transport := newTestClient(t, ClientConfig{Retry: testRetryPolicy})
+
+	// a request which never reached the server is retried even if it
+	// is not idempotent
+	_, err := send(t, client, context.Background(), http.MethodPost, server.URL, "{}")
+	if err == nil || !isDialError(err) {
+		t.Fatalf("got %v, want a dial error", err)
+	}
+	if n := transport.count(); n != testRetryPolicy.MaxRetries+1 {
+		t.Errorf("sent the request %d times, want %d", n, testRetryPolicy.MaxRetries+1)
+	}
+}
+
+func TestRetryBodyWithoutGetBody(t *testing.T) {
+	server, requests := failingServer(t, 1, http.StatusServiceUnavailable, nil, "{}")
+	client, _ := newTestClient(t, ClientConfig{Retry: testRetryPolicy})
+
+	// a body that cannot be read again is not resent
+	request, err := http.NewRequestWithContext(Idempotent(context.Background()),
+		http.MethodPost, server.URL, io.NopCloser(bytes.NewBufferString("{}")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.doWithRetry(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusServiceUnavailable || *requests != 1 {
+		t.Errorf("got %d after %d requests, want 503 after 1", response.StatusCode,
+			*requests)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/getpasswd"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// SecretUsage is appended to the help of sensitive flags
+const SecretUsage = " To keep it out of the shell history and process list, " +
+	"give @FILE to read it from FILE, - to read it from stdin or env:VAR " +
+	"to read it from environment variable VAR. Use @@ for a leading @."
+
+var stdinSecretRead bool
+
+// trimNewline strips the line ending a file or stdin usually ends with
+func trimNewline(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// ReadSecret returns the secret value of a sensitive flag, given as
+// @FILE, - (stdin), env:VAR or the secret itself
+func ReadSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "@@"):
+		return value[1:], nil
+	case strings.HasPrefix(value, "@"):
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(data)), nil
+	case value == "-":
+		if stdinSecretRead {
+			return "", fmt.Errorf("only one secret can be read from stdin")
+		}
+		stdinSecretRead = true
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		return trimNewline(string(data)), nil
+	case strings.HasPrefix(value, "env:"):
+		name := strings.TrimPrefix(value, "env:")
+		secret, found := os.LookupEnv(name)
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	}
+	return value, nil
+}
+
+// IsTerminal reports whether stdin is a terminal the user can be
+// prompted on
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// PromptSecret prompts for a secret without echo if stdin is a
+// terminal and returns "" otherwise
+func PromptSecret(prompt string) string {
+	if !IsTerminal() {
+		return ""
+	}
+	fmt.Printf("%s: ", prompt)
+	secret := getpasswd.ReadPassword()
+	fmt.Printf("\n")
+	return secret
+}
+
+// GetSecretFlag returns the secret of the sensitive flag name. If the
+// flag is omitted, the secret is prompted for on a terminal unless
+// prompt is empty.
+func GetSecretFlag(flags *pflag.FlagSet, name, prompt string) (string, error) {
+	value, _ := flags.GetString(name)
+	if !flags.Changed(name) {
+		if prompt == "" {
+			return value, nil
+		}
+		return PromptSecret(prompt), nil
+	}
+	secret, err := ReadSecret(value)
+	if err != nil {
+		return "", fmt.Errorf("Error reading --%s - %v", name, err)
+	}
+	return secret, nil
+}
+
+// CommandSecretFlag is GetSecretFlag for commands, failing with a
+// CommandError. A required secret that is neither given nor entered is
+// reported as a missing flag.
+func CommandSecretFlag(flags *pflag.FlagSet, name, prompt string, required bool) (string, error) {
+	secret, err := GetSecretFlag(flags, name, prompt)
+	if err != nil {
+		return "", Errorf(ExitError, "\n%v\n\n", err)
+	}
+	if required && secret == "" {
+		return "", UsageError("\nrequired flag(s) %q not set\n\n", name)
+	}
+	return secret, nil
+}
@@ -0,0 +1,206 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultRenewWindow is how long before expiry sessions are renewed
+const DefaultRenewWindow = 5 * time.Minute
+
+var gRenewWindow = DefaultRenewWindow
+
+// renewMu serializes automatic renewals, tokenMu guards the access
+// token of gTokenInfo against concurrent requests
+var (
+	renewMu sync.Mutex
+	tokenMu sync.RWMutex
+)
+
+// expiration returns when the login session expires, if known
+func (info tokenInfo) expiration() (time.Time, bool) {
+	if info.ExpiresAt == "" {
+		return time.Time{}, false
+	}
+	expiration, err := time.Parse(time.RFC3339, info.ExpiresAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiration, true
+}
+
+// RenewSession renews the login session and saves the new access
+// token to the token file in use
+func RenewSession(ctx context.Context) (*vault.Session, error) {
+	session, err := GetVault().Renew(ctx)
+	if err != nil {
+		return session, err
+	}
+
+	tokenMu.Lock()
+	gTokenInfo.AccessToken = session.AccessToken
+	gTokenInfo.ExpiresAt = session.ExpiresAt
+	info := gTokenInfo
+	tokenMu.Unlock()
+	if _, err = SaveAccessToken(gAccessTokenFile, info); err != nil {
+		return session, fmt.Errorf("Error saving access token - %v", err)
+	}
+	return session, nil
+}
+
+// RenewSessionIfDue renews the login session if it expires within
+// the renew window. Commands call it once they start, and long running
+// ones, such as the batch engine, again before each request; the SDK
+// clients of GetVault then send the renewed token. A failed renewal is
+// reported but not fatal, as the session may still be valid.
+func RenewSessionIfDue(ctx context.Context) {
+	// personal access tokens are not renewed, they expire as set
+	if gRenewWindow <= 0 || gTokenInfo.AuthMethod == authMethodPAT {
+		return
+	}
+
+	renewMu.Lock()
+	defer renewMu.Unlock()
+
+	expiration, ok := gTokenInfo.expiration()
+	if !ok {
+		return
+	}
+	remaining := time.Until(expiration)
+	if remaining <= 0 || remaining > gRenewWindow {
+		return
+	}
+
+	session, err := RenewSession(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nWARNING: Automatic session renewal failed - %v\n",
+			strings.TrimSpace(err.Error()))
+		return
+	}
+	if gVerbose {
+		expiresAt, _ := formatLoginExpiration(session.ExpiresAt)
+		fmt.Fprintf(os.Stderr, "Session renewed, expires at %s\n", expiresAt)
+	}
+}
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Login session commands",
+}
+
+// sessionStatus is the login session as shown with --output. Remaining
+// is left empty once the session expired.
+type sessionStatus struct {
+	User      string   `json:"user"`
+	Server    string   `json:"server"`
+	Nodes     []string `json:"nodes,omitempty"`
+	TokenFile string   `json:"token_file"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+	Remaining string   `json:"remaining,omitempty"`
+	Expired   bool     `json:"expired"`
+}
+
+var sessionStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show user, server and remaining lifetime of the login session",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tokenFile, _ := tokenFilePath(gAccessTokenFile)
+		status := sessionStatus{User: gTokenInfo.User, Server: GetServer(),
+			TokenFile: tokenFile}
+		if nodes := GetNodes(); len(nodes) > 1 {
+			status.Nodes = nodes
+		}
+		expiration, ok := gTokenInfo.expiration()
+		if ok {
+			status.ExpiresAt = expiration.Format(time.RFC3339)
+			remaining := time.Until(expiration).Round(time.Second)
+			status.Expired = remaining <= 0
+			if !status.Expired {
+				status.Remaining = remaining.String()
+			}
+		}
+
+		if !DefaultOutput() {
+			if err := PrintStatus("", status); err != nil {
+				return err
+			}
+			if status.Expired {
+				return Errorf(ExitAuthExpired, "")
+			}
+			return nil
+		}
+
+		fmt.Printf("\nUser       : %s\n", status.User)
+		fmt.Printf("Server     : %s\n", status.Server)
+		if len(status.Nodes) > 0 {
+			fmt.Printf("Nodes      : %s\n", strings.Join(status.Nodes, ", "))
+		}
+		fmt.Printf("Token File : %s\n", status.TokenFile)
+		if !ok {
+			fmt.Printf("Expires At : unknown - log in again to record it\n\n")
+			return nil
+		}
+		fmt.Printf("Expires At : %s\n", formatExpiration(expiration))
+		if status.Expired {
+			return Errorf(ExitAuthExpired, "Remaining  : expired - log in again\n\n")
+		}
+		fmt.Printf("Remaining  : %s\n\n", status.Remaining)
+		return nil
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Log out by discarding the access token file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// the token file need not be readable to be discarded
+		tokenFile, err := resolveTokenFile()
+		if err == nil {
+			tokenFile, err = tokenFilePath(tokenFile)
+		}
+		if err == nil {
+			err = os.Remove(tokenFile)
+		}
+		if err != nil {
+			if os.IsNotExist(err) {
+				return PrintStatus(fmt.Sprintf("\nNot logged in - %s not found\n\n",
+					tokenFile), map[string]string{"status": "not_logged_in",
+					"token_file": tokenFile})
+			}
+			return Errorf(ExitError, "\nError removing token file %s - %v\n\n", tokenFile, err)
+		}
+		return PrintStatus(fmt.Sprintf("\nLogged out. Removed %s.\n\n", tokenFile),
+			map[string]string{"status": "logged_out", "token_file": tokenFile})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+	sessionCmd.AddCommand(sessionStatusCmd)
+	rootCmd.AddCommand(logoutCmd)
+}
@@ -17,81 +17,38 @@ limitations under the License.
 package cmd
 
 import (
-    // standard
-    "os"
-    "fmt"
-    "bytes"
-    "encoding/json"
-    // external
-    "github.com/spf13/cobra"
+	// standard
+	"cli/pkg/vault"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 // setPolicyVersionCmd represents the set-policy-version command
 var setPolicyVersionCmd = &cobra.Command{
-    Use:   "set-policy-version",
-    Short: "Set a specific version of Policy to current",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        params := map[string]interface{}{}
-
-        // create request payload
-        policyId, _ := flags.GetString("policyid")
-        params["policy_id"] = policyId
-
-        
-        version, _ := flags.GetInt("version")
-        params["version"] = version
-
-        // JSONify
-        jsonParams, err := json.Marshal(params)
-        if (err != nil) {
-            fmt.Println("Error building JSON request: ", err)
-            os.Exit(1)
-        }
-
-        // now POST
-        endpoint := GetEndPoint("", "1.0", "SetPolicyVersion")
-        ret, err := DoPost(endpoint,
-                               GetCACertFile(),
-                               AuthTokenKV(),
-                               jsonParams,
-                               "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        } else {
-            // type assertion
-            retBytes := ret["data"].(*bytes.Buffer)
-            retStatus := ret["status"].(int)
-            retStr := retBytes.String()
-
-            if (retStr == "" && retStatus == 404) {
-                fmt.Println("\nPolicy not found\n")
-                os.Exit(5)
-            }
-
-            fmt.Println("\n" + retStr + "\n")
-
-            // make a decision on what to exit with
-            retMap := JsonStrToMap(retStr)
-            if _, present := retMap["error"]; present {
-                os.Exit(3)
-            } else {
-                os.Exit(0)
-            }
-        }
-    },
+	Use:   "set-policy-version",
+	Short: "Set a specific version of Policy to current",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		request := vault.PolicyVersion{}
+		request.PolicyID, _ = flags.GetString("policyid")
+		request.Version, _ = flags.GetInt("version")
+
+		response, err := GetVault().SetPolicyVersion(cmd.Context(), request)
+		if err != nil {
+			return VaultError(err, "Policy not found")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(setPolicyVersionCmd)
-    setPolicyVersionCmd.Flags().StringP("policyid", "p", "",
-                                 "Id or name of the Policy")
-    setPolicyVersionCmd.Flags().IntP("version", "v", 0,
-                              "Version of the Policy to be set as current")
-
-    // mark mandatory fields as required
-    setPolicyVersionCmd.MarkFlagRequired("policyid")
-    setPolicyVersionCmd.MarkFlagRequired("version")
+	rootCmd.AddCommand(setPolicyVersionCmd)
+	setPolicyVersionCmd.Flags().StringP("policyid", "p", "",
+		"Id or name of the Policy")
+	setPolicyVersionCmd.Flags().IntP("version", "v", 0,
+		"Version of the Policy to be set as current")
+
+	// mark mandatory fields as required
+	setPolicyVersionCmd.MarkFlagRequired("policyid")
+	setPolicyVersionCmd.MarkFlagRequired("version")
 }
@@ -0,0 +1,252 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/getpasswd"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// TokenKeyEnv names the environment variable holding the base64
+	// encoded AES-256 key of encrypted token files
+	TokenKeyEnv = "CRYPTOCLI_TOKEN_KEY"
+
+	// TokenPassphraseEnv names the environment variable holding the
+	// passphrase of encrypted token files
+	TokenPassphraseEnv = "CRYPTOCLI_TOKEN_PASSPHRASE"
+
+	encryptedTokenFormat = "cryptocli-encrypted-token-v1"
+
+	kdfArgon2id = "argon2id"
+	kdfNone     = "none" // key taken from TokenKeyEnv
+
+	// Argon2id parameters recommended by RFC 9106 for memory
+	// constrained environments
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024 // KiB
+	argon2Threads = 4
+	argon2SaltLen = 16
+
+	tokenKeyLen = 32
+)
+
+var errTokenDecrypt = errors.New("Unable to decrypt token file - wrong passphrase or key")
+
+type tokenKDF struct {
+	Algorithm string `json:"algorithm"`
+	Salt      string `json:"salt,omitempty"`
+	Time      uint32 `json:"time,omitempty"`
+	Memory    uint32 `json:"memory,omitempty"`
+	Threads   uint8  `json:"threads,omitempty"`
+}
+
+// encryptedToken is the content of an encrypted token file, the
+// tokenInfo JSON sealed with AES-256-GCM
+type encryptedToken struct {
+	Format     string   `json:"format"`
+	KDF        tokenKDF `json:"kdf"`
+	Nonce      string   `json:"nonce"`
+	Ciphertext string   `json:"ciphertext"`
+}
+
+// tokenKey is the key token files of this session are encrypted with
+type tokenKey struct {
+	kdf tokenKDF
+	key []byte
+}
+
+// gTokenKey is set if the token file is encrypted. Renewed tokens are
+// saved encrypted with the same key.
+var gTokenKey *tokenKey
+
+func deriveTokenKey(passphrase string, kdf tokenKDF) (*tokenKey, error) {
+	salt, err := base64.StdEncoding.DecodeString(kdf.Salt)
+	if err != nil || kdf.Time == 0 || kdf.Memory == 0 || kdf.Threads == 0 {
+		return nil, fmt.Errorf("Invalid or corrupt token file - bad %s parameters",
+			kdfArgon2id)
+	}
+	key := argon2.IDKey([]byte(passphrase), salt, kdf.Time, kdf.Memory,
+		kdf.Threads, tokenKeyLen)
+	return &tokenKey{kdf: kdf, key: key}, nil
+}
+
+func envTokenKey() (*tokenKey, error) {
+	key, err := base64.StdEncoding.DecodeString(os.Getenv(TokenKeyEnv))
+	if err != nil || len(key) != tokenKeyLen {
+		return nil, fmt.Errorf("Invalid %s - expected base64 encoded %d byte key",
+			TokenKeyEnv, tokenKeyLen)
+	}
+	return &tokenKey{kdf: tokenKDF{Algorithm: kdfNone}, key: key}, nil
+}
+
+func readTokenPassphrase(confirm bool) (string, error) {
+	if passphrase := os.Getenv(TokenPassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+	fmt.Printf("Token file passphrase: ")
+	passphrase := getpasswd.ReadPassword()
+	fmt.Printf("\n")
+	if passphrase == "" {
+		return "", fmt.Errorf("A passphrase is required to encrypt the token file")
+	}
+	if confirm {
+		fmt.Printf("Confirm passphrase: ")
+		again := getpasswd.ReadPassword()
+		fmt.Printf("\n")
+		if again != passphrase {
+			return "", fmt.Errorf("Passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// TokenEncryptionRequested reports whether the environment supplies a
+// key or passphrase to encrypt token files with
+func TokenEncryptionRequested() bool {
+	return os.Getenv(TokenKeyEnv) != "" || os.Getenv(TokenPassphraseEnv) != ""
+}
+
+// NewTokenKey sets up encryption of the token file saved at login.
+// The key is taken from TokenKeyEnv or derived with Argon2id from a
+// passphrase given by TokenPassphraseEnv or entered by the user.
+func NewTokenKey() error {
+	if os.Getenv(TokenKeyEnv) != "" {
+		key, err := envTokenKey()
+		if err != nil {
+			return err
+		}
+		gTokenKey = key
+		return nil
+	}
+
+	passphrase, err := readTokenPassphrase(true)
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key, err := deriveTokenKey(passphrase, tokenKDF{
+		Algorithm: kdfArgon2id,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Time:      argon2Time,
+		Memory:    argon2Memory,
+		Threads:   argon2Threads})
+	if err != nil {
+		return err
+	}
+	gTokenKey = key
+	return nil
+}
+
+func (k *tokenKey) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptToken seals the tokenInfo JSON plaintext with gTokenKey
+func encryptToken(plaintext []byte) (encryptedToken, error) {
+	aead, err := gTokenKey.aead()
+	if err != nil {
+		return encryptedToken{}, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return encryptedToken{}, err
+	}
+	ciphertext := aead.Seal(nil, nonce, plaintext, []byte(encryptedTokenFormat))
+	return encryptedToken{
+		Format:     encryptedTokenFormat,
+		KDF:        gTokenKey.kdf,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext)}, nil
+}
+
+// decryptToken opens an encrypted token file and sets gTokenKey to
+// the key it was encrypted with
+func decryptToken(sealed encryptedToken) ([]byte, error) {
+	var key *tokenKey
+	var err error
+	switch sealed.KDF.Algorithm {
+	case kdfNone:
+		if os.Getenv(TokenKeyEnv) == "" {
+			return nil, fmt.Errorf("Token file is encrypted - set %s to its key",
+				TokenKeyEnv)
+		}
+		key, err = envTokenKey()
+	case kdfArgon2id:
+		var passphrase string
+		if passphrase, err = readTokenPassphrase(false); err == nil {
+			key, err = deriveTokenKey(passphrase, sealed.KDF)
+		}
+	default:
+		err = fmt.Errorf("Unsupported token file key derivation %q",
+			sealed.KDF.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(sealed.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid or corrupt token file - bad nonce")
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid or corrupt token file - bad ciphertext")
+	}
+	aead, err := key.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("Invalid or corrupt token file - bad nonce")
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(encryptedTokenFormat))
+	if err != nil {
+		return nil, errTokenDecrypt
+	}
+	gTokenKey = key
+	return plaintext, nil
+}
+
+// warnFilePermissions warns if other users may access file. Windows
+// controls access with ACLs rather than permission bits.
+func warnFilePermissions(file string, info os.FileInfo) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		fmt.Fprintf(os.Stderr, "\nWARNING: %s is accessible by other users "+
+			"(permissions %04o). Restrict it by running: chmod 600 %s\n",
+			file, perm, file)
+	}
+}
@@ -0,0 +1,203 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testTokenPlaintext = `{"access_token":"secret-token","server":"vault.test"}`
+
+// isolateTokenKey clears the token encryption settings of the
+// environment and restores gTokenKey and gTokenInfo after the test
+func isolateTokenKey(t *testing.T) {
+	t.Setenv(TokenKeyEnv, "")
+	t.Setenv(TokenPassphraseEnv, "")
+	key, info := gTokenKey, gTokenInfo
+	t.Cleanup(func() {
+		gTokenKey, gTokenInfo = key, info
+	})
+	gTokenKey = nil
+}
+
+// testTokenKey returns a base64 encoded AES-256 key made of b
+func testTokenKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, tokenKeyLen))
+}
+
+// sealTestToken encrypts testTokenPlaintext with a new token key
+func sealTestToken(t *testing.T) encryptedToken {
+	t.Helper()
+	if err := NewTokenKey(); err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := encryptToken([]byte(testTokenPlaintext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed.Format != encryptedTokenFormat {
+		t.Errorf("format %q, want %q", sealed.Format, encryptedTokenFormat)
+	}
+	ciphertext, _ := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if bytes.Contains(ciphertext, []byte("secret-token")) {
+		t.Error("the ciphertext holds the access token")
+	}
+	gTokenKey = nil
+	return sealed
+}
+
+// openTestToken decrypts sealed and checks it holds testTokenPlaintext
+func openTestToken(t *testing.T, sealed encryptedToken) {
+	t.Helper()
+	plaintext, err := decryptToken(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != testTokenPlaintext {
+		t.Errorf("decrypted %q, want %q", plaintext, testTokenPlaintext)
+	}
+	if gTokenKey == nil || gTokenKey.kdf != sealed.KDF {
+		t.Errorf("token key %+v, want the key of the token file", gTokenKey)
+	}
+}
+
+func TestTokenKeyRoundTrip(t *testing.T) {
+	isolateTokenKey(t)
+	t.Setenv(TokenKeyEnv, testTokenKey(1))
+
+	sealed := sealTestToken(t)
+	if sealed.KDF != (tokenKDF{Algorithm: kdfNone}) {
+		t.Errorf("KDF %+v, want %s", sealed.KDF, kdfNone)
+	}
+	openTestToken(t, sealed)
+
+	// a file encrypted twice with the same key differs by its nonce
+	again := sealTestToken(t)
+	if again.Nonce == sealed.Nonce || again.Ciphertext == sealed.Ciphertext {
+		t.Error("the nonce was reused")
+	}
+}
+
+func TestTokenPassphraseRoundTrip(t *testing.T) {
+	isolateTokenKey(t)
+	t.Setenv(TokenPassphraseEnv, "correct horse battery staple")
+
+	sealed := sealTestToken(t)
+	salt, _ := base64.StdEncoding.DecodeString(sealed.KDF.Salt)
+	if sealed.KDF.Algorithm != kdfArgon2id || len(salt) != argon2SaltLen ||
+		sealed.KDF.Time != argon2Time || sealed.KDF.Memory != argon2Memory ||
+		sealed.KDF.Threads != argon2Threads {
+		t.Errorf("KDF %+v, want %s with the default parameters", sealed.KDF, kdfArgon2id)
+	}
+	openTestToken(t, sealed)
+}
+
+func TestTokenWrongKey(t *testing.T) {
+	isolateTokenKey(t)
+	t.Setenv(TokenKeyEnv, testTokenKey(1))
+	sealed := sealTestToken(t)
+
+	t.Setenv(TokenKeyEnv, testTokenKey(2))
+	if _, err := decryptToken(sealed); !errors.Is(err, errTokenDecrypt) {
+		t.Errorf("wrong key: got %v, want %v", err, errTokenDecrypt)
+	}
+	t.Setenv(TokenKeyEnv, "")
+	if _, err := decryptToken(sealed); err == nil {
+		t.Error("no key: decrypted the token file")
+	}
+	if gTokenKey != nil {
+		t.Errorf("token key %+v set by a failed decryption", gTokenKey)
+	}
+
+	t.Setenv(TokenPassphraseEnv, "correct horse battery staple")
+	sealed = sealTestToken(t)
+	t.Setenv(TokenPassphraseEnv, "wrong horse battery staple")
+	if _, err := decryptToken(sealed); !errors.Is(err, errTokenDecrypt) {
+		t.Errorf("wrong passphrase: got %v, want %v", err, errTokenDecrypt)
+	}
+}
+
+func TestTokenTampered(t *testing.T) {
+	isolateTokenKey(t)
+	t.Setenv(TokenKeyEnv, testTokenKey(1))
+	sealed := sealTestToken(t)
+
+	ciphertext, _ := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	ciphertext[0] ^= 1
+	tampered := sealed
+	tampered.Ciphertext = base64.StdEncoding.EncodeToString(ciphertext)
+	if _, err := decryptToken(tampered); !errors.Is(err, errTokenDecrypt) {
+		t.Errorf("tampered ciphertext: got %v, want %v", err, errTokenDecrypt)
+	}
+
+	// a passphrase, so that the Argon2id file is not prompted for one
+	t.Setenv(TokenPassphraseEnv, "correct horse battery staple")
+	for _, tampered := range []encryptedToken{
+		{Format: sealed.Format, KDF: sealed.KDF, Nonce: "AAAA", Ciphertext: sealed.Ciphertext},
+		{Format: sealed.Format, KDF: sealed.KDF, Nonce: sealed.Nonce, Ciphertext: "%"},
+		{Format: sealed.Format, KDF: tokenKDF{Algorithm: "rot13"}, Nonce: sealed.Nonce,
+			Ciphertext: sealed.Ciphertext},
+		{Format: sealed.Format, KDF: tokenKDF{Algorithm: kdfArgon2id}, Nonce: sealed.Nonce,
+			Ciphertext: sealed.Ciphertext},
+	} {
+		if _, err := decryptToken(tampered); err == nil {
+			t.Errorf("decrypted %+v", tampered)
+		}
+	}
+}
+
+func TestEncryptedTokenFile(t *testing.T) {
+	isolateTokenKey(t)
+	t.Setenv(TokenKeyEnv, testTokenKey(1))
+	if err := NewTokenKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenFile := filepath.Join(t.TempDir(), "token.json")
+	info := tokenInfo{AccessToken: "secret-token", Server: "vault.test"}
+	if _, err := SaveAccessToken(tokenFile, info); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(tokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("secret-token")) {
+		t.Errorf("the token file holds the access token: %s", data)
+	}
+	if fileInfo, _ := os.Stat(tokenFile); fileInfo.Mode().Perm() != 0600 {
+		t.Errorf("token file permissions %04o, want 0600", fileInfo.Mode().Perm())
+	}
+
+	gTokenKey, gTokenInfo = nil, tokenInfo{}
+	if _, err = LoadAccessToken(tokenFile); err != nil {
+		t.Fatal(err)
+	}
+	if gTokenInfo.AccessToken != info.AccessToken || gTokenInfo.Server != info.Server {
+		t.Errorf("loaded %+v, want %+v", gTokenInfo, info)
+	}
+
+	t.Setenv(TokenKeyEnv, testTokenKey(2))
+	if _, err = LoadAccessToken(tokenFile); !errors.Is(err, errTokenDecrypt) {
+		t.Errorf("wrong key: got %v, want %v", err, errTokenDecrypt)
+	}
+}
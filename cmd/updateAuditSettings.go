@@ -18,10 +18,7 @@ package cmd
 
 import (
 	// standard
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	// external
 	"github.com/spf13/cobra"
 )
@@ -30,77 +27,39 @@ import (
 var updateAuditSettingsCmd = &cobra.Command{
 	Use:   "update-audit-settings",
 	Short: "Update audit settings",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
+		settings := vault.AuditSettings{}
 
-		// create request payload
 		if flags.Changed("retention-days") {
 			retentionDays, _ := flags.GetInt32("retention-days")
 			if retentionDays < 0 {
-				fmt.Printf("\nRetention days must be 0 (retain all) or greater\n\n")
-				os.Exit(1)
+				return Errorf(ExitUsage, "\nRetention days must be 0 (retain all) or greater\n\n")
 			}
-			params["auditlog_retention"] = retentionDays
+			settings.Retention = &retentionDays
 		}
 
 		if flags.Changed("max-logs-size") {
 			maxLogsSize, _ := flags.GetInt64("max-logs-size")
 			if maxLogsSize < 0 {
-				fmt.Printf("\nMaximum log size must be 0 (no limit) or greater\n\n")
-				os.Exit(1)
+				return Errorf(ExitUsage, "\nMaximum log size must be 0 (no limit) or greater\n\n")
 			}
-			params["auditlog_totsize"] = maxLogsSize
+			settings.TotalSize = &maxLogsSize
 		}
 
-		if len(params) <= 0 {
+		if settings.Retention == nil && settings.TotalSize == nil {
 			cmd.Usage()
-			os.Exit(1)
+			return &CommandError{Code: ExitUsage}
 		}
 
-		// JSONify
-		jsonParams, err := json.Marshal(params)
+		_, err := GetVault().UpdateAuditSettings(cmd.Context(), settings)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		// now POST
-		endpoint := GetEndPoint("", "1.0", "UpdateAuditSetting")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			// type assertion
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAudit settings not found\n")
-				os.Exit(5)
-			}
-
-			if retStatus == 204 {
-				fmt.Println("\nUpdate successful\n")
-				os.Exit(0)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				// make a decision on what to exit with
-				retMap := JsonStrToMap(retStr)
-				if _, present := retMap["error"]; present {
-					os.Exit(3)
-				} else {
-					fmt.Println("\nUnknown error\n")
-					os.Exit(0)
-				}
-			}
+			return VaultError(err, "Audit settings not found")
 		}
+		return PrintStatus("\nUpdate successful\n\n", struct {
+			Status string `json:"status"`
+			vault.AuditSettings
+		}{"updated", settings})
 	},
 }
 
@@ -108,8 +67,8 @@ func init() {
 	rootCmd.AddCommand(updateAuditSettingsCmd)
 	// auditlog_retention
 	updateAuditSettingsCmd.Flags().Int32P("retention-days", "r", 0,
-		"Number of days to retain the audit logs. Retention days can be set between " +
-        "30 - 365 days. Set 0 to retain all.")
+		"Number of days to retain the audit logs. Retention days can be set between "+
+			"30 - 365 days. Set 0 to retain all.")
 	updateAuditSettingsCmd.Flags().Int64P("max-logs-size", "m", 0,
 		"Maximum size for the audit logs in bytes. Set 0 for no limit.")
 }
@@ -17,27 +17,23 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"fmt"
 	"github.com/spf13/cobra"
-	"os"
 	"time"
 )
 
 var updatePersonalAccessTokenCmd = &cobra.Command{
 	Use:   "update-personal-access-token",
 	Short: "Update a Personal Access Token",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		name, _ := flags.GetString("name")
-		params["name"] = name
+		token := vault.PersonalAccessToken{}
+		token.Name, _ = flags.GetString("name")
 
 		if flags.Changed("description") {
 			description, _ := flags.GetString("description")
-			params["description"] = description
+			token.Description = &description
 		}
 
 		if flags.Changed("expiry") {
@@ -45,55 +41,26 @@ var updatePersonalAccessTokenCmd = &cobra.Command{
 			expiry = expiry + "T10:00:00Z"
 			thetime, e := time.Parse(time.RFC3339, expiry)
 			if e != nil {
-				fmt.Println("Can't parse time format\n")
+				fmt.Printf("Can't parse time format\n\n")
 			}
-			params["expiry"] = thetime.Unix()
+			expiryTime := thetime.Unix()
+			token.Expiry = &expiryTime
 		}
 
 		if flags.Changed("revoked") {
 			revoked, _ := flags.GetBool("revoked")
-			params["revoked"] = revoked
-		}
-
-		if len(params) < 2 {
-			fmt.Println("nothing to update")
-			os.Exit(1)
+			token.Revoked = &revoked
 		}
 
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+		if token.Description == nil && token.Expiry == nil && token.Revoked == nil {
+			return Errorf(ExitUsage, "nothing to update\n")
 		}
 
-		endpoint := GetEndPoint("", "1.0", "UpdatePersonalAccessToken")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().UpdatePersonalAccessToken(cmd.Context(), token)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
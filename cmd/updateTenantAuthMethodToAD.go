@@ -19,13 +19,13 @@ package cmd
 import (
 	// standard
 	"bufio"
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
 	// custom
 	"cli/getpasswd"
+	"cli/pkg/vault"
 
 	// external
 	"github.com/spf13/cobra"
@@ -47,65 +47,6 @@ const (
 	initialADMemberUPN               = "initial-ad-member-upn"
 )
 
-type AuthToADServer struct {
-	ServerURL   string `json:"server_url"`
-	TLS         bool   `json:"tls,omitempty"`
-	UserBaseDN  string `json:"user_base_dn,omitempty"`
-	GroupBaseDN string `json:"group_base_dn,omitempty"`
-	Timeout     int    `json:"timeout,omitempty"`
-	CACert      string `json:"cacert,omitempty"`
-}
-
-type ADDomain struct {
-	DomainName         string           `json:"domain_name,omitempty"`
-	DomainType         string           `json:"type,omitempty"`
-	ServiceAccountName string           `json:"service_account_name,omitempty"`
-	ServiceAccountPw   string           `json:"service_account_pw,omitempty"`
-	UID                string           `json:"uid,omitempty"`
-	Servers            []AuthToADServer `json:"servers,omitempty"`
-}
-
-type InitialADmember struct {
-	CN                string `json:"cn,omitempty"`
-	DistinguishedName string `json:"distinguishedName,omitempty"`
-	Mail              string `json:"mail,omitempty"`
-	UPN               string `json:"upn,omitempty"`
-}
-
-type updateTenentAuthModeToADApiResponse struct {
-	Result string `json:"result"`
-}
-
-func adDomainParseServersJSONFile(JSONFile string, v interface{}) error {
-	file, err := os.Open(JSONFile)
-	if err != nil {
-		fmt.Printf("Error opening file %s - %v\n", JSONFile, err)
-		return err
-	}
-	defer file.Close()
-
-	err = json.NewDecoder(file).Decode(v)
-	if err != nil {
-		fmt.Printf("Error decoding Servers JSON - %v\n", err)
-		return err
-	}
-	return nil
-}
-
-func adDomainParseCACertFile(Servers []AuthToADServer) error {
-	for idx := range Servers {
-		if Servers[idx].CACert != "" {
-			encoded, err := LoadAndEncodeCACertFile(Servers[idx].CACert)
-			if err != nil {
-				fmt.Printf("%v\n", err)
-				return err
-			}
-			Servers[idx].CACert = encoded
-		}
-	}
-	return nil
-}
-
 func getADServiceCredentials(prefix, user, password string) (string, string) {
 
 	if user == "" {
@@ -130,16 +71,19 @@ func getADServiceCredentials(prefix, user, password string) (string, string) {
 	return user, password
 }
 
-func updateTenantAuthMethodToAD(cmd *cobra.Command, args []string) {
+func updateTenantAuthMethodToAD(cmd *cobra.Command, args []string) error {
 
-	params := map[string]interface{}{}
-	var adDomain ADDomain
-	var initialADmember InitialADmember
+	var request vault.AuthMethodToADRequest
+	adDomain := &request.ADDomain
+	initialADmember := &request.InitialADMember
 
 	flags := cmd.Flags()
 
 	serviceUsername, _ := flags.GetString(adServiceAccountName)
-	servicePassword, _ := flags.GetString(adServiceAccountPw)
+	servicePassword, err := CommandSecretFlag(flags, adServiceAccountPw, "", false)
+	if err != nil {
+		return err
+	}
 	if servicePassword == "" || serviceUsername == "" {
 		fmt.Printf("\n")
 		serviceUsername, servicePassword = getADServiceCredentials("Service", serviceUsername, servicePassword)
@@ -153,13 +97,11 @@ func updateTenantAuthMethodToAD(cmd *cobra.Command, args []string) {
 
 	JSONFile, _ := flags.GetString(adServers)
 	if JSONFile != "" {
-		err := adDomainParseServersJSONFile(JSONFile, &adDomain.Servers)
-		if err != nil {
-			os.Exit(1)
+		if err := parseServersJSONFile(JSONFile, &adDomain.Servers); err != nil {
+			return err
 		}
-		err = adDomainParseCACertFile(adDomain.Servers)
-		if err != nil {
-			os.Exit(1)
+		if err := parseCACertFile(adDomain.Servers); err != nil {
+			return err
 		}
 	}
 
@@ -168,30 +110,19 @@ func updateTenantAuthMethodToAD(cmd *cobra.Command, args []string) {
 	initialADmember.Mail, _ = flags.GetString(initialADMemberMail)
 	initialADmember.UPN, _ = flags.GetString(initialADMemberUPN)
 
-	params["ad_domain"] = adDomain
-
-	params["name"], _ = flags.GetString(Name)
-
-	params["initial_admember"] = initialADmember
+	request.Name, _ = flags.GetString(Name)
 
-	jsonParams, err := json.Marshal(params)
+	respData, err := GetVault().UpdateTenantAuthMethodToAD(cmd.Context(), request)
 	if err != nil {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
+		return OperationError("Updating auth method to Active Directory failed:", err)
 	}
 
-	var respData updateTenentAuthModeToADApiResponse
-	endpoint := GetEndPoint("", "1.0", "UpdateTenantAuthMethodToAD")
-	_, err = DoPost2(endpoint, GetCACertFile(),
-		AuthTokenKV(),
-		jsonParams, ContentTypeJSON, &respData, nil)
-	if err != nil {
-		fmt.Printf("\nUpdating auth method to Active Directory failed:\n\n%v\n", err)
-		os.Exit(1)
+	if !DefaultOutput() {
+		return PrintVaultResult(respData.JSON())
 	}
-
 	fmt.Printf("\nAuth method updated to Active Directory successfully.\n")
-	fmt.Printf("\nResult : %s\n", respData.Result)
+	fmt.Printf("\nResult : %s\n", respData.Status)
+	return nil
 }
 
 func init() {
@@ -199,7 +130,7 @@ func init() {
 	var updateTenantAuthMethodToADCommand = &cobra.Command{
 		Use:   "update-vault-auth-method-to-ad",
 		Short: "Update Vault Auth Method To AD",
-		Run:   updateTenantAuthMethodToAD,
+		RunE:  updateTenantAuthMethodToAD,
 	}
 	updateTenantAuthMethodToADCommand.Flags().StringP(authADDomainName, "a", "",
 		"Active Directory Name")
@@ -213,7 +144,8 @@ func init() {
 			"To clear, set it to \"unset\".")
 	updateTenantAuthMethodToADCommand.Flags().StringP(adServiceAccountPw, "p", "",
 		"Active Directory Service Account Password. "+
-			"Users have the option to input values either through the console or by using a flag")
+			"Users have the option to input values either through the console or by using a flag."+
+			SecretUsage)
 	updateTenantAuthMethodToADCommand.Flags().StringP(adServers, "j", "",
 		"Path to the Active Directory Domain server List JSON File. The file should  "+
 			"contain an array of JSON objects, each object representing a Domain Controller. "+
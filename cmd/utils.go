@@ -27,8 +27,6 @@ import (
 	"math/big"
     "bytes"
 	"time"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
 	"strings"
 )
@@ -36,51 +34,94 @@ import (
 const RandomStringCharSet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz1234567890"
 
 type tokenInfo struct {
-	AccessToken string `json:"access_token"`
-	Server      string `json:"server"`
-	CACertFile  string `json:"cacert_file"`
+	AccessToken        string   `json:"access_token"`
+	Server             string   `json:"server"`
+	CACertFile         string   `json:"cacert_file"`
+	Servers            []string `json:"servers,omitempty"` // cluster nodes in order of preference
+	SkipHostnameVerify bool     `json:"skip_hostname_verify,omitempty"`
+	Pins               []string `json:"spki_pins,omitempty"` // SPKI SHA-256 pins of the vault certificates
+	ClientCertFile     string   `json:"client_cert_file,omitempty"`
+	ClientKeyFile      string   `json:"client_key_file,omitempty"`
+	User               string   `json:"user,omitempty"`
+	ExpiresAt          string   `json:"expires_at,omitempty"` // RFC 3339 expiry of the login session
+	AuthMethod         string   `json:"auth_method,omitempty"` // empty for username and password
 }
 
 var gTokenInfo tokenInfo
 
-func SaveAccessToken(tokenFile, accessToken, server, caCertFile string) (string, error) {
-	if tokenFile == "" {
-		tokenDir, err := GetDataDir()
+var gClient *Client
+
+// tokenFilePath returns tokenFile, by default the token file in the
+// data directory
+func tokenFilePath(tokenFile string) (string, error) {
+	if tokenFile != "" {
+		return tokenFile, nil
+	}
+	tokenDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(tokenDir, DefaultTokenFilename), nil
+}
+
+func SaveAccessToken(tokenFile string, info tokenInfo) (string, error) {
+	tokenFile, err := tokenFilePath(tokenFile)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(&info)
+	if err != nil {
+		return tokenFile, err
+	}
+	if gTokenKey != nil {
+		sealed, err := encryptToken(data)
 		if err != nil {
-			return "", err
+			return tokenFile, err
+		}
+		if data, err = json.Marshal(&sealed); err != nil {
+			return tokenFile, err
 		}
-		tokenFile = filepath.Join(tokenDir, DefaultTokenFilename)
 	}
 
-	info := tokenInfo{
-		AccessToken: accessToken,
-		Server:      server,
-		CACertFile:  caCertFile}
-
-	file, err := os.Create(tokenFile)
+	// readable by the owner only, also if the file existed before
+	file, err := os.OpenFile(tokenFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return tokenFile, err
 	}
 	defer file.Close()
-	return tokenFile, json.NewEncoder(file).Encode(&info)
+	if err = file.Chmod(0600); err != nil {
+		return tokenFile, err
+	}
+	_, err = file.Write(append(data, '\n'))
+	return tokenFile, err
 }
 
 func LoadAccessToken(tokenFile string) (string, error) {
-	if tokenFile == "" {
-		tokenDir, err := GetDataDir()
-		if err != nil {
-			return "", err
-		}
-		tokenFile = filepath.Join(tokenDir, DefaultTokenFilename)
+	tokenFile, err := tokenFilePath(tokenFile)
+	if err != nil {
+		return "", err
 	}
 
-	file, err := os.Open(tokenFile)
+	fileInfo, err := os.Stat(tokenFile)
 	if err != nil {
 		return tokenFile, err
 	}
-	defer file.Close()
+	warnFilePermissions(tokenFile, fileInfo)
+
+	data, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return tokenFile, err
+	}
+
+	var sealed encryptedToken
+	if json.Unmarshal(data, &sealed) == nil && sealed.Format == encryptedTokenFormat {
+		if data, err = decryptToken(sealed); err != nil {
+			return tokenFile, err
+		}
+	}
 
-	err = json.NewDecoder(file).Decode(&gTokenInfo)
+	err = json.Unmarshal(data, &gTokenInfo)
 	if err != nil {
 		return tokenFile, err
 	}
@@ -93,6 +134,8 @@ func LoadAccessToken(tokenFile string) (string, error) {
 }
 
 func GetAccessToken() string {
+	tokenMu.RLock()
+	defer tokenMu.RUnlock()
 	return gTokenInfo.AccessToken
 }
 
@@ -100,28 +143,46 @@ func GetServer() string {
 	return gTokenInfo.Server
 }
 
-func GetCACertFile() string {
-	return gTokenInfo.CACertFile
+// nodes returns the vault cluster nodes, the server logged into first
+func (info tokenInfo) nodes() []string {
+	if len(info.Servers) == 0 {
+		return []string{info.Server}
+	}
+	return info.Servers
 }
 
-func JsonStrToMap(jsonStr string) map[string]interface{} {
-	var jsonMap map[string]interface{}
-	err := json.Unmarshal([]byte(jsonStr), &jsonMap)
-	if err != nil {
-		fmt.Println("Error parsing json string: ", err)
-		os.Exit(1)
-	}
-	return jsonMap
+func GetNodes() []string {
+	return gTokenInfo.nodes()
 }
 
-func JsonArrayStrToMap(jsonArrayStr string) []map[string]interface{} {
-	var jsonArray []map[string]interface{}
-	err := json.Unmarshal([]byte(jsonArrayStr), &jsonArray)
+// InitClient builds the Client shared by all commands run in this
+// process
+func InitClient(config ClientConfig) error {
+	if config.CACertFile == "" {
+		fmt.Println("\n###############################################################################\n" +
+			"Insecure request. Entrust Vault certificate not verified. \n" +
+			"It is strongly recommended to verify the same by specifying CA \n" +
+			"Certificate, using the --cacert option, to mitigate Man-in-the-middle attack\n" +
+			"###############################################################################")
+	}
+
+	client, err := NewClient(config)
 	if err != nil {
-		fmt.Println("Error parsing json array string: ", err)
-		os.Exit(1)
+		return err
 	}
-	return jsonArray
+	gClient = client
+	return nil
+}
+
+// GetClient returns the Client built by InitClient
+func GetClient() *Client {
+	return gClient
+}
+
+func JsonStrToMap(jsonStr string) (map[string]interface{}, error) {
+	var jsonMap map[string]interface{}
+	err := json.Unmarshal([]byte(jsonStr), &jsonMap)
+	return jsonMap, err
 }
 
 func IsJSON(str string) bool {
@@ -160,70 +221,12 @@ func JSONMarshalIndent(t interface{}) ([]byte, error) {
     return buffer.Bytes(), err
 }
 
-func B64File(File string) (string, error) {
-	file, err := os.Open(File)
-	if err != nil {
-		return "", fmt.Errorf("Error opening file %s - %v",
-			File, err)
-	}
-	defer file.Close()
-
-	reader := bufio.NewReader(file)
-	content, _ := io.ReadAll(reader)
-
-	encoded := base64.StdEncoding.EncodeToString(content)
-	if encoded == "" {
-		return "", fmt.Errorf("Error base64 encoding file - %s", File)
-	}
-	return encoded, nil
-}
-
 func B64Encode(data string) string {
 	return base64.StdEncoding.EncodeToString([]byte(data))
 }
 
-func B64Decode(b64Str string) string {
-	dec, _ := base64.StdEncoding.DecodeString(b64Str)
-	return string(dec)
-}
-
-func AESGCMDecrypt(b64CipherText string, b64Key string, b64Nonce string,
-	b64Tag string, authData string) string {
-	key := []byte(B64Decode(b64Key))
-	ciphertext := []byte(B64Decode(b64CipherText))
-	nonce := []byte(B64Decode(b64Nonce))
-	tag := []byte(B64Decode(b64Tag))
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		panic(err.Error())
-	}
-
-	aesgcm, err := cipher.NewGCM(block)
-	if err != nil {
-		panic(err.Error())
-	}
-
-// crypto library expects these together & separates it out by itself
-	ciphertextTag := append(ciphertext, tag...)
-	plaintext, err := aesgcm.Open(nil, nonce, ciphertextTag, []byte(authData))
-	if err != nil {
-		panic(err.Error())
-	}
-	return string(plaintext)
-}
-
-func KeyExists(kvMap map[string]interface{}, key string) bool {
-	val, ok := kvMap[key]
-	return ok && val != nil
-}
-
 func convertUTCtoLocal() *time.Location {
-	location, err := time.LoadLocation("Local")
-	if err != nil {
-		os.Exit(10) // unexpected
-	}
-	return location
+	return time.Local
 }
 
 func convertTimeFrom24HourTo12HourFormat(hour int) (int, string) {
@@ -239,13 +242,16 @@ func convertTimeFrom24HourTo12HourFormat(hour int) (int, string) {
 	return hour, ampm
 }
 
-func formatLoginExpiration(expiresAt string) string {
+func formatLoginExpiration(expiresAt string) (string, error) {
 	expiration, err := time.Parse(time.RFC3339, expiresAt)
 	if err != nil {
-		fmt.Printf("\nInvalid Expiration %q - %v\n", expiresAt, err)
-		os.Exit(5)
+		return "", InvalidResponseError(fmt.Sprintf("\nInvalid Expiration %q - %v\n", expiresAt, err))
 	}
+	return formatExpiration(expiration), nil
+}
 
+// formatExpiration formats an expiration time in the local time zone
+func formatExpiration(expiration time.Time) string {
 	expiration = expiration.In(convertUTCtoLocal())
 
 	hour, min, sec := expiration.Clock()
@@ -0,0 +1,121 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"cli/pkg/vault"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// vaultDoer sends the requests of the vault SDK through a Client, so
+// that they are retried and fail over like any other request
+type vaultDoer struct {
+	client *Client
+}
+
+func (d vaultDoer) Do(request *http.Request) (*http.Response, error) {
+	return d.client.doWithRetry(request)
+}
+
+// NewVault returns an SDK client of server authenticating with token
+func NewVault(server, token string) *vault.Client {
+	return vault.NewClient(serverHost(server), token, vaultDoer{GetClient()})
+}
+
+// GetVault returns the SDK client of the vault logged into
+func GetVault() *vault.Client {
+	return NewVault(GetServer(), GetAccessToken())
+}
+
+// vaultAPIError returns the error response err as APIError, with the
+// JSON error indented as the commands print it
+func vaultAPIError(err *vault.Error) *APIError {
+	body := err.Body
+	dst := &bytes.Buffer{}
+	if json.Indent(dst, body, "", "  ") == nil {
+		body = dst.Bytes()
+	}
+	return &APIError{
+		RequestURL:     err.URL,
+		HttpStatusCode: err.StatusCode,
+		HttpStatus:     err.Status,
+		ErrorJSON:      body}
+}
+
+// VaultError is the error of an SDK request that failed with err. An
+// empty 404 response is reported as notFound, or as denied action if
+// notFound is empty.
+func VaultError(err error, notFound string) error {
+	var vaultError *vault.Error
+	if !errors.As(err, &vaultError) {
+		return RequestFailedError(err)
+	}
+	apiError := vaultAPIError(vaultError)
+	if vaultError.Empty() && vaultError.StatusCode == http.StatusNotFound {
+		if notFound == "" {
+			return commandFailed(ExitPermissionDenied, "\nAction denied\n\n", apiError)
+		}
+		return commandFailed(ExitNotFound, "\n"+notFound+"\n\n", apiError)
+	}
+	return ErrorResponseError(*apiError)
+}
+
+// PrintVaultResult prints the JSON response of an SDK request, as
+// PrintResult does
+func PrintVaultResult(data []byte) error {
+	dst := &bytes.Buffer{}
+	if err := json.Indent(dst, data, "", "  "); err != nil {
+		return InvalidResponseError("\nEmpty response\n")
+	}
+	return PrintResult(dst.String())
+}
+
+// listOptions returns the options of a list command, given by its
+// --prefix, --filters, --max-items and --next-token options and the
+// repeatable fieldsOption
+func listOptions(flags *pflag.FlagSet, fieldsOption string) vault.ListOptions {
+	options := vault.ListOptions{}
+	options.Prefix, _ = flags.GetString("prefix")
+	options.Filters, _ = flags.GetString("filters")
+	options.MaxItems, _ = flags.GetInt("max-items")
+	options.Fields, _ = flags.GetStringArray(fieldsOption)
+	options.NextToken, _ = flags.GetString("next-token")
+	return options
+}
+
+// SaveDownload saves a file downloaded from the vault in the current
+// directory and returns its name
+func SaveDownload(download *vault.Download) (string, error) {
+	defer download.Body.Close()
+	outFile, err := os.Create(download.Name)
+	if err != nil {
+		return "", err
+	}
+	defer outFile.Close()
+
+	if _, err = io.Copy(outFile, download.Body); err != nil {
+		return "", err
+	}
+	return download.Name, nil
+}
@@ -17,60 +17,27 @@ limitations under the License.
 package cmd
 
 import (
-	"os"
-	"fmt"
-	"bytes"
-	"encoding/json"
 	"github.com/spf13/cobra"
 )
 
 var RotateKeyCmd = &cobra.Command{
-	Use:	"rotate-key",
-	Short:	"Rotate Key",
-	Run: func(cmd *cobra.Command, args []string) {
-	    flags := cmd.Flags()
-	    params := map[string]interface{}{}
-
-	    key_guid, _ := flags.GetString("key_guid")
-
-	    jsonParams, err := json.Marshal(params)
-	    if (err != nil) {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
-	    }
-
-	    endpoint := GetEndPoint("", "1.0", "key/"+key_guid+"/rotate")
-	    ret, err := DoPost(endpoint,
-	                        	GetCACertFile(),
-								AuthTokenKV(),
-								jsonParams,
-								"application/json")
-	   if err != nil {
-	    	fmt.Printf("\nHTTP request failed: %s\n", err)
-	    	os.Exit(4)
-	   }
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if (retStr == "" && retStatus == 404) {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
+	Use:   "rotate-key",
+	Short: "Rotate Key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		key_guid, _ := flags.GetString("key_guid")
+
+		response, err := GetVault().RotateKey(cmd.Context(), key_guid)
+		if err != nil {
+			return VaultError(err, "")
 		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
 func init() {
-    rootCmd.AddCommand(RotateKeyCmd)
-    RotateKeyCmd.Flags().StringP("key_guid", "k", "", "Key GUID")
+	rootCmd.AddCommand(RotateKeyCmd)
+	RotateKeyCmd.Flags().StringP("key_guid", "k", "", "Key GUID")
 
-    RotateKeyCmd.MarkFlagRequired("key_guid")
+	RotateKeyCmd.MarkFlagRequired("key_guid")
 }
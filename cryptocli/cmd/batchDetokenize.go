@@ -17,70 +17,55 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
+	"context"
+
 	"github.com/spf13/cobra"
 )
 
+// batchDetokenizeRecords runs the records of --input through BatchDetokenize
+var batchDetokenizeRecords = batchCommand{
+	fields:   []string{"policyName", "tokenData", "keyGuid"},
+	required: []string{"policyName", "tokenData"},
+	results:  []string{"tokenData"},
+	send: func(ctx context.Context, records []map[string]string) ([]vault.BatchResult, error) {
+		return batchResults(GetVault().BatchDetokenize(ctx, tokenizeRequests(records)))
+	},
+}
+
 var batchDetokenizeCmd = &cobra.Command{
 	Use:   "batch-detokenize",
 	Short: "Batch Detokenize. Please provide policyName, keyGuid and tokenData alternatively.",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
+		if input, _ := flags.GetString(batchOptionInput); input != "" {
+			return batchDetokenizeRecords.run(cmd)
+		}
 
 		policyName, _ := flags.GetStringArray("policyName")
 		tokenData, _ := flags.GetStringArray("tokenData")
 		keyGuid, _ := flags.GetStringArray("keyGuid")
 
 		if len(policyName) != len(tokenData) || len(policyName) != len(keyGuid) {
-			fmt.Println("Missing parameters. Please check and try again")
-			os.Exit(1)
+			return Errorf(ExitUsage, "Missing parameters. Please check and try again\n")
 		}
 
-		request := []interface{}{}
-
+		records := []vault.TokenizeRequest{}
 		for i := 0; i < len(policyName); i++ {
-			params := map[string]interface{}{}
-			params["policyName"] = policyName[i]
-			params["tokenData"] = tokenData[i]
+			record := vault.TokenizeRequest{PolicyName: policyName[i],
+				TokenData: tokenData[i]}
+			// 0 stands for the latest version of the key
 			if keyGuid[i] != "0" {
-				params["keyGuid"] = keyGuid[i]
+				record.KeyGUID = keyGuid[i]
 			}
-			request = append(request, params)
+			records = append(records, record)
 		}
 
-		jsonParams, err := json.Marshal(request)
+		response, err := GetVault().BatchDetokenize(Idempotent(cmd.Context()), records)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "batch/detoken")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonArrayStrToMap(retStr)
-			jsonData, _ := JSONMarshalIndent(retMap)
-			fmt.Println("\n" + string(jsonData) + "\n")
-			os.Exit(0)
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -93,7 +78,5 @@ func init() {
 	batchDetokenizeCmd.Flags().StringArrayP("keyGuid", "k", []string{},
 		"Enter keyGuid if you want to detokenize data using specific version of the key else provide 0.")
 
-	batchDetokenizeCmd.MarkFlagRequired("policyName")
-	batchDetokenizeCmd.MarkFlagRequired("tokenData")
-	batchDetokenizeCmd.MarkFlagRequired("keyGuid")
+	batchDetokenizeRecords.addFlags(batchDetokenizeCmd, "tokenData")
 }
@@ -17,18 +17,30 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
+	"context"
+
 	"github.com/spf13/cobra"
 )
 
+// batchEncryptRecords runs the records of --input through BatchEncrypt
+var batchEncryptRecords = batchCommand{
+	fields:   []string{"keyGuid", "data", "mode", "iv", "aad"},
+	required: []string{"keyGuid", "data", "mode"},
+	results:  []string{"data", "iv", "tag"},
+	send: func(ctx context.Context, records []map[string]string) ([]vault.BatchResult, error) {
+		return batchResults(GetVault().BatchEncrypt(ctx, encryptRequests(records)))
+	},
+}
+
 var batchEncryptCmd = &cobra.Command{
 	Use:   "batch-encrypt",
 	Short: "Batch Encrypt. Please provide keyGuid, data, mode, iv and aad alternatively.",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
+		if input, _ := flags.GetString(batchOptionInput); input != "" {
+			return batchEncryptRecords.run(cmd)
+		}
 
 		data, _ := flags.GetStringArray("data")
 		keyGuid, _ := flags.GetStringArray("keyGuid")
@@ -37,57 +49,28 @@ var batchEncryptCmd = &cobra.Command{
 		aad, _ := flags.GetStringArray("aad")
 
 		if len(mode) != len(data) || len(mode) != len(keyGuid) || len(mode) != len(iv) || len(mode) != len(aad) {
-			fmt.Println("Missing parameters. Please check and try again")
-			os.Exit(1)
+			return Errorf(ExitUsage, "Missing parameters. Please check and try again\n")
 		}
 
-		request := []interface{}{}
-
+		records := []vault.BatchEncryptRequest{}
 		for i := 0; i < len(mode); i++ {
-			params := map[string]interface{}{}
-			params["keyGuid"] = keyGuid[i]
-			params["data"] = data[i]
-			params["mode"] = mode[i]
-			
+			record := vault.BatchEncryptRequest{KeyGUID: keyGuid[i],
+				Data: data[i], Mode: mode[i]}
+			// 0 stands for an absent IV or AAD
 			if iv[i] != "0" {
-				params["iv"] = iv[i]
+				record.IV = iv[i]
 			}
 			if aad[i] != "0" {
-				params["aad"] = aad[i]
+				record.AAD = aad[i]
 			}
-			request = append(request, params)
+			records = append(records, record)
 		}
 
-		jsonParams, err := json.Marshal(request)
+		response, err := GetVault().BatchEncrypt(Idempotent(cmd.Context()), records)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "batch/encrypt")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-			
-			retMap := JsonArrayStrToMap(retStr)
-			jsonData, _ := JSONMarshalIndent(retMap)
-			fmt.Println("\n" + string(jsonData) + "\n")
-			os.Exit(0)
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -99,9 +82,5 @@ func init() {
 	batchEncryptCmd.Flags().StringArrayP("iv", "i", []string{}, "Enter initialization vector if required else provide 0")
 	batchEncryptCmd.Flags().StringArrayP("aad", "a", []string{}, "Enter Additional authentication data if required else provide 0")
 
-	batchEncryptCmd.MarkFlagRequired("data")
-	batchEncryptCmd.MarkFlagRequired("keyGuid")
-	batchEncryptCmd.MarkFlagRequired("mode")
-	batchEncryptCmd.MarkFlagRequired("iv")
-	batchEncryptCmd.MarkFlagRequired("aad")
+	batchEncryptRecords.addFlags(batchEncryptCmd, "data")
 }
@@ -17,18 +17,30 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
+	"context"
+
 	"github.com/spf13/cobra"
 )
 
+// batchEncryptDecryptRecords runs the records of --input through BatchEncryptDecrypt
+var batchEncryptDecryptRecords = batchCommand{
+	fields:   []string{"keyGuid", "data", "mode", "iv", "aad", "operation"},
+	required: []string{"keyGuid", "data", "mode", "operation"},
+	results:  []string{"data", "iv", "tag"},
+	send: func(ctx context.Context, records []map[string]string) ([]vault.BatchResult, error) {
+		return batchResults(GetVault().BatchEncryptDecrypt(ctx, encryptRequests(records)))
+	},
+}
+
 var batchEncryptDecryptCmd = &cobra.Command{
 	Use:   "batch-encrypt-decrypt",
 	Short: "Batch Encrypt/Decrypt. Please provide keyGuid, data, mode, iv, aad and operation alternatively.",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
+		if input, _ := flags.GetString(batchOptionInput); input != "" {
+			return batchEncryptDecryptRecords.run(cmd)
+		}
 
 		data, _ := flags.GetStringArray("data")
 		keyGuid, _ := flags.GetStringArray("keyGuid")
@@ -38,58 +50,29 @@ var batchEncryptDecryptCmd = &cobra.Command{
 		operation, _ := flags.GetStringArray("operation")
 
 		if len(mode) != len(data) || len(mode) != len(keyGuid) || len(mode) != len(iv) || len(mode) != len(aad) || len(mode) != len(operation) {
-			fmt.Println("Missing parameters. Please check and try again")
-			os.Exit(1)
+			return Errorf(ExitUsage, "Missing parameters. Please check and try again\n")
 		}
 
-		request := []interface{}{}
-
+		records := []vault.BatchEncryptRequest{}
 		for i := 0; i < len(mode); i++ {
-			params := map[string]interface{}{}
-			params["keyGuid"] = keyGuid[i]
-			params["data"] = data[i]
-			params["operation"] = operation[i]
-			params["mode"] = mode[i]
-			
+			record := vault.BatchEncryptRequest{KeyGUID: keyGuid[i],
+				Data: data[i], Mode: mode[i],
+				Operation: operation[i]}
+			// 0 stands for an absent IV or AAD
 			if iv[i] != "0" {
-				params["iv"] = iv[i]
+				record.IV = iv[i]
 			}
 			if aad[i] != "0" {
-				params["aad"] = aad[i]
+				record.AAD = aad[i]
 			}
-			request = append(request, params)
+			records = append(records, record)
 		}
 
-		jsonParams, err := json.Marshal(request)
+		response, err := GetVault().BatchEncryptDecrypt(Idempotent(cmd.Context()), records)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "batch/encrypt-decrypt")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-			
-			retMap := JsonArrayStrToMap(retStr)
-			jsonData, _ := JSONMarshalIndent(retMap)
-			fmt.Println("\n" + string(jsonData) + "\n")
-			os.Exit(0)
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -102,10 +85,5 @@ func init() {
 	batchEncryptDecryptCmd.Flags().StringArrayP("aad", "a", []string{}, "Enter Additional authentication data if required else provide 0")
 	batchEncryptDecryptCmd.Flags().StringArrayP("operation", "o", []string{}, "Operation type: Encrypt/Decrypt")
 
-	batchEncryptDecryptCmd.MarkFlagRequired("data")
-	batchEncryptDecryptCmd.MarkFlagRequired("keyGuid")
-	batchEncryptDecryptCmd.MarkFlagRequired("mode")
-	batchEncryptDecryptCmd.MarkFlagRequired("iv")
-	batchEncryptDecryptCmd.MarkFlagRequired("aad")
-	batchEncryptDecryptCmd.MarkFlagRequired("operation")
+	batchEncryptDecryptRecords.addFlags(batchEncryptDecryptCmd, "data")
 }
@@ -0,0 +1,569 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const (
+	// batchCheckpointInterval is the least time between two saves of
+	// the checkpoint
+	batchCheckpointInterval = time.Second
+	// batchProgressInterval is the least time between two progress
+	// lines
+	batchProgressInterval = 500 * time.Millisecond
+)
+
+// batchCheckpoint is the state of a run writing to a file, saved as
+// JSON while it runs so that an interrupted run can be resumed. The
+// first Records records of the input are written to the first
+// OutputSize bytes of the partial output file.
+type batchCheckpoint struct {
+	Command    string            `json:"command"`
+	Input      string            `json:"input"`
+	Format     string            `json:"format"`
+	Defaults   map[string]string `json:"defaults,omitempty"`
+	Options    map[string]string `json:"options,omitempty"`
+	Records    int               `json:"records"`
+	Failed     int               `json:"failed"`
+	OutputSize int64             `json:"output_size"`
+}
+
+// loadBatchCheckpoint reads the checkpoint file name
+func loadBatchCheckpoint(name string) (*batchCheckpoint, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &batchCheckpoint{}
+	if err = json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("%s is not a checkpoint - %v", name, err)
+	}
+	return checkpoint, nil
+}
+
+// sameRun reports whether c is the checkpoint of a run of the same
+// command with the same input and options as other
+func (c *batchCheckpoint) sameRun(other *batchCheckpoint) bool {
+	return c.Command == other.Command && c.Input == other.Input &&
+		c.Format == other.Format && sameValues(c.Defaults, other.Defaults) &&
+		sameValues(c.Options, other.Options)
+}
+
+// sameValues reports whether a and b hold the same values
+func sameValues(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+// save writes the checkpoint file name, replacing it at once so that a
+// crash leaves the previous checkpoint
+func (c *batchCheckpoint) save(name string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(data, '\n')); err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), name)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// batchProgress prints the progress of a run to stderr, over and over
+// on the same line
+type batchProgress struct {
+	enabled bool
+	input   *countingReader
+	// size is the size of the input, or 0 if unknown
+	size  int64
+	start time.Time
+	// startRecords and startBytes are where the run started, after the
+	// records of a checkpoint
+	startRecords int
+	startBytes   int64
+	last         time.Time
+}
+
+// begin starts measuring at records records into the input
+func (p *batchProgress) begin(records int) {
+	p.start = time.Now()
+	p.last = p.start
+	p.startRecords = records
+	p.startBytes = p.input.n.Load()
+}
+
+// update prints the progress after records records of which failed
+// failed, at most every batchProgressInterval unless final
+func (p *batchProgress) update(records, failed int, final bool) {
+	if !p.enabled {
+		return
+	}
+	now := time.Now()
+	if !final && now.Sub(p.last) < batchProgressInterval {
+		return
+	}
+	p.last = now
+
+	elapsed := now.Sub(p.start).Seconds()
+	line := fmt.Sprintf("%d records, %d failed", records, failed)
+	if elapsed > 0 {
+		line += fmt.Sprintf(", %.0f records/s", float64(records-p.startRecords)/elapsed)
+	}
+	read := p.input.n.Load()
+	if p.size > 0 && !final {
+		line += fmt.Sprintf(", %d%%", read*100/p.size)
+		if done := read - p.startBytes; done > 0 && read < p.size {
+			remaining := time.Duration(elapsed * float64(p.size-read) / float64(done) *
+				float64(time.Second))
+			line += fmt.Sprintf(", ETA %s", remaining.Round(time.Second))
+		}
+	}
+	// clear what is left of a longer previous line
+	fmt.Fprintf(os.Stderr, "\r%-72s", line)
+	if final {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// batchSource reads the records of a run, returning io.EOF after the
+// last one
+type batchSource interface {
+	Read() (*batchRecord, error)
+}
+
+// batchSink writes the records of a run
+type batchSink interface {
+	WriteHeader() error
+	Write(record *batchRecord) error
+	Flush() error
+}
+
+// batchChunk is the records sent in one request, numbered in the order
+// of the input
+type batchChunk struct {
+	seq     int
+	records []*batchRecord
+	failed  int
+	err     error
+}
+
+// batchRun runs process over the records of reader. Chunks of
+// batchSize records are processed by up to concurrency requests at a
+// time and written in the order of the input.
+type batchRun struct {
+	process     func(ctx context.Context, records []*batchRecord) (int, error)
+	reader      batchSource
+	writer      batchSink
+	batchSize   int
+	concurrency int
+	progress    *batchProgress
+
+	// state counts the records written. With a checkpoint file, output
+	// is the partial output file and state is saved to checkpointName.
+	state          *batchCheckpoint
+	checkpointName string
+	output         *os.File
+	saved          time.Time
+}
+
+// readChunk reads up to batchSize records. It returns io.EOF with the
+// last records.
+func (r *batchRun) readChunk() ([]*batchRecord, error) {
+	records := []*batchRecord{}
+	for len(records) < r.batchSize {
+		record, err := r.reader.Read()
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// write writes the records of chunk and saves the checkpoint if it is
+// due
+func (r *batchRun) write(chunk *batchChunk) error {
+	for _, record := range chunk.records {
+		if err := r.writer.Write(record); err != nil {
+			return Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+		}
+	}
+	r.state.Records += len(chunk.records)
+	r.state.Failed += chunk.failed
+	r.progress.update(r.state.Records, r.state.Failed, false)
+	if r.checkpointName != "" && time.Since(r.saved) >= batchCheckpointInterval {
+		return r.saveCheckpoint()
+	}
+	return nil
+}
+
+// saveCheckpoint flushes the output and saves the checkpoint
+func (r *batchRun) saveCheckpoint() error {
+	err := r.writer.Flush()
+	if err == nil {
+		r.state.OutputSize, err = r.output.Seek(0, io.SeekCurrent)
+	}
+	if err != nil {
+		return Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+	}
+	if err = r.state.save(r.checkpointName); err != nil {
+		return Errorf(ExitError, "\nError writing checkpoint - %v\n\n", err)
+	}
+	r.saved = time.Now()
+	return nil
+}
+
+// run sends all records and writes them with their results. When a
+// request fails, or ctx is done, no more chunks are sent and run
+// returns the error once the chunks before the failed one are written
+// and checkpointed.
+func (r *batchRun) run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.saved = time.Now()
+	r.progress.begin(r.state.Records)
+
+	chunks := make(chan *batchChunk)
+	results := make(chan *batchChunk)
+	// slots bounds the chunks read but not yet written, as chunks
+	// after a slow one wait for it
+	slots := make(chan struct{}, 2*r.concurrency)
+	var readError error
+	go func() {
+		defer close(chunks)
+		for seq := 0; ; seq++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			records, err := r.readChunk()
+			if len(records) > 0 {
+				chunks <- &batchChunk{seq: seq, records: records}
+			}
+			if err != nil {
+				if err != io.EOF {
+					readError = Errorf(ExitError, "\nError reading input file - %v\n\n", err)
+				}
+				return
+			}
+		}
+	}()
+
+	workers := &sync.WaitGroup{}
+	for i := 0; i < r.concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for chunk := range chunks {
+				// a long run outlives the session unless it is renewed
+				RenewSessionIfDue(ctx)
+				chunk.failed, chunk.err = r.process(ctx, chunk.records)
+				results <- chunk
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// chunks wait in pending until those before them are written. A
+	// failed write stops writing, leaving the last checkpoint as it is.
+	pending := map[int]*batchChunk{}
+	next := 0
+	writing := true
+	var runError error
+	for chunk := range results {
+		if chunk.err != nil && runError == nil {
+			runError = chunk.err
+			cancel()
+		}
+		pending[chunk.seq] = chunk
+		for writing {
+			chunk = pending[next]
+			if chunk == nil || chunk.err != nil {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-slots
+			if err := r.write(chunk); err != nil {
+				writing = false
+				runError = err
+				cancel()
+			}
+		}
+	}
+	if runError == nil {
+		runError = readError
+	}
+	if !writing {
+		return runError
+	}
+	if r.checkpointName != "" {
+		if err := r.saveCheckpoint(); err != nil && runError == nil {
+			runError = err
+		}
+	} else if err := r.writer.Flush(); err != nil && runError == nil {
+		runError = Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+	}
+	r.progress.update(r.state.Records, r.state.Failed, true)
+	return runError
+}
+
+// addBatchEngineFlags adds the options of the batch engine to cmd,
+// which writes to the option outOption. Requests send --batch-size
+// units.
+func addBatchEngineFlags(cmd *cobra.Command, outOption, units string) {
+	cmd.Flags().Int(batchOptionBatchSize, defaultBatchSize,
+		"Number of "+units+" sent in one request")
+	cmd.Flags().Int(batchOptionConcurrency, defaultBatchConcurrency,
+		"Number of requests sent at a time")
+	cmd.Flags().Bool(batchOptionProgress, false,
+		"Show the progress on stderr. Defaults to true if stderr is a terminal.")
+	cmd.Flags().String(batchOptionCheckpoint, "",
+		"Checkpoint file of --"+outOption+". Defaults to the output file "+
+			"with "+batchCheckpointSuffix+" appended.")
+	cmd.Flags().Bool(batchOptionResume, false,
+		"Continue the interrupted run the checkpoint file records")
+}
+
+// batchFile is a run of the batch engine over a record file
+type batchFile struct {
+	inName  string
+	outName string // stdStream for stdout
+	// outOption is the option giving outName
+	outOption string
+	// valuesPerRecord is the number of values each record sends,
+	// which --batch-size counts
+	valuesPerRecord int
+	// state identifies the run in its checkpoint and counts the
+	// records done
+	state *batchCheckpoint
+	// newReader reads the input from r, and newWriter then writes the
+	// output to w
+	newReader func(r io.Reader) (batchSource, error)
+	newWriter func(w io.Writer) batchSink
+	process   func(ctx context.Context, records []*batchRecord) (int, error)
+}
+
+// openPartialOutput creates the partial output file name or, to
+// resume, opens it to write after its first size bytes
+func openPartialOutput(name string, size int64, resume bool) (*os.File, error) {
+	if !resume {
+		return os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err == nil && info.Size() < size {
+		err = fmt.Errorf("%s is shorter than its checkpoint says", name)
+	}
+	if err == nil {
+		err = file.Truncate(size)
+	}
+	if err == nil {
+		_, err = file.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// run processes all records of the input, resuming the run of the
+// checkpoint with --resume, and prints a summary to stderr. Once run
+// returned nil, the output file is in place and state counts the
+// records.
+func (f *batchFile) run(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	batchSize, _ := flags.GetInt(batchOptionBatchSize)
+	concurrency, _ := flags.GetInt(batchOptionConcurrency)
+	checkpointName, _ := flags.GetString(batchOptionCheckpoint)
+	resume, _ := flags.GetBool(batchOptionResume)
+
+	if batchSize < 1 || concurrency < 1 {
+		return UsageError("\n--%s and --%s must be at least 1\n\n", batchOptionBatchSize,
+			batchOptionConcurrency)
+	}
+	if f.outName == stdStream && (resume || checkpointName != "") {
+		return UsageError("\n--%s and --%s need --%s\n\n", batchOptionCheckpoint,
+			batchOptionResume, f.outOption)
+	}
+	if f.valuesPerRecord > 1 {
+		batchSize /= f.valuesPerRecord
+		if batchSize < 1 {
+			batchSize = 1
+		}
+	}
+
+	if f.inName != stdStream {
+		if abs, err := filepath.Abs(f.inName); err == nil {
+			f.state.Input = abs
+		}
+	}
+	if f.outName != stdStream {
+		if checkpointName == "" {
+			checkpointName = f.outName + batchCheckpointSuffix
+		}
+		saved, err := loadBatchCheckpoint(checkpointName)
+		switch {
+		case os.IsNotExist(err):
+			if resume {
+				return UsageError("\nThere is no checkpoint %s to resume from\n\n",
+					checkpointName)
+			}
+		case err != nil:
+			return Errorf(ExitError, "\nError reading checkpoint - %v\n\n", err)
+		case !resume:
+			return UsageError("\n%s is the checkpoint of an interrupted run. Continue it "+
+				"with --%s, or delete it to start over.\n\n", checkpointName, batchOptionResume)
+		case !saved.sameRun(f.state):
+			return UsageError("\n%s is the checkpoint of a run with another command, "+
+				"input or options\n\n", checkpointName)
+		default:
+			f.state = saved
+		}
+	}
+
+	in, err := openInput(f.inName)
+	if err != nil {
+		return Errorf(ExitError, "\nError opening input file - %v\n\n", err)
+	}
+	defer in.Close()
+	progress := &batchProgress{input: &countingReader{r: in}}
+	progress.enabled, _ = flags.GetBool(batchOptionProgress)
+	if !flags.Changed(batchOptionProgress) {
+		progress.enabled = term.IsTerminal(int(os.Stderr.Fd()))
+	}
+	if file, ok := in.(*os.File); ok {
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			progress.size = info.Size()
+		}
+	}
+	reader, err := f.newReader(progress.input)
+	if err != nil {
+		return Errorf(ExitError, "\nError reading input file - %v\n\n", err)
+	}
+	for i := 0; i < f.state.Records; i++ {
+		if _, err = reader.Read(); err == io.EOF {
+			return Errorf(ExitError, "\nThe input ends before the %d records of the "+
+				"checkpoint %s\n\n", f.state.Records, checkpointName)
+		}
+		if err != nil {
+			return Errorf(ExitError, "\nError reading input file - %v\n\n", err)
+		}
+	}
+
+	run := &batchRun{process: f.process, reader: reader, batchSize: batchSize,
+		concurrency: concurrency, progress: progress, state: f.state, output: os.Stdout}
+	partialName := f.outName + batchPartialSuffix
+	if f.outName != stdStream {
+		run.checkpointName = checkpointName
+		run.output, err = openPartialOutput(partialName, f.state.OutputSize, resume)
+		if err != nil {
+			return Errorf(ExitError, "\nError creating output file - %v\n\n", err)
+		}
+		defer run.output.Close()
+	}
+	run.writer = f.newWriter(run.output)
+	if !resume {
+		if err = run.writer.WriteHeader(); err != nil {
+			return Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+		}
+	}
+
+	err = run.run(Idempotent(cmd.Context()))
+	fmt.Fprintf(os.Stderr, "\n%d records processed: %d succeeded, %d failed\n",
+		f.state.Records, f.state.Records-f.state.Failed, f.state.Failed)
+	if err != nil {
+		if run.checkpointName == "" {
+			return err
+		}
+		if cmd.Context().Err() != nil {
+			err = Errorf(ExitError, "\nInterrupted\n\n")
+		}
+		var cmdError *CommandError
+		if errors.As(err, &cmdError) {
+			cmdError.Message = strings.TrimRight(cmdError.Message, "\n") + fmt.Sprintf(
+				"\n\nThe run is checkpointed in %s. Run the command again with --%s "+
+					"to continue.\n\n", checkpointName, batchOptionResume)
+		}
+		return err
+	}
+
+	if f.outName == stdStream {
+		return nil
+	}
+	if err = run.output.Close(); err == nil {
+		err = os.Rename(partialName, f.outName)
+	}
+	if err != nil {
+		return Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+	}
+	os.Remove(checkpointName)
+	return nil
+}
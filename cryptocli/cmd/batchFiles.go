@@ -0,0 +1,275 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	batchOptionInput       = "input"
+	batchOptionOutputFile  = "output-file"
+	batchOptionFormat      = "format"
+	batchOptionBatchSize   = "batch-size"
+	batchOptionConcurrency = "concurrency"
+	batchOptionProgress    = "progress"
+	batchOptionCheckpoint  = "checkpoint"
+	batchOptionResume      = "resume"
+
+	// batchErrorField holds why a record failed
+	batchErrorField = "error"
+	// defaultBatchSize is the number of records sent in one request
+	defaultBatchSize = 1000
+	// defaultBatchConcurrency is the number of requests sent at a time
+	defaultBatchConcurrency = 4
+	// batchPartialSuffix names the output file until the run is done
+	batchPartialSuffix = ".partial"
+	// batchCheckpointSuffix names the checkpoint of an output file
+	batchCheckpointSuffix = ".checkpoint"
+)
+
+// batchCommand runs the records of a CSV or JSONL file through a batch
+// endpoint of the vault. The fields of a record are named as the
+// options of the command; an option given once sets the fields records
+// leave empty.
+type batchCommand struct {
+	fields   []string
+	required []string
+	// results are the fields of vault.BatchResult the results set
+	results []string
+	// send sends records, given by their fields, and returns their
+	// results in order
+	send func(ctx context.Context, records []map[string]string) ([]vault.BatchResult, error)
+}
+
+// addFlags adds the options of record files to cmd, whose dataOption
+// gives the data of records on the command line
+func (b batchCommand) addFlags(cmd *cobra.Command, dataOption string) {
+	cmd.Long = fmt.Sprintf(`%s
+
+Records can also be read from a CSV file with a header line or from a
+JSONL file with a JSON object per line, given by --%s. Their fields
+are %s; other fields are kept as they are. The records are written to
+--%s, or stdout, in the format and order of the input with the
+%s fields of their results, and an %s field if they failed.
+
+Records are sent in requests of --%s records, --%s at a time. The
+output file is written as <file>%s and renamed once all records are
+done. Its checkpoint, <file>%s unless --%s says otherwise, records
+how far the run got; after an interrupted run or a failed request,
+the same command with --%s continues where the run stopped.`,
+		cmd.Short, batchOptionInput, strings.Join(b.fields, ", "), batchOptionOutputFile,
+		strings.Join(b.results, ", "), batchErrorField, batchOptionBatchSize,
+		batchOptionConcurrency, batchPartialSuffix, batchCheckpointSuffix,
+		batchOptionCheckpoint, batchOptionResume)
+
+	cmd.Flags().String(batchOptionInput, "",
+		"CSV or JSONL file of records to process, or - for stdin")
+	cmd.Flags().String(batchOptionOutputFile, "",
+		"File to write the processed records to. Defaults to stdout.")
+	cmd.Flags().String(batchOptionFormat, "",
+		"Format of the record files: csv or jsonl. Defaults to the format the "+
+			"extension of the input file says.")
+	addBatchEngineFlags(cmd, batchOptionOutputFile, "records of --"+batchOptionInput)
+	cmd.MarkFlagsOneRequired(dataOption, batchOptionInput)
+	cmd.MarkFlagsMutuallyExclusive(dataOption, batchOptionInput)
+}
+
+// batchErrorText returns the error of a record as text
+func batchErrorText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	return string(raw)
+}
+
+// batchResultField returns the field name of result
+func batchResultField(result vault.BatchResult, name string) string {
+	switch name {
+	case "data":
+		return result.Data
+	case "iv":
+		return result.IV
+	case "tag":
+		return result.Tag
+	case "tokenData":
+		return result.TokenData
+	}
+	return ""
+}
+
+// process sends the records which have the required fields and sets
+// their results. It returns the number of failed records.
+func (b batchCommand) process(ctx context.Context, records []*batchRecord,
+	defaults map[string]string) (int, error) {
+	failed := 0
+	sent := []*batchRecord{}
+	fields := []map[string]string{}
+	for _, record := range records {
+		values := map[string]string{}
+		var recordError error
+		for _, name := range b.fields {
+			value, err := record.get(name)
+			if err != nil {
+				recordError = err
+				break
+			}
+			if value == "" {
+				value = defaults[name]
+			}
+			values[name] = value
+		}
+		for _, name := range b.required {
+			if recordError == nil && values[name] == "" {
+				recordError = fmt.Errorf("%s is missing", name)
+			}
+		}
+		if recordError != nil {
+			record.set(batchErrorField, recordError.Error())
+			failed++
+			continue
+		}
+		sent = append(sent, record)
+		fields = append(fields, values)
+	}
+	if len(sent) == 0 {
+		return failed, nil
+	}
+
+	results, err := b.send(ctx, fields)
+	if err != nil {
+		return failed, VaultError(err, "")
+	}
+	if len(results) != len(sent) {
+		return failed, InvalidResponseError(fmt.Sprintf(
+			"\nThe vault returned %d results for %d records\n\n", len(results), len(sent)))
+	}
+	for i, result := range results {
+		if len(result.Error) > 0 && string(result.Error) != "null" {
+			sent[i].set(batchErrorField, batchErrorText(result.Error))
+			failed++
+			continue
+		}
+		for _, name := range b.results {
+			if value := batchResultField(result, name); value != "" {
+				sent[i].set(name, value)
+			}
+		}
+	}
+	return failed, nil
+}
+
+// run processes the records of --input
+func (b batchCommand) run(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	inName, _ := flags.GetString(batchOptionInput)
+	outName, _ := flags.GetString(batchOptionOutputFile)
+	format, _ := flags.GetString(batchOptionFormat)
+	if outName == "" {
+		outName = stdStream
+	}
+
+	defaults := map[string]string{}
+	for _, name := range b.fields {
+		values, _ := flags.GetStringArray(name)
+		if len(values) > 1 {
+			return UsageError("\nWith --%s, --%s sets the %s of the records which have "+
+				"none and may be given once only\n\n", batchOptionInput, name, name)
+		}
+		if len(values) == 1 {
+			defaults[name] = values[0]
+		}
+	}
+	format, err := batchFormat(inName, format)
+	if err != nil {
+		return UsageError("\n%v\n\n", err)
+	}
+
+	var reader *batchReader
+	file := &batchFile{inName: inName, outName: outName, outOption: batchOptionOutputFile,
+		state: &batchCheckpoint{Command: cmd.Name(), Input: inName, Format: format,
+			Defaults: defaults},
+		newReader: func(r io.Reader) (batchSource, error) {
+			reader, err = newBatchReader(r, format)
+			return reader, err
+		},
+		newWriter: func(w io.Writer) batchSink {
+			return newBatchWriter(w, format, reader.header,
+				append(append([]string{}, b.results...), batchErrorField))
+		},
+		process: func(ctx context.Context, records []*batchRecord) (int, error) {
+			return b.process(ctx, records, defaults)
+		},
+	}
+	if err = file.run(cmd); err != nil {
+		return err
+	}
+
+	total, failed := file.state.Records, file.state.Failed
+	if outName == stdStream {
+		// the records on stdout tell which failed
+		if failed > 0 {
+			return &CommandError{Code: ExitError}
+		}
+		return nil
+	}
+	if failed > 0 {
+		return Errorf(ExitError, "\n%d of %d records failed. See the %s field of the "+
+			"records in %s.\n\n", failed, total, batchErrorField, outName)
+	}
+	fmt.Printf("\n%d records written to %s\n\n", total, outName)
+	return nil
+}
+
+// encryptRequests returns records as requests of the batch encrypt
+// endpoints
+func encryptRequests(records []map[string]string) []vault.BatchEncryptRequest {
+	requests := make([]vault.BatchEncryptRequest, len(records))
+	for i, record := range records {
+		requests[i] = vault.BatchEncryptRequest{KeyGUID: record["keyGuid"],
+			Data: record["data"], Mode: record["mode"], IV: record["iv"],
+			AAD: record["aad"], Operation: record["operation"]}
+	}
+	return requests
+}
+
+// tokenizeRequests returns records as requests of the batch
+// tokenization endpoints
+func tokenizeRequests(records []map[string]string) []vault.TokenizeRequest {
+	requests := make([]vault.TokenizeRequest, len(records))
+	for i, record := range records {
+		requests[i] = vault.TokenizeRequest{PolicyName: record["policyName"],
+			TokenData: record["tokenData"], KeyGUID: record["keyGuid"]}
+	}
+	return requests
+}
+
+// batchResults returns the results of response, or err
+func batchResults(response *vault.BatchResponse, err error) ([]vault.BatchResult, error) {
+	if err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
@@ -17,64 +17,49 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
+	"context"
+
 	"github.com/spf13/cobra"
 )
 
+// batchMaskRecords runs the records of --input through BatchMask
+var batchMaskRecords = batchCommand{
+	fields:   []string{"policyName", "tokenData"},
+	required: []string{"policyName", "tokenData"},
+	results:  []string{"tokenData"},
+	send: func(ctx context.Context, records []map[string]string) ([]vault.BatchResult, error) {
+		return batchResults(GetVault().BatchMask(ctx, tokenizeRequests(records)))
+	},
+}
+
 var batchMaskCmd = &cobra.Command{
 	Use:   "batch-mask",
 	Short: "Batch Mask. Please provide policyName and tokenData alternatively.",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
+		if input, _ := flags.GetString(batchOptionInput); input != "" {
+			return batchMaskRecords.run(cmd)
+		}
 
 		policyName, _ := flags.GetStringArray("policyName")
 		tokenData, _ := flags.GetStringArray("tokenData")
 
 		if len(policyName) != len(tokenData) {
-			fmt.Println("Missing parameters. Please check and try again")
-			os.Exit(1)
+			return Errorf(ExitUsage, "Missing parameters. Please check and try again\n")
 		}
 
-		request := []interface{}{}
-
+		records := []vault.TokenizeRequest{}
 		for i := 0; i < len(policyName); i++ {
-			params := map[string]interface{}{}
-			params["policyName"] = policyName[i]
-			params["tokenData"] = tokenData[i]
-			request = append(request, params)
+			records = append(records, vault.TokenizeRequest{
+				PolicyName: policyName[i], TokenData: tokenData[i]})
 		}
 
-		jsonParams, err := json.Marshal(request)
+		response, err := GetVault().BatchMask(Idempotent(cmd.Context()), records)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "batch/mask")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(0)
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -85,6 +70,5 @@ func init() {
 	batchMaskCmd.Flags().StringArrayP("tokenData", "d", []string{},
 		"Data to be masked")
 
-	batchMaskCmd.MarkFlagRequired("policyName")
-	batchMaskCmd.MarkFlagRequired("tokenData")
+	batchMaskRecords.addFlags(batchMaskCmd, "tokenData")
 }
@@ -0,0 +1,364 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Formats of batch record files
+const (
+	batchFormatCSV   = "csv"
+	batchFormatJSONL = "jsonl"
+)
+
+// batchFormat returns the format of the record file name: format if
+// given, or else the format its extension says
+func batchFormat(name, format string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".csv":
+			format = batchFormatCSV
+		case ".jsonl", ".ndjson":
+			format = batchFormatJSONL
+		default:
+			return "", fmt.Errorf("Cannot tell the format of %s from its extension. "+
+				"Use --%s csv or --%s jsonl", name, batchOptionFormat, batchOptionFormat)
+		}
+	}
+	if format != batchFormatCSV && format != batchFormatJSONL {
+		return "", fmt.Errorf("Invalid %s %q. Use csv or jsonl", batchOptionFormat, format)
+	}
+	return format, nil
+}
+
+// batchRecord is a record of a batch file with its fields in the order
+// of the file. Values are the text of CSV cells or the JSON encoding of
+// JSONL values. Records of files processed as a whole, such as JSON
+// documents, are raw instead. Number counts the records of the file
+// from 1.
+type batchRecord struct {
+	names  []string
+	values []string
+	json   bool
+	raw    []byte
+	number int
+}
+
+func (r *batchRecord) index(name string) int {
+	for i, n := range r.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// get returns the field name as text. Absent, null and empty fields
+// are all empty.
+func (r *batchRecord) get(name string) (string, error) {
+	i := r.index(name)
+	if i < 0 {
+		return "", nil
+	}
+	if !r.json {
+		return r.values[i], nil
+	}
+	raw := strings.TrimSpace(r.values[i])
+	switch {
+	case raw == "null":
+		return "", nil
+	case strings.HasPrefix(raw, `"`):
+		var value string
+		err := json.Unmarshal([]byte(raw), &value)
+		return value, err
+	case strings.HasPrefix(raw, "{"), strings.HasPrefix(raw, "["):
+		return "", fmt.Errorf("field %s is not a string", name)
+	}
+	// numbers and booleans as written
+	return raw, nil
+}
+
+// set sets the field name to value, adding the field if it is absent
+func (r *batchRecord) set(name, value string) {
+	if r.json {
+		encoded, _ := json.Marshal(value)
+		value = string(encoded)
+	}
+	if i := r.index(name); i >= 0 {
+		r.values[i] = value
+		return
+	}
+	r.names = append(r.names, name)
+	r.values = append(r.values, value)
+}
+
+// batchReader reads the records of a CSV file with a header line or of
+// a JSONL file with a JSON object per line
+type batchReader struct {
+	format string
+	csv    *csv.Reader
+	header []string
+	// noHeader tells that the CSV file has no header line and header
+	// numbers the columns
+	noHeader bool
+	// pending are the CSV rows read ahead
+	pending [][]string
+	lines   *bufio.Reader
+	line    int
+	records int
+}
+
+func newBatchReader(r io.Reader, format string) (*batchReader, error) {
+	if format == batchFormatCSV {
+		return newCSVBatchReader(r, ',', false, nil)
+	}
+	return &batchReader{format: format, lines: bufio.NewReader(r)}, nil
+}
+
+// newCSVBatchReader reads a CSV file with the delimiter comma.
+// hasHeader tells from the first two rows, the second nil if there is
+// none, whether the first is a header line; nil always says it is.
+// Without a header line, the columns are named by their number from 1.
+func newCSVBatchReader(r io.Reader, comma rune, lazyQuotes bool,
+	hasHeader func(first, second []string) bool) (*batchReader, error) {
+	reader := &batchReader{format: batchFormatCSV, csv: csv.NewReader(r)}
+	reader.csv.Comma = comma
+	reader.csv.LazyQuotes = lazyQuotes
+	first, err := reader.csv.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the CSV file has no header line")
+	}
+	if err != nil {
+		return nil, err
+	}
+	// a byte order mark is not part of the first column
+	first[0] = strings.TrimPrefix(first[0], "\ufeff")
+	if hasHeader == nil {
+		reader.header = first
+		return reader, nil
+	}
+
+	second, err := reader.csv.Read()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if second != nil {
+		reader.pending = append(reader.pending, second)
+	}
+	if hasHeader(first, second) {
+		reader.header = first
+		return reader, nil
+	}
+	reader.noHeader = true
+	reader.pending = append([][]string{first}, reader.pending...)
+	for i := range first {
+		reader.header = append(reader.header, strconv.Itoa(i+1))
+	}
+	return reader, nil
+}
+
+// Read returns the next record, or io.EOF after the last one
+func (r *batchReader) Read() (*batchRecord, error) {
+	if r.format == batchFormatCSV {
+		var values []string
+		if len(r.pending) > 0 {
+			values = r.pending[0]
+			r.pending = r.pending[1:]
+		} else {
+			var err error
+			if values, err = r.csv.Read(); err != nil {
+				return nil, err
+			}
+		}
+		r.records++
+		return &batchRecord{names: append([]string{}, r.header...), values: values,
+			number: r.records}, nil
+	}
+
+	for {
+		line, err := r.lines.ReadBytes('\n')
+		if len(line) == 0 && err != nil {
+			return nil, err
+		}
+		r.line++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		record, err := parseJSONRecord(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", r.line, err)
+		}
+		r.records++
+		record.number = r.records
+		return record, nil
+	}
+}
+
+// parseJSONRecord parses a JSON object, keeping the order of its fields
+func parseJSONRecord(line []byte) (*batchRecord, error) {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil, fmt.Errorf("a record must be a JSON object")
+	}
+	record := &batchRecord{json: true}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		name, _ := token.(string)
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		record.names = append(record.names, name)
+		record.values = append(record.values, string(value))
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("data follows the JSON object")
+	}
+	return record, nil
+}
+
+// batchWriter writes records in the format of the input. CSV files
+// get the columns of the input followed by the columns added for the
+// results.
+type batchWriter struct {
+	format string
+	csv    *csv.Writer
+	header []string
+	// noHeader leaves out the header line
+	noHeader bool
+	// quoteAll quotes all CSV fields, which are then written without
+	// csv
+	quoteAll bool
+	comma    rune
+	w        *bufio.Writer
+}
+
+func newBatchWriter(w io.Writer, format string, inputHeader, added []string) *batchWriter {
+	writer := &batchWriter{format: format, w: bufio.NewWriter(w)}
+	if format == batchFormatJSONL {
+		return writer
+	}
+	writer.header = append([]string{}, inputHeader...)
+	for _, name := range added {
+		found := false
+		for _, column := range inputHeader {
+			found = found || column == name
+		}
+		if !found {
+			writer.header = append(writer.header, name)
+		}
+	}
+	writer.csv = csv.NewWriter(writer.w)
+	return writer
+}
+
+// newCSVBatchWriter writes CSV files with the columns of header and
+// the delimiter comma
+func newCSVBatchWriter(w io.Writer, header []string, noHeader bool, comma rune,
+	quoteAll bool) *batchWriter {
+	writer := newBatchWriter(w, batchFormatCSV, header, nil)
+	writer.noHeader = noHeader
+	writer.quoteAll = quoteAll
+	writer.comma = comma
+	writer.csv.Comma = comma
+	return writer
+}
+
+// WriteHeader writes the header line of CSV files
+func (w *batchWriter) WriteHeader() error {
+	if w.csv == nil || w.noHeader {
+		return nil
+	}
+	return w.writeRow(w.header)
+}
+
+// writeRow writes a CSV row
+func (w *batchWriter) writeRow(values []string) error {
+	if !w.quoteAll {
+		return w.csv.Write(values)
+	}
+	line := &bytes.Buffer{}
+	for i, value := range values {
+		if i > 0 {
+			line.WriteRune(w.comma)
+		}
+		line.WriteByte('"')
+		line.WriteString(strings.ReplaceAll(value, `"`, `""`))
+		line.WriteByte('"')
+	}
+	line.WriteByte('\n')
+	_, err := w.w.Write(line.Bytes())
+	return err
+}
+
+// Write writes record
+func (w *batchWriter) Write(record *batchRecord) error {
+	if w.format == batchFormatCSV {
+		values := make([]string, len(w.header))
+		for i, name := range w.header {
+			// columns of the input are in place, even if names repeat
+			if i < len(record.names) && record.names[i] == name {
+				values[i] = record.values[i]
+			} else if j := record.index(name); j >= 0 {
+				values[i] = record.values[j]
+			}
+		}
+		return w.writeRow(values)
+	}
+
+	line := &bytes.Buffer{}
+	line.WriteByte('{')
+	for i, name := range record.names {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		encoded, _ := json.Marshal(name)
+		line.Write(encoded)
+		line.WriteByte(':')
+		line.WriteString(record.values[i])
+	}
+	line.WriteString("}\n")
+	_, err := w.w.Write(line.Bytes())
+	return err
+}
+
+// Flush writes buffered records
+func (w *batchWriter) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
@@ -17,66 +17,49 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
+	"context"
+
 	"github.com/spf13/cobra"
 )
 
+// batchRekeyRecords runs the records of --input through BatchRekey
+var batchRekeyRecords = batchCommand{
+	fields:   []string{"policyName", "tokenData"},
+	required: []string{"policyName", "tokenData"},
+	results:  []string{"tokenData"},
+	send: func(ctx context.Context, records []map[string]string) ([]vault.BatchResult, error) {
+		return batchResults(GetVault().BatchRekey(ctx, tokenizeRequests(records)))
+	},
+}
+
 var batchRekeyCmd = &cobra.Command{
 	Use:   "batch-rekey",
 	Short: "Batch Rekey. Please provide policyName and tokenData alternatively.",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
+		if input, _ := flags.GetString(batchOptionInput); input != "" {
+			return batchRekeyRecords.run(cmd)
+		}
 
 		policyName, _ := flags.GetStringArray("policyName")
 		tokenData, _ := flags.GetStringArray("tokenData")
 
 		if len(policyName) != len(tokenData) {
-			fmt.Println("Missing parameters. Please check and try again")
-			os.Exit(1)
+			return Errorf(ExitUsage, "Missing parameters. Please check and try again\n")
 		}
 
-		request := []interface{}{}
-
+		records := []vault.TokenizeRequest{}
 		for i := 0; i < len(policyName); i++ {
-			params := map[string]interface{}{}
-			params["policyName"] = policyName[i]
-			params["tokenData"] = tokenData[i]
-			request = append(request, params)
+			records = append(records, vault.TokenizeRequest{
+				PolicyName: policyName[i], TokenData: tokenData[i]})
 		}
 
-		jsonParams, err := json.Marshal(request)
+		response, err := GetVault().BatchRekey(Idempotent(cmd.Context()), records)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "batch/rekey")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonArrayStrToMap(retStr)
-			jsonData, _ := JSONMarshalIndent(retMap)
-			fmt.Println("\n" + string(jsonData) + "\n")
-			os.Exit(0)
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -87,6 +70,5 @@ func init() {
 	batchRekeyCmd.Flags().StringArrayP("tokenData", "d", []string{},
 		"Data to be rekeyed")
 
-	batchRekeyCmd.MarkFlagRequired("policyName")
-	batchRekeyCmd.MarkFlagRequired("tokenData")
+	batchRekeyRecords.addFlags(batchRekeyCmd, "tokenData")
 }
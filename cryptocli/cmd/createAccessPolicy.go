@@ -17,153 +17,101 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
-	"strings"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"strings"
 )
 
 var createAccessPolicyCmd = &cobra.Command{
 	Use:   "create-access-policy",
 	Short: "Create Access Policy",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		policyName, _ := flags.GetString("name")
-		params["name"] = policyName
-
-		role, _ := flags.GetString("role")
-		params["role"] = role
-
-		tokenization_permissions, _ := flags.GetStringArray("tokenization_permissions")
-		params["tokenization_permissions"] = tokenization_permissions
-
-		if flags.Changed("description") {
-			description, _ := flags.GetString("description")
-			params["desc"] = description
+		policy := vault.AccessPolicy{}
+		policy.Name, _ = flags.GetString("name")
+		policy.Role, _ = flags.GetString("role")
+		policy.TokenizationPermissions, _ = flags.GetStringArray("tokenization_permissions")
+		policy.Description, _ = flags.GetString("description")
+		var err error
+		if policy.Principals, err = policyPrincipals(flags); err != nil {
+			return err
 		}
-
-		if flags.Changed("local-user") || flags.Changed("ad-upn") ||
-			flags.Changed("ad-logon-name") || flags.Changed("ad-group") {
-			principalParams := []interface{}{}
-
-			if flags.Changed("local-user") {
-				localUserArray, _ := flags.GetStringArray("local-user")
-				for i := 0; i < len(localUserArray); i += 1 {
-					usernameMap := map[string]interface{}{}
-					usernameMap["username"] = localUserArray[i]
-					localUser := map[string]interface{}{}
-					localUser["local_user"] = usernameMap
-					principalParams = append(principalParams, localUser)
-				}
-			}
-
-			if flags.Changed("ad-upn") {
-				upnArray, _ := flags.GetStringArray("ad-upn")
-				for i := 0; i < len(upnArray); i += 1 {
-					upnMap := map[string]interface{}{}
-					upnMap["upn"] = upnArray[i]
-					adUser := map[string]interface{}{}
-					adUser["ad_user"] = upnMap
-					principalParams = append(principalParams, adUser)
-				}
-			}
-
-			if flags.Changed("ad-logon-name") {
-				samArray, _ := flags.GetStringArray("ad-logon-name")
-				for i := 0; i < len(samArray); i += 1 {
-					samMap := map[string]interface{}{}
-					samMap["logon_name"] = samArray[i]
-					adUser := map[string]interface{}{}
-					adUser["ad_user"] = samMap
-					principalParams = append(principalParams, adUser)
-				}
-			}
-
-			if flags.Changed("ad-group") {
-				adGroupArray, _ := flags.GetStringArray("ad-group")
-				for i := 0; i < len(adGroupArray); i += 1 {
-					groupList := strings.Split(adGroupArray[i], "||")
-					for index := range groupList {
-						groupList[index] = strings.TrimSpace(groupList[index])
-					}
-
-					if len(groupList) != 2 {
-						fmt.Printf("\nInvalid ad-group argument: %s\n", adGroupArray[i])
-						os.Exit(1)
-					}
-					adGroupMap := map[string]interface{}{}
-					adGroupMap["dn"] = groupList[0]
-					adGroupMap["display_name"] = groupList[1]
-					adGroup := map[string]interface{}{}
-					adGroup["ad_group"] = adGroupMap
-					principalParams = append(principalParams, adGroup)
-				}
-			}
-			params["principals"] = principalParams
+		if policy.Tags, err = policyTags(flags); err != nil {
+			return err
 		}
 
-		if (flags.Changed("tagkey") && !flags.Changed("tagvalue")) ||
-			(!flags.Changed("tagkey") && flags.Changed("tagvalue")) {
-			fmt.Println("Please provide both tag key & values")
-			os.Exit(1)
-		}
-
-		if flags.Changed("tagkey") && flags.Changed("tagvalue") {
-			tagkeyArray, _ := flags.GetStringArray("tagkey")
-			tagvalueArray, _ := flags.GetStringArray("tagvalue")
-			if len(tagkeyArray) != len(tagvalueArray) {
-				fmt.Println("Please provide equal number of tag keys & values")
-				os.Exit(1)
-			}
-
-			tagParams := map[string]interface{}{}
-			for i := 0; i < len(tagvalueArray); i += 1 {
-				if IsJSON(tagvalueArray[i]) {
-					tagParams[tagkeyArray[i]] = JsonStrToMap(tagvalueArray[i])
-				} else {
-					tagParams[tagkeyArray[i]] = tagvalueArray[i]
-				}
-			}
-			params["tags"] = tagParams
-		}
-
-		jsonParams, err := json.Marshal(params)
+		response, err := GetVault().CreateAccessPolicy(cmd.Context(), policy)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
+	},
+}
 
-		endpoint := GetEndPoint("", "1.0", "CreatePolicy")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
+// policyPrincipals returns the principals given by the --local-user,
+// --ad-upn, --ad-logon-name and --ad-group options
+func policyPrincipals(flags *pflag.FlagSet) ([]vault.Principal, error) {
+	var principals []vault.Principal
+
+	localUserArray, _ := flags.GetStringArray("local-user")
+	for _, username := range localUserArray {
+		principals = append(principals, vault.LocalUser(username))
+	}
+
+	upnArray, _ := flags.GetStringArray("ad-upn")
+	for _, upn := range upnArray {
+		principals = append(principals, vault.ADUserByUPN(upn))
+	}
+
+	samArray, _ := flags.GetStringArray("ad-logon-name")
+	for _, logonName := range samArray {
+		principals = append(principals, vault.ADUserByLogonName(logonName))
+	}
+
+	adGroupArray, _ := flags.GetStringArray("ad-group")
+	for _, adGroup := range adGroupArray {
+		groupList := strings.Split(adGroup, "||")
+		for index := range groupList {
+			groupList[index] = strings.TrimSpace(groupList[index])
 		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
+		if len(groupList) != 2 {
+			return nil, UsageError("\nInvalid ad-group argument: %s\n", adGroup)
 		}
+		principals = append(principals, vault.ADGroup(groupList[0], groupList[1]))
+	}
+	return principals, nil
+}
 
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+// policyTags returns the tags given by the --tagkey and --tagvalue
+// options. Tag values in JSON are sent as JSON objects.
+func policyTags(flags *pflag.FlagSet) (map[string]interface{}, error) {
+	if flags.Changed("tagkey") != flags.Changed("tagvalue") {
+		return nil, UsageError("Please provide both tag key & values\n")
+	}
+	if !flags.Changed("tagkey") {
+		return nil, nil
+	}
+
+	tagkeyArray, _ := flags.GetStringArray("tagkey")
+	tagvalueArray, _ := flags.GetStringArray("tagvalue")
+	if len(tagkeyArray) != len(tagvalueArray) {
+		return nil, UsageError("Please provide equal number of tag keys & values\n")
+	}
+
+	tags := map[string]interface{}{}
+	for i := 0; i < len(tagvalueArray); i += 1 {
+		if IsJSON(tagvalueArray[i]) {
+			value, err := JsonStrToMap(tagvalueArray[i])
+			if err != nil {
+				return nil, UsageError("\nInvalid --tagvalue %s - %v\n", tagvalueArray[i], err)
+			}
+			tags[tagkeyArray[i]] = value
+		} else {
+			tags[tagkeyArray[i]] = tagvalueArray[i]
 		}
-		os.Exit(0)
-	},
+	}
+	return tags, nil
 }
 
 func init() {
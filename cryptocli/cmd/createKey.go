@@ -17,70 +17,33 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var createKeyCmd = &cobra.Command{
 	Use:   "create-key",
 	Short: "Create Key",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
+		request := vault.CreateKeyRequest{}
+		request.Name, _ = flags.GetString("name")
+		request.Description, _ = flags.GetString("description")
+		request.KeysetGUID, _ = flags.GetString("keyset_guid")
+		request.Cipher, _ = flags.GetString("cipher")
 
-		name, _ := flags.GetString("name")
-		params["name"] = name
-
-		if flags.Changed("description") {
-			description, _ := flags.GetString("description")
-			params["description"] = description
-		}
-
-		if flags.Changed("keyset_guid") {
-			keyset_guid, _ := flags.GetString("keyset_guid")
-			params["keyset_guid"] = keyset_guid
-		}
-
-		cipher, _ := flags.GetString("cipher")
-		params["cipher"] = cipher
-
-		jsonParams, err := json.Marshal(params)
+		response, err := GetVault().CreateKey(cmd.Context(), request)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return VaultError(err, "")
 		}
-
-		endpoint := GetEndPoint("", "1.0", "key")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
+		if err := PrintVaultResult(response.JSON()); err != nil {
+			return err
 		}
-
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+		if DefaultOutput() {
+			fmt.Printf("Key successfully created: %s\n\n", request.Name)
 		}
-		fmt.Println("Key successfully created:", name,
-			"\n")
-		os.Exit(0)
+		return nil
 	},
 }
 
@@ -97,4 +60,4 @@ func init() {
 
 	createKeyCmd.MarkFlagRequired("name")
 	createKeyCmd.MarkFlagRequired("cipher")
-}
\ No newline at end of file
+}
@@ -17,79 +17,36 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var createMaskPolicyCmd = &cobra.Command{
 	Use:   "create-mask-policy",
 	Short: "Create Mask Policy",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		name, _ := flags.GetString("name")
-		params["name"] = name
-
-		if flags.Changed("description") {
-			description, _ := flags.GetString("description")
-			params["description"] = description
-		}
-
-		new, _ := flags.GetBool("new")
-		params["isNew"] = new
-
-		preservedPrefixLength, _ := flags.GetInt("preservedPrefixLength")
-		params["preservedPrefixLength"] = preservedPrefixLength
-
-		preservedSuffixLength, _ := flags.GetInt("preservedSuffixLength")
-		params["preservedSuffixLength"] = preservedSuffixLength
-
-		charset, _ := flags.GetString("charset")
-		params["charset"] = charset
-
-		maskChar, _ := flags.GetString("maskChar")
-		params["maskChar"] = maskChar
-
-		jsonParams, err := json.Marshal(params)
+		policy := vault.MaskPolicy{}
+		policy.Name, _ = flags.GetString("name")
+		policy.Description, _ = flags.GetString("description")
+		policy.IsNew, _ = flags.GetBool("new")
+		policy.PreservedPrefixLength, _ = flags.GetInt("preservedPrefixLength")
+		policy.PreservedSuffixLength, _ = flags.GetInt("preservedSuffixLength")
+		policy.Charset, _ = flags.GetString("charset")
+		policy.MaskChar, _ = flags.GetString("maskChar")
+
+		response, err := GetVault().CreateMaskPolicy(cmd.Context(), policy)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return VaultError(err, "")
 		}
-
-		endpoint := GetEndPoint("", "1.0", "CreateMaskPolicy")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			fmt.Println("\n" + retStr + "\n")
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				os.Exit(3)
-			} else {
-				fmt.Println("Masking policy successfully created:", name,
-					"\n")
-				os.Exit(0)
-			}
+		if err := PrintVaultResult(response.JSON()); err != nil {
+			return err
 		}
+		if DefaultOutput() {
+			fmt.Printf("Masking policy successfully created: %s\n\n", policy.Name)
+		}
+		return nil
 	},
 }
 
@@ -113,4 +70,4 @@ func init() {
 	createMaskPolicyCmd.MarkFlagRequired("name")
 	createMaskPolicyCmd.MarkFlagRequired("charset")
 	createMaskPolicyCmd.MarkFlagRequired("maskChar")
-}
\ No newline at end of file
+}
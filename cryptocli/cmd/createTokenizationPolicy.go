@@ -17,82 +17,37 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var createTokenizationPolicyCmd = &cobra.Command{
 	Use:   "create-tokenization-policy",
 	Short: "Create Tokenization Policy",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		name, _ := flags.GetString("name")
-		params["name"] = name
-
-		if flags.Changed("description") {
-			description, _ := flags.GetString("description")
-			params["description"] = description
-		}
-
-		keyGuid, _ := flags.GetString("keyGuid")
-		params["keyGuid"] = keyGuid
-
-		preservedPrefixLength, _ := flags.GetInt("preservedPrefixLength")
-		params["preservedPrefixLength"] = preservedPrefixLength
-
-		preservedSuffixLength, _ := flags.GetInt("preservedSuffixLength")
-		params["preservedSuffixLength"] = preservedSuffixLength
-
-		new, _ := flags.GetBool("new")
-		params["isNew"] = new
-
-		charset, _ := flags.GetString("charset")
-		params["charset"] = charset
-
-		charsetOption, _ := flags.GetStringArray("charsetOption")
-		params["charsetOption"] = charsetOption
-
-		jsonParams, err := json.Marshal(params)
+		policy := vault.TokenizationPolicy{}
+		policy.Name, _ = flags.GetString("name")
+		policy.Description, _ = flags.GetString("description")
+		policy.KeyGUID, _ = flags.GetString("keyGuid")
+		policy.PreservedPrefixLength, _ = flags.GetInt("preservedPrefixLength")
+		policy.PreservedSuffixLength, _ = flags.GetInt("preservedSuffixLength")
+		policy.IsNew, _ = flags.GetBool("new")
+		policy.Charset, _ = flags.GetString("charset")
+		policy.CharsetOption, _ = flags.GetStringArray("charsetOption")
+
+		response, err := GetVault().CreateTokenizationPolicy(cmd.Context(), policy)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return VaultError(err, "")
 		}
-
-		endpoint := GetEndPoint("", "1.0", "CreateTokenPolicy")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			fmt.Println("\n" + retStr + "\n")
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				os.Exit(3)
-			} else {
-				fmt.Println("Tokenization policy successfully created:", name,
-					"\n")
-				os.Exit(0)
-			}
+		if err := PrintVaultResult(response.JSON()); err != nil {
+			return err
 		}
+		if DefaultOutput() {
+			fmt.Printf("Tokenization policy successfully created: %s\n\n", policy.Name)
+		}
+		return nil
 	},
 }
 
@@ -118,4 +73,4 @@ func init() {
 	createTokenizationPolicyCmd.MarkFlagRequired("keyGuid")
 	createTokenizationPolicyCmd.MarkFlagRequired("name")
 	createTokenizationPolicyCmd.MarkFlagRequired("charset")
-}
\ No newline at end of file
+}
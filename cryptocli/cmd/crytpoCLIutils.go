@@ -18,14 +18,79 @@ package cmd
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 )
 
 const DefaultTokenFilename = "crypto_token.txt"
 
 const CryptoCLIDataSubdir = "cryptocli.data"
 
+// DefaultServerPort is the vault API port used when none is given
+const DefaultServerPort = "443"
+
+var hostnameLabelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+
+// isValidHostname reports whether host is a valid DNS host name
+func isValidHostname(host string) bool {
+	host = strings.TrimSuffix(host, ".")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if !hostnameLabelPattern.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeServer validates address - a DNS host name, an IPv4 address
+// or an IPv6 address, with an optional port and the IPv6 address in
+// brackets if a port is given - and returns it as host:port, with
+// defaultPort if address has none.
+func NormalizeServer(address, defaultPort string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// no port
+		host = address
+		port = defaultPort
+		if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+			host = host[1 : len(host)-1]
+		}
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	} else if strings.Contains(host, ":") || !isValidHostname(host) {
+		return "", fmt.Errorf("Invalid host %q", host)
+	}
+
+	portNumber, err := strconv.Atoi(port)
+	if err != nil || portNumber < 1 || portNumber > 65535 {
+		return "", fmt.Errorf("Invalid port %q", port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// serverHost returns server in the form used as host of a URL. Token
+// files of earlier versions hold the server without port.
+func serverHost(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	if strings.Contains(server, ":") && !strings.HasPrefix(server, "[") {
+		// bare IPv6 address
+		return "[" + server + "]"
+	}
+	return server
+}
+
 func GetDataDir() (string, error) {
 	baseDir, err := os.UserHomeDir()
 	if err != nil {
@@ -34,33 +99,25 @@ func GetDataDir() (string, error) {
 
 	cryptoCLIDir := filepath.Join(baseDir, CryptoCLIDataSubdir)
 
-	_, err = os.Stat(cryptoCLIDir)
+	info, err := os.Stat(cryptoCLIDir)
 
 	if os.IsNotExist(err) {
-		errDir := os.MkdirAll(cryptoCLIDir, 0755)
+		errDir := os.MkdirAll(cryptoCLIDir, 0700)
 		if errDir != nil {
-			return "", err
+			return "", errDir
+		}
+	} else if err != nil {
+		return "", err
+	} else if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		// the directory holds token files; earlier versions created it
+		// accessible by other users
+		if errChmod := os.Chmod(cryptoCLIDir, 0700); errChmod != nil {
+			fmt.Fprintf(os.Stderr, "\nWARNING: %s is accessible by other users "+
+				"(permissions %04o). Restrict it by running: chmod 700 %s\n",
+				cryptoCLIDir, info.Mode().Perm(), cryptoCLIDir)
 		}
 	}
 	return cryptoCLIDir, nil
 }
 
-func GetEndPoint(server string, version string, action string) string {
-	if server == "" {
-		server = GetServer()
-	}
-	return fmt.Sprintf("https://%s/token/%s/%s/", server, version, action)
-}
-
-func GetEndPoint2(server string, version string, action string) string {
-	if server == "" {
-		server = GetServer()
-	}
-	return fmt.Sprintf("https://%s/token/%s/%s", server, version, action)
-}
 
-
-
-func AuthTokenKV() map[string]string {
-	return map[string]string{"X-TOKEN-AUTH": GetAccessToken()}
-}
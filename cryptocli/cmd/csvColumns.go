@@ -0,0 +1,302 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	csvOptionColumns    = "columns"
+	csvOptionHeader     = "header"
+	csvOptionDelimiter  = "delimiter"
+	csvOptionLazyQuotes = "lazy-quotes"
+	csvOptionQuoteAll   = "quote-all"
+)
+
+// Values of --header
+const (
+	csvHeaderAuto = "auto"
+	csvHeaderYes  = "yes"
+	csvHeaderNo   = "no"
+)
+
+// csvColumn is a column of --columns and the policy its values are
+// sent with. Columns are given by name or by number from 1.
+type csvColumn struct {
+	name   string
+	number int
+	policy string
+	// index is the index of the column once the header is read
+	index int
+}
+
+// parseCSVColumns parses the column=policy pairs of --columns
+func parseCSVColumns(pairs []string) ([]csvColumn, error) {
+	columns := []csvColumn{}
+	for _, pair := range pairs {
+		i := strings.LastIndex(pair, "=")
+		if i <= 0 || i == len(pair)-1 {
+			return nil, fmt.Errorf("Invalid column %q. Use column=policy", pair)
+		}
+		column := csvColumn{name: strings.TrimSpace(pair[:i]),
+			policy: strings.TrimSpace(pair[i+1:])}
+		if number, err := strconv.Atoi(column.name); err == nil {
+			if number < 1 {
+				return nil, fmt.Errorf("Invalid column number %d. Columns are numbered from 1",
+					number)
+			}
+			column.number = number
+		}
+		columns = append(columns, column)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("No columns given. Use --%s column=policy", csvOptionColumns)
+	}
+	return columns, nil
+}
+
+// parseDelimiter returns the delimiter of --delimiter, a character or
+// \t or tab for a tab
+func parseDelimiter(delimiter string) (rune, error) {
+	if delimiter == `\t` || delimiter == "tab" {
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) || r == utf8.RuneError || r == '"' ||
+		r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("Invalid %s %q. Use a single character other than "+
+			"a quote or a line break", csvOptionDelimiter, delimiter)
+	}
+	return r, nil
+}
+
+// hasDigit reports whether s holds a digit
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+// csvHasHeader returns whether a CSV file whose first two rows are
+// first and second starts with a header line, as --header says. Auto
+// finds one if the first row names the columns given by name or, with
+// columns given by number only, if their cells hold no digits where
+// those of the second row do.
+func csvHasHeader(mode string, columns []csvColumn) func(first, second []string) bool {
+	return func(first, second []string) bool {
+		switch mode {
+		case csvHeaderYes:
+			return true
+		case csvHeaderNo:
+			return false
+		}
+		byName := false
+		for _, column := range columns {
+			if column.number == 0 {
+				byName = true
+			}
+		}
+		if byName {
+			// the missing columns are reported with the header
+			return true
+		}
+		dataBelow := false
+		for _, column := range columns {
+			i := column.number - 1
+			if i >= len(first) || first[i] == "" || hasDigit(first[i]) {
+				return false
+			}
+			if second != nil && i < len(second) && hasDigit(second[i]) {
+				dataBelow = true
+			}
+		}
+		return dataBelow
+	}
+}
+
+// csvColumnCommand sends columns of a CSV file through a batch
+// tokenization endpoint of the vault, with a policy per column
+type csvColumnCommand struct {
+	// verb says what the command does to the values, e.g. tokenize
+	verb string
+	send func(ctx context.Context, requests []vault.TokenizeRequest) (*vault.BatchResponse,
+		error)
+}
+
+// addFlags adds the options of CSV files to cmd
+func (c csvColumnCommand) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP(fileOptionIn, "i", "", "CSV file to read, or - for stdin")
+	cmd.Flags().StringP(fileOptionOut, "o", "",
+		"CSV file to write, or - for stdout. Defaults to stdout.")
+	cmd.Flags().StringSlice(csvOptionColumns, []string{},
+		"Columns to "+c.verb+" and their policies as column=policy, comma separated. "+
+			"Columns are given by header name or by number from 1.")
+	cmd.Flags().String(csvOptionHeader, csvHeaderAuto,
+		"Whether the first row is a header line: auto, yes or no")
+	cmd.Flags().String(csvOptionDelimiter, ",",
+		`Delimiter of the fields: a character, or \t or tab for a tab`)
+	cmd.Flags().Bool(csvOptionLazyQuotes, false,
+		"Accept quotes in unquoted fields and unescaped quotes in quoted fields")
+	cmd.Flags().Bool(csvOptionQuoteAll, false,
+		"Quote all fields written, not only those that need it")
+	addBatchEngineFlags(cmd, fileOptionOut, "values")
+
+	cmd.MarkFlagRequired(fileOptionIn)
+	cmd.MarkFlagRequired(csvOptionColumns)
+}
+
+// resolveCSVColumns sets the index of columns in the header of reader
+func resolveCSVColumns(columns []csvColumn, reader *batchReader) error {
+	for i := range columns {
+		column := &columns[i]
+		if column.number > 0 {
+			if column.number > len(reader.header) {
+				return fmt.Errorf("column %d does not exist, the file has %d columns",
+					column.number, len(reader.header))
+			}
+			column.index = column.number - 1
+			continue
+		}
+		column.index = -1
+		if !reader.noHeader {
+			for j, name := range reader.header {
+				if name == column.name {
+					column.index = j
+					break
+				}
+			}
+		}
+		if column.index < 0 {
+			return fmt.Errorf("column %s is not in the header line", column.name)
+		}
+	}
+	return nil
+}
+
+// process sends the non-empty values of columns of records and puts
+// their results in their place. A value which fails fails the run, as
+// the file has no place for the error.
+func (c csvColumnCommand) process(ctx context.Context, records []*batchRecord,
+	columns []csvColumn) (int, error) {
+	type cell struct {
+		record *batchRecord
+		column csvColumn
+	}
+	cells := []cell{}
+	requests := []vault.TokenizeRequest{}
+	for _, record := range records {
+		for _, column := range columns {
+			if value := record.values[column.index]; value != "" {
+				cells = append(cells, cell{record, column})
+				requests = append(requests, vault.TokenizeRequest{PolicyName: column.policy,
+					TokenData: value})
+			}
+		}
+	}
+	if len(requests) == 0 {
+		return 0, nil
+	}
+
+	response, err := c.send(ctx, requests)
+	if err != nil {
+		return 0, VaultError(err, "")
+	}
+	if len(response.Results) != len(requests) {
+		return 0, InvalidResponseError(fmt.Sprintf(
+			"\nThe vault returned %d results for %d values\n\n", len(response.Results),
+			len(requests)))
+	}
+	for i, result := range response.Results {
+		target := cells[i]
+		if len(result.Error) > 0 && string(result.Error) != "null" {
+			return 0, Errorf(ExitServerError, "\nFailed to %s column %s of row %d:\n%s\n\n",
+				c.verb, target.record.names[target.column.index], target.record.number,
+				batchErrorText(result.Error))
+		}
+		target.record.values[target.column.index] = result.TokenData
+	}
+	return 0, nil
+}
+
+// run processes the columns of the CSV file --in
+func (c csvColumnCommand) run(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	inName, _ := flags.GetString(fileOptionIn)
+	outName, _ := flags.GetString(fileOptionOut)
+	pairs, _ := flags.GetStringSlice(csvOptionColumns)
+	header, _ := flags.GetString(csvOptionHeader)
+	delimiter, _ := flags.GetString(csvOptionDelimiter)
+	lazyQuotes, _ := flags.GetBool(csvOptionLazyQuotes)
+	quoteAll, _ := flags.GetBool(csvOptionQuoteAll)
+	if outName == "" {
+		outName = stdStream
+	}
+
+	columns, err := parseCSVColumns(pairs)
+	if err != nil {
+		return UsageError("\n%v\n\n", err)
+	}
+	comma, err := parseDelimiter(delimiter)
+	if err != nil {
+		return UsageError("\n%v\n\n", err)
+	}
+	if header != csvHeaderAuto && header != csvHeaderYes && header != csvHeaderNo {
+		return UsageError("\nInvalid %s %q. Use %s, %s or %s.\n\n", csvOptionHeader, header,
+			csvHeaderAuto, csvHeaderYes, csvHeaderNo)
+	}
+
+	var reader *batchReader
+	file := &batchFile{inName: inName, outName: outName, outOption: fileOptionOut,
+		valuesPerRecord: len(columns),
+		state: &batchCheckpoint{Command: cmd.Name(), Input: inName, Format: batchFormatCSV,
+			Options: map[string]string{
+				csvOptionColumns:    strings.Join(pairs, ","),
+				csvOptionHeader:     header,
+				csvOptionDelimiter:  string(comma),
+				csvOptionLazyQuotes: strconv.FormatBool(lazyQuotes),
+				csvOptionQuoteAll:   strconv.FormatBool(quoteAll)}},
+		newReader: func(r io.Reader) (batchSource, error) {
+			reader, err = newCSVBatchReader(r, comma, lazyQuotes,
+				csvHasHeader(header, columns))
+			if err == nil {
+				err = resolveCSVColumns(columns, reader)
+			}
+			return reader, err
+		},
+		newWriter: func(w io.Writer) batchSink {
+			return newCSVBatchWriter(w, reader.header, reader.noHeader, comma, quoteAll)
+		},
+		process: func(ctx context.Context, records []*batchRecord) (int, error) {
+			return c.process(ctx, records, columns)
+		},
+	}
+	if err = file.run(cmd); err != nil {
+		return err
+	}
+	if outName != stdStream {
+		fmt.Printf("\n%d rows written to %s\n\n", file.state.Records, outName)
+	}
+	return nil
+}
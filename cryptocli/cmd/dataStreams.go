@@ -0,0 +1,228 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	dataOptionInputEncoding  = "input-encoding"
+	dataOptionOutputEncoding = "output-encoding"
+)
+
+// Encodings of the data read with --in or --data and of the results
+// written with --out
+const (
+	encodingRaw       = "raw"
+	encodingBase64    = "base64"
+	encodingBase64URL = "base64url"
+	encodingHex       = "hex"
+)
+
+var dataEncodings = []string{encodingRaw, encodingBase64, encodingBase64URL, encodingHex}
+
+// dataCommand describes the data a command sends to the vault and the
+// result it receives. Binary data and results are base64 encoded in
+// the API, the others are text.
+type dataCommand struct {
+	dataOption   string
+	binaryData   bool
+	binaryResult bool
+}
+
+// addFlags adds --in, --out and the encoding options to cmd. The data
+// option gives data on the command line, in the encoding of the API
+// unless --input-encoding says otherwise.
+func (d dataCommand) addFlags(cmd *cobra.Command, what string) {
+	native := encodingRaw
+	if d.binaryData {
+		native = encodingBase64
+	}
+	resultEncoding := encodingRaw
+	if d.binaryResult {
+		resultEncoding = encodingBase64
+	}
+	cmd.Flags().String(fileOptionIn, "",
+		"File to read the "+what+" from, or - for stdin, in place of --"+d.dataOption)
+	cmd.Flags().String(fileOptionOut, "",
+		"File to write the result to, or - for stdout, in place of printing the response")
+	cmd.Flags().String(dataOptionInputEncoding, "",
+		"Encoding of the "+what+": raw, base64, base64url or hex. Defaults to raw "+
+			"for --in and to "+native+" for --"+d.dataOption+".")
+	cmd.Flags().String(dataOptionOutputEncoding, resultEncoding,
+		"Encoding of the result written by --out: raw, base64, base64url or hex. "+
+			"Implies --out - if --out is not given.")
+	cmd.MarkFlagsMutuallyExclusive(d.dataOption, fileOptionIn)
+}
+
+// validEncoding returns an error if the encoding option name is not
+// one of dataEncodings
+func validEncoding(flags *pflag.FlagSet, name string) (string, error) {
+	encoding, _ := flags.GetString(name)
+	for _, valid := range dataEncodings {
+		if encoding == valid || (encoding == "" && name == dataOptionInputEncoding) {
+			return encoding, nil
+		}
+	}
+	return "", UsageError("\nInvalid %s %q. Use %s.\n\n", name, encoding,
+		strings.Join(dataEncodings, ", "))
+}
+
+// decodeData decodes data given in encoding
+func decodeData(data []byte, encoding string) ([]byte, error) {
+	text := strings.TrimSpace(string(data))
+	switch encoding {
+	case encodingBase64:
+		return base64.StdEncoding.DecodeString(text)
+	case encodingBase64URL:
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(text, "="))
+	case encodingHex:
+		return hex.DecodeString(text)
+	}
+	return data, nil
+}
+
+// encodeData encodes data in encoding
+func encodeData(data []byte, encoding string) string {
+	switch encoding {
+	case encodingBase64:
+		return base64.StdEncoding.EncodeToString(data)
+	case encodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString(data)
+	case encodingHex:
+		return hex.EncodeToString(data)
+	}
+	return string(data)
+}
+
+// readData returns the data of the command, from --in or the data
+// option, as the API takes it. With a prompt, the data option is
+// sensitive and prompted for if neither is given.
+func (d dataCommand) readData(flags *pflag.FlagSet, prompt string) (string, error) {
+	if _, err := validEncoding(flags, dataOptionOutputEncoding); err != nil {
+		return "", err
+	}
+	encoding, err := validEncoding(flags, dataOptionInputEncoding)
+	if err != nil {
+		return "", err
+	}
+	inName, _ := flags.GetString(fileOptionIn)
+
+	var data []byte
+	if inName != "" {
+		in, err := openInput(inName)
+		if err != nil {
+			return "", Errorf(ExitError, "\nError opening input file - %v\n\n", err)
+		}
+		defer in.Close()
+		if data, err = io.ReadAll(in); err != nil {
+			return "", Errorf(ExitError, "\nError reading input file - %v\n\n", err)
+		}
+		if encoding == "" {
+			encoding = encodingRaw
+			if !d.binaryData {
+				// text usually ends with a line break that is not data
+				data = []byte(trimNewline(string(data)))
+			}
+		}
+	} else {
+		var value string
+		if prompt != "" {
+			if value, err = CommandSecretFlag(flags, d.dataOption, prompt, true); err != nil {
+				return "", err
+			}
+		} else {
+			value, _ = flags.GetString(d.dataOption)
+		}
+		if encoding == "" {
+			// given as the API takes it
+			return value, nil
+		}
+		data = []byte(value)
+	}
+
+	if data, err = decodeData(data, encoding); err != nil {
+		return "", UsageError("\nInvalid %s data - %v\n\n", encoding, err)
+	}
+	if d.binaryData {
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return string(data), nil
+}
+
+// writeResult writes result, as the API returned it, to --out and
+// reports whether --out or --output-encoding asked for it. Results are
+// followed by a line break unless they are written as raw binary data.
+func (d dataCommand) writeResult(flags *pflag.FlagSet, result string) (bool, error) {
+	outName, _ := flags.GetString(fileOptionOut)
+	if outName == "" {
+		if !flags.Changed(dataOptionOutputEncoding) {
+			return false, nil
+		}
+		outName = stdStream
+	}
+	encoding, err := validEncoding(flags, dataOptionOutputEncoding)
+	if err != nil {
+		return true, err
+	}
+
+	data := []byte(result)
+	if d.binaryResult {
+		if data, err = base64.StdEncoding.DecodeString(result); err != nil {
+			return true, InvalidResponseError(
+				fmt.Sprintf("\nInvalid base64 result - %v\n\n", err))
+		}
+	}
+	output := encodeData(data, encoding)
+	if encoding != encodingRaw || !d.binaryResult {
+		output += "\n"
+	}
+
+	out, err := createOutput(outName)
+	if err != nil {
+		return true, Errorf(ExitError, "\nError creating output file - %v\n\n", err)
+	}
+	if _, err = io.WriteString(out, output); err != nil {
+		out.Abort()
+		return true, Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+	}
+	if err = out.Commit(); err != nil {
+		return true, Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+	}
+	if outName != stdStream {
+		fmt.Printf("\nResult written to %s\n\n", outName)
+	}
+	return true, nil
+}
+
+// printResult writes result to --out if asked for, or else prints the
+// response
+func (d dataCommand) printResult(flags *pflag.FlagSet, result string,
+	response []byte) error {
+	if written, err := d.writeResult(flags, result); written || err != nil {
+		return err
+	}
+	return PrintVaultResult(response)
+}
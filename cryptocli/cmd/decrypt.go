@@ -17,69 +17,28 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
 var decryptCmd = &cobra.Command{
 	Use:   "decrypt",
 	Short: "decrypt",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		keyGuid, _ := flags.GetString("keyGuid")
-		params["keyGuid"] = keyGuid
-
 		data, _ := flags.GetString("data")
-		params["data"] = data
-
 		mode, _ := flags.GetString("mode")
-		params["mode"] = mode
-
 		iv, _ := flags.GetString("iv")
-		params["iv"] = iv
-
 		aad, _ := flags.GetString("aad")
-		params["aad"] = aad
 
-		jsonParams, err := json.Marshal(params)
+		response, err := GetVault().Decrypt(Idempotent(cmd.Context()),
+			vault.EncryptRequest{KeyGUID: keyGuid, Data: data, Mode: mode,
+				IV: iv, AAD: aad})
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "decrypt")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
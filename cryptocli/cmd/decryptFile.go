@@ -0,0 +1,83 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+var decryptFileCmd = &cobra.Command{
+	Use:   "decrypt-file",
+	Short: "Decrypt a file encrypted by encrypt-file",
+	Long: `Decrypt a file encrypted by encrypt-file
+
+The vault unwraps the data key with the key named in the header of the
+file. Each chunk is authenticated before it is written. A file output is
+only created once the whole file decrypted, while output to stdout may
+end early if the file turns out to be truncated or altered.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		inName, _ := flags.GetString(fileOptionIn)
+		outName, _ := flags.GetString(fileOptionOut)
+
+		in, err := openInput(inName)
+		if err != nil {
+			return Errorf(ExitError, "\nError opening input file - %v\n\n", err)
+		}
+		defer in.Close()
+
+		envelope, err := GetVault().NewEnvelopeReader(Idempotent(cmd.Context()),
+			bufio.NewReader(in))
+		if err != nil {
+			return envelopeError("Decrypting the file failed:", err)
+		}
+		out, err := createOutput(outName)
+		if err != nil {
+			return Errorf(ExitError, "\nError creating output file - %v\n\n", err)
+		}
+		if _, err = io.Copy(out, envelope); err != nil {
+			out.Abort()
+			return Errorf(ExitError, "\nDecrypting the file failed:\n%v\n\n", err)
+		}
+		if err = out.Commit(); err != nil {
+			return Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+		}
+
+		if outName != stdStream {
+			header := envelope.Header()
+			fmt.Printf("\nFile decrypted to %s with key %s version %s\n\n", outName,
+				header.KeyGUID, header.KeyVersion)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(decryptFileCmd)
+	decryptFileCmd.Flags().StringP(fileOptionIn, "i", "",
+		"File to decrypt, or - for stdin")
+	decryptFileCmd.Flags().StringP(fileOptionOut, "o", "",
+		"File to write the decrypted file to, or - for stdout. "+
+			"It is replaced only once decryption succeeded.")
+
+	decryptFileCmd.MarkFlagRequired(fileOptionIn)
+	decryptFileCmd.MarkFlagRequired(fileOptionOut)
+}
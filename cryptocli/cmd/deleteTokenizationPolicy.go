@@ -17,48 +17,21 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var deleteTokenPolicyCmd = &cobra.Command{
 	Use:   "delete-tokenization-policy",
 	Short: "Delete Token Policy",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-
 		name, _ := flags.GetString("name")
 
-		endpoint := GetEndPoint("", "1.0", "DeleteTokenPolicy/"+name)
-		ret, err := DoDelete(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			nil,
-			"application/json")
+		response, err := GetVault().DeleteTokenizationPolicy(cmd.Context(), name)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -68,4 +41,4 @@ func init() {
 		"Policy Name")
 
 	deleteTokenPolicyCmd.MarkFlagRequired("name")
-}
\ No newline at end of file
+}
@@ -17,66 +17,26 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
 var detokenMaskCmd = &cobra.Command{
 	Use:   "detoken-mask",
 	Short: "Detoken Mask",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		policyName, _ := flags.GetString("policyName")
-		params["policyName"] = policyName
-
 		tokenData, _ := flags.GetString("tokenData")
-		params["tokenData"] = tokenData
-		
-		if flags.Changed("keyGuid") {
-			keyGuid, _ := flags.GetString("keyGuid")
-			params["keyGuid"] = keyGuid
-		}
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
+		keyGuid, _ := flags.GetString("keyGuid")
 
-		endpoint := GetEndPoint("", "1.0", "detoken_mask")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().DetokenizeMask(Idempotent(cmd.Context()),
+			vault.TokenizeRequest{PolicyName: policyName, TokenData: tokenData,
+				KeyGUID: keyGuid})
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				jsonData, _ := JSONMarshalIndent(retMap)
-				fmt.Println("\n" + string(jsonData) + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -0,0 +1,56 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+// detokenizeCSVColumns sends the columns of --in through BatchDetokenize
+var detokenizeCSVColumns = csvColumnCommand{
+	verb: "detokenize",
+	send: func(ctx context.Context, requests []vault.TokenizeRequest) (*vault.BatchResponse,
+		error) {
+		return GetVault().BatchDetokenize(ctx, requests)
+	},
+}
+
+var detokenizeCSVCmd = &cobra.Command{
+	Use:   "detokenize-csv",
+	Short: "Detokenize columns of a CSV file",
+	Long: `Detokenize columns of a CSV file
+
+The tokens of the columns --columns names are detokenized with the
+tokenization policy given for each column; other columns are written
+as they are. Empty cells stay empty. The file is streamed and the
+tokens are sent to the vault in batches, so memory use does not depend
+on the size of the file.
+
+A value the vault fails to detokenize stops the command. With --out, the run
+can be continued with --resume once the cause is fixed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return detokenizeCSVColumns.run(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(detokenizeCSVCmd)
+	detokenizeCSVColumns.addFlags(detokenizeCSVCmd)
+}
@@ -0,0 +1,58 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+
+	"github.com/spf13/cobra"
+)
+
+// detokenizeParquetColumns sends the columns of --in through BatchDetokenize
+var detokenizeParquetColumns = parquetColumnCommand{
+	verb: "detokenize",
+	send: func(ctx context.Context, requests []vault.TokenizeRequest) (*vault.BatchResponse,
+		error) {
+		return GetVault().BatchDetokenize(ctx, requests)
+	},
+}
+
+var detokenizeParquetCmd = &cobra.Command{
+	Use:   "detokenize-parquet",
+	Short: "Detokenize columns of a Parquet file",
+	Long: `Detokenize columns of a Parquet file
+
+The tokens of the string columns --columns names are detokenized with
+the tokenization policy given for each column and written to a new
+Parquet file with the schema, compression and row groups of the input.
+Other columns are written as they are, and null values stay null. The
+file is read a row group at a time and the tokens are sent to the
+vault in batches, so memory use depends on the size of the row groups
+only.
+
+A value the vault fails to detokenize stops the command and --out is
+not written.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return detokenizeParquetColumns.run(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(detokenizeParquetCmd)
+	detokenizeParquetColumns.addFlags(detokenizeParquetCmd)
+}
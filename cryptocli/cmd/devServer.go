@@ -0,0 +1,127 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault/vaulttest"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	devServerOptionListen         = "listen"
+	devServerOptionVaultID        = "vault-id"
+	devServerOptionUsername       = "username"
+	devServerOptionPassword       = "password"
+	devServerOptionCACertOut      = "cacert-out"
+	devServerOptionSessionTimeout = "session-timeout"
+)
+
+var devServerCmd = &cobra.Command{
+	Use:   "dev-server",
+	Short: "Run a local mock " + VaultName + " for tests and demos",
+	Long: "Run a local mock " + VaultName + " serving the API used by cryptocli " +
+		"from memory, so that cryptocli can be tried and tested offline. The " +
+		"mock supports logins, keys, encryption, signing, tokenization, masking, " +
+		"access policies and audit messages; it does not enforce access " +
+		"policies and loses all data when stopped. Never store real data in it.\n\n" +
+		"The server uses a new self-signed certificate, written to the " +
+		"--" + devServerOptionCACertOut + " file for use as login CA certificate. " +
+		"It is issued for localhost and the host of --" + devServerOptionListen +
+		", or for all addresses and the host name of the machine if that host is " +
+		"0.0.0.0 or ::. " +
+		"Stop the server with Ctrl-C.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		listen, _ := flags.GetString(devServerOptionListen)
+		caCertOut, _ := flags.GetString(devServerOptionCACertOut)
+		username, _ := flags.GetString(devServerOptionUsername)
+		password, _ := flags.GetString(devServerOptionPassword)
+		config := vaulttest.Config{Users: map[string]string{username: password}}
+		config.VaultID, _ = flags.GetString(devServerOptionVaultID)
+		config.SessionTimeout, _ = flags.GetDuration(devServerOptionSessionTimeout)
+
+		if !regexp.MustCompile(`^[0-9a-fA-F-]+$`).MatchString(config.VaultID) {
+			return UsageError("\nInvalid --%s %q - use hexadecimal digits and '-' only\n",
+				devServerOptionVaultID, config.VaultID)
+		}
+		host, _, err := net.SplitHostPort(listen)
+		if err != nil {
+			return UsageError("\nInvalid --%s %q - %v\n", devServerOptionListen, listen, err)
+		}
+		cert, certPEM, err := vaulttest.NewCertificate(host)
+		if err != nil {
+			return Errorf(ExitError, "\nError creating server certificate - %v\n", err)
+		}
+		if err = os.WriteFile(caCertOut, certPEM, 0644); err != nil {
+			return Errorf(ExitError, "\nError writing CA certificate to %s - %v\n", caCertOut, err)
+		}
+
+		listener, err := net.Listen("tcp", listen)
+		if err != nil {
+			return Errorf(ExitError, "\nError listening on %s - %v\n", listen, err)
+		}
+		mock := vaulttest.New(config)
+		server := &http.Server{
+			Handler:           mock,
+			TLSConfig:         &tls.Config{Certificates: []tls.Certificate{cert}},
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			<-cmd.Context().Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(ctx)
+		}()
+
+		fmt.Printf("\nMock %s listening on %s\n", VaultName, listener.Addr())
+		fmt.Printf("Log in with\n\n  cryptocli login --%s %s --%s %s --%s %s\n\n",
+			loginOptionLoginURL, vaulttest.LoginURL(listener.Addr().String(), mock.VaultID()),
+			loginOptionCACert, caCertOut, loginOptionUserName, username)
+		err = server.ServeTLS(listener, "", "")
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return Errorf(ExitError, "\nServer failed - %v\n", err)
+		}
+		fmt.Printf("\nServer stopped\n")
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(devServerCmd)
+	devServerCmd.Flags().String(devServerOptionListen, "127.0.0.1:8443",
+		"Address to listen on, as host:port")
+	devServerCmd.Flags().String(devServerOptionVaultID, vaulttest.DefaultVaultID,
+		"Vault ID of the login URL")
+	devServerCmd.Flags().String(devServerOptionUsername, vaulttest.DefaultUsername,
+		"Username of the user who may log in")
+	devServerCmd.Flags().String(devServerOptionPassword, vaulttest.DefaultPassword,
+		"Password of the user who may log in")
+	devServerCmd.Flags().String(devServerOptionCACertOut, "dev-server-cacert.pem",
+		"File to write the server certificate to, for login --"+loginOptionCACert)
+	devServerCmd.Flags().Duration(devServerOptionSessionTimeout,
+		vaulttest.DefaultSessionTimeout, "Lifetime of login sessions, e.g. 10m")
+}
@@ -17,60 +17,30 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
+// digestData reads the data to digest from --data or --in
+var digestData = dataCommand{dataOption: "data", binaryData: true, binaryResult: true}
+
 var digestCmd = &cobra.Command{
 	Use:   "digest",
 	Short: "Message digest",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		data, _ := flags.GetString("data")
-		params["data"] = data
-
-		mode, _ := flags.GetString("mode")
-		params["mode"] = mode
-
-		jsonParams, err := json.Marshal(params)
+		data, err := digestData.readData(flags, "")
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
+		mode, _ := flags.GetString("mode")
 
-		endpoint := GetEndPoint("", "1.0", "digest")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().Digest(Idempotent(cmd.Context()),
+			vault.DigestRequest{Data: data, Mode: mode})
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return digestData.printResult(flags, response.Digest, response.JSON())
 	},
 }
 
@@ -78,7 +48,8 @@ func init() {
 	rootCmd.AddCommand(digestCmd)
 	digestCmd.Flags().StringP("data", "d", "", "data to be digested (base64 encoded)")
 	digestCmd.Flags().StringP("mode", "m", "", "Message Digest Mode (e.g., SHA-256, SHA-512)")
+	digestData.addFlags(digestCmd, "data to digest")
 
-	digestCmd.MarkFlagRequired("data")
+	digestCmd.MarkFlagsOneRequired("data", fileOptionIn)
 	digestCmd.MarkFlagRequired("mode")
 }
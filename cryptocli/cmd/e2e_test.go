@@ -0,0 +1,876 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+// The end-to-end tests run commands against a mock vault and compare
+// the requests they send, their output and their exit code with the
+// golden files in testdata/e2e. After an intended change of behavior,
+// rewrite the golden files with
+//
+//	go test ./cmd -run TestCommands -update
+
+import (
+	"bytes"
+	"cli/pkg/vault"
+	"cli/pkg/vault/vaulttest"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the end-to-end tests")
+
+// e2eNow is the clock of the mock vault. Sessions it starts must not
+// have expired by the real clock the commands check them with.
+var e2eNow = time.Date(2099, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// e2eTest is a command run against a mock vault. Variables of the
+// fixtures, such as {KEY_GUID}, and {TMP}, the temporary directory of
+// the test, are expanded in args and in files, which are written to
+// {TMP} before the command runs. The commands of setup run before it,
+// unrecorded. The content of the files of show is added to the output
+// after the command ran, that of Parquet files as their rows. env sets
+// environment variables for setup and the command.
+type e2eTest struct {
+	name    string
+	args    []string
+	noLogin bool
+	files   map[string]string
+	setup   [][]string
+	show    []string
+	env     map[string]string
+}
+
+// e2eProfileLogin returns the arguments of a login into the profile
+// name of the vault of loginURL
+func e2eProfileLogin(name, loginURL string) []string {
+	return []string{"login", "--profile", name, "--login-URL", loginURL,
+		"--cacert", "{CACERT}", "--username", vaulttest.DefaultUsername,
+		"--password", vaulttest.DefaultPassword}
+}
+
+// e2eProfiles logs into the profiles prod, of the vault of the
+// fixtures, and staging, of the other vault, and uses current
+var e2eProfiles = func(current string) [][]string {
+	return [][]string{e2eProfileLogin("prod", "{LOGIN_URL}"),
+		e2eProfileLogin("staging", "{OTHER_LOGIN_URL}"), {"profile", "use", current}}
+}
+
+var e2eTests = []e2eTest{
+	{name: "login", noLogin: true, args: []string{"login", "--login-URL", "{LOGIN_URL}",
+		"--cacert", "{CACERT}", "--username", vaulttest.DefaultUsername,
+		"--password", vaulttest.DefaultPassword}},
+	{name: "login-wrong-password", noLogin: true, args: []string{"login",
+		"--login-URL", "{LOGIN_URL}", "--cacert", "{CACERT}",
+		"--username", vaulttest.DefaultUsername, "--password", "wrong"}},
+	{name: "not-logged-in", noLogin: true, args: []string{"list-of-keys"}},
+	{name: "session-status-expired", noLogin: true,
+		args: []string{"session", "status", "--token-file", "{TMP}/expired.json"},
+		files: map[string]string{"expired.json": `{"access_token": "expired", ` +
+			`"server": "{HOST}", "cacert_file": "{CACERT}", "user": "admin", ` +
+			`"expires_at": "2020-01-01T00:00:00Z"}`}},
+	{name: "create-key", args: []string{"create-key", "--name", "new-key",
+		"--cipher", "AES-256", "--description", "created by the test"}},
+	{name: "list-of-keys", args: []string{"list-of-keys", "--keyset_guid", "{KEYSET_GUID}"}},
+	{name: "list-of-keys-query", args: []string{"list-of-keys", "--keyset_guid", "{KEYSET_GUID}",
+		"--output", "json", "--query", "keys[].name"}},
+	{name: "encrypt", args: []string{"encrypt", "--keyGuid", "{KEY_GUID}",
+		"--data", "c2VjcmV0", "--mode", "GCM", "--aad", "Y29udGV4dA=="}},
+	{name: "encrypt-denied", args: []string{"encrypt",
+		"--keyGuid", "00000000-0000-4000-8000-00000000dead", "--data", "c2VjcmV0",
+		"--mode", "GCM"}},
+	{name: "encrypt-denied-json-error", args: []string{"encrypt", "--error-format", "json",
+		"--keyGuid", "00000000-0000-4000-8000-00000000dead", "--data", "c2VjcmV0",
+		"--mode", "GCM"}},
+	{name: "encrypt-missing-flag", args: []string{"encrypt", "--data", "c2VjcmV0"}},
+	{name: "decrypt", args: []string{"decrypt", "--keyGuid", "{KEY_GUID}",
+		"--data", "{CIPHERTEXT}", "--iv", "{IV}", "--mode", "GCM"}},
+	{name: "encrypt-file", args: []string{"encrypt-file", "--keyGuid", "{KEY_GUID}",
+		"--in", "{PLAIN_FILE}", "--out", "{TMP}/plain.txt.enc", "--chunk-size", "16"}},
+	{name: "encrypt-file-wrap", args: []string{"encrypt-file", "--keyGuid", "{KEY_GUID}",
+		"--in", "{PLAIN_FILE}", "--out", "{TMP}/plain.txt.enc", "--wrap-method", "wrap"}},
+	{name: "encrypt-file-invalid-wrap-method", args: []string{"encrypt-file",
+		"--keyGuid", "{KEY_GUID}", "--in", "{PLAIN_FILE}", "--out", "{TMP}/plain.txt.enc",
+		"--wrap-method", "seal"}},
+	{name: "decrypt-file", args: []string{"decrypt-file", "--in", "{ENVELOPE_FILE}",
+		"--out", "-"}},
+	{name: "decrypt-file-tampered", args: []string{"decrypt-file",
+		"--in", "{TAMPERED_FILE}", "--out", "{TMP}/plain.txt"}},
+	{name: "decrypt-file-not-envelope", args: []string{"decrypt-file",
+		"--in", "{PLAIN_FILE}", "--out", "{TMP}/plain.txt"}},
+	{name: "digest-file", args: []string{"digest", "--in", "{PLAIN_FILE}",
+		"--mode", "SHA-256", "--output-encoding", "hex"}},
+	{name: "digest-hex-data", args: []string{"digest", "--data", "616263",
+		"--input-encoding", "hex", "--mode", "SHA-256"}},
+	{name: "digest-invalid-encoding", args: []string{"digest", "--in", "{PLAIN_FILE}",
+		"--mode", "SHA-256", "--output-encoding", "base32"}},
+	{name: "import-key-material-file", args: []string{"import-key", "--name", "imported",
+		"--cipher", "AES-256", "--wrapping_key_guid", "{KEY_GUID}",
+		"--key_material", "@{TMP}/wrapped.txt"},
+		files: map[string]string{"wrapped.txt": "d3JhcHBlZA==\n"}},
+	{name: "wrap-out", args: []string{"wrap", "--keyGuid", "{KEY_GUID}",
+		"--data", "000102030405060708090a0b0c0d0e0f", "--input-encoding", "hex",
+		"--out", "{TMP}/wrapped.bin", "--output-encoding", "raw"}},
+	{name: "tokenize-file", args: []string{"tokenize", "--policyName", "ssn",
+		"--in", "{SSN_FILE}", "--out", "-"}},
+	{name: "batch-encrypt", args: []string{"batch-encrypt",
+		"--keyGuid", "{KEY_GUID}", "--data", "Zmlyc3Q=", "--mode", "GCM",
+		"--iv", "0", "--aad", "0",
+		"--keyGuid", "{KEY_GUID}", "--data", "c2Vjb25k", "--mode", "GCM",
+		"--iv", "AAAAAAAAAAAAAAAA", "--aad", "Y29udGV4dA=="}},
+	{name: "batch-encrypt-missing-parameters", args: []string{"batch-encrypt",
+		"--keyGuid", "{KEY_GUID}", "--keyGuid", "{KEY_GUID}", "--data", "Zmlyc3Q=",
+		"--mode", "GCM", "--iv", "0", "--aad", "0"}},
+	{name: "batch-tokenize-csv", args: []string{"batch-tokenize", "--input", "{RECORDS_CSV}",
+		"--policyName", "ssn"}},
+	{name: "batch-tokenize-csv-file", args: []string{"batch-tokenize",
+		"--input", "{RECORDS_CSV}", "--policyName", "ssn", "--output-file", "{TMP}/tokens.csv"}},
+	{name: "batch-tokenize-renew", args: []string{"batch-tokenize", "--input", "{RECORDS_CSV}",
+		"--policyName", "ssn", "--batch-size", "2", "--concurrency", "1", "--renew-window", "1000000h"}},
+	{name: "batch-tokenize-batch-size", args: []string{"batch-tokenize",
+		"--input", "{RECORDS_CSV}", "--policyName", "ssn", "--batch-size", "1",
+		"--concurrency", "1", "--output-file", "{TMP}/tokens.csv"},
+		show: []string{"tokens.csv", "tokens.csv.checkpoint"}},
+	{name: "batch-tokenize-resume", args: []string{"batch-tokenize",
+		"--input", "{RECORDS_CSV}", "--policyName", "ssn", "--output-file", "{TMP}/tokens.csv",
+		"--resume"},
+		files: map[string]string{
+			// the partial output ends with a record written after the
+			// checkpoint was saved
+			"tokens.csv.partial": "id,tokenData,note,error\n1,{TOKEN},first,\n2,,",
+			"tokens.csv.checkpoint": `{"command": "batch-tokenize", "input": "{RECORDS_CSV}", ` +
+				`"format": "csv", "defaults": {"policyName": "ssn"}, "records": 1, ` +
+				`"failed": 0, "output_size": 45}`},
+		show: []string{"tokens.csv", "tokens.csv.partial", "tokens.csv.checkpoint"}},
+	{name: "batch-tokenize-resume-other-options", args: []string{"batch-tokenize",
+		"--input", "{RECORDS_CSV}", "--policyName", "other", "--output-file",
+		"{TMP}/tokens.csv", "--resume"},
+		files: map[string]string{
+			"tokens.csv.checkpoint": `{"command": "batch-tokenize", "input": "{RECORDS_CSV}", ` +
+				`"format": "csv", "defaults": {"policyName": "ssn"}, "records": 1, ` +
+				`"failed": 0, "output_size": 45}`}},
+	{name: "batch-tokenize-checkpoint-exists", args: []string{"batch-tokenize",
+		"--input", "{RECORDS_CSV}", "--policyName", "ssn", "--output-file", "{TMP}/tokens.csv"},
+		files: map[string]string{"tokens.csv.checkpoint": "{}"}},
+	{name: "batch-tokenize-resume-stdout", args: []string{"batch-tokenize",
+		"--input", "{RECORDS_CSV}", "--policyName", "ssn", "--resume"}},
+	{name: "tokenize-csv", args: []string{"tokenize-csv", "--in", "{CARDS_CSV}",
+		"--columns", "card_number=ssn,ssn=ssn"}},
+	{name: "tokenize-csv-no-header", args: []string{"tokenize-csv", "--in", "{TMP}/cards.tsv",
+		"--columns", "2=ssn", "--delimiter", "tab", "--quote-all", "--out", "{TMP}/tokens.tsv"},
+		files: map[string]string{"cards.tsv": "Alice\t123-45-6789\nBob\t987-65-4321\n"},
+		show:  []string{"tokens.tsv"}},
+	{name: "tokenize-csv-unknown-column", args: []string{"tokenize-csv", "--in", "{CARDS_CSV}",
+		"--columns", "phone=ssn"}},
+	{name: "tokenize-csv-failed-value", args: []string{"tokenize-csv", "--in", "{CARDS_CSV}",
+		"--columns", "ssn=ssn,card_number=unknown", "--out", "{TMP}/tokens.csv"},
+		show: []string{"tokens.csv", "tokens.csv.checkpoint"}},
+	{name: "detokenize-csv", args: []string{"detokenize-csv", "--in", "{TMP}/tokens.csv",
+		"--columns", "ssn=ssn", "--delimiter", ";"},
+		files: map[string]string{"tokens.csv": "id;ssn\n1;{TOKEN}\n2;\n"}},
+	{name: "mask-csv", args: []string{"mask-csv", "--in", "{CARDS_CSV}",
+		"--columns", "card_number=last4", "--columns", "ssn=last4"},
+		setup: [][]string{{"create-mask-policy", "--name", "last4", "--charset", "numeric",
+			"--preservedSuffixLength", "4", "--maskChar", "*"}}},
+	{name: "protect-json", args: []string{"protect-json", "--in", "{DOCS_NDJSON}",
+		"--tokenize", "$.customer.card.number=ssn", "--encrypt", "$.notes={KEY_GUID}"}},
+	{name: "protect-json-wildcard", args: []string{"protect-json", "--in", "{ACCOUNTS_JSON}",
+		"--tokenize", "$.accounts[*]['ssn']=ssn", "--encrypt", "$.accounts[1].owner={KEY_GUID}",
+		"--out", "{TMP}/accounts.json"},
+		show: []string{"accounts.json"}},
+	{name: "unprotect-json", args: []string{"unprotect-json", "--in", "{TMP}/docs.ndjson",
+		"--tokenize", "$.customer.card.number=ssn", "--encrypt", "$.notes={KEY_GUID}",
+		"--out", "{TMP}/restored.ndjson"},
+		setup: [][]string{{"protect-json", "--in", "{DOCS_NDJSON}",
+			"--tokenize", "$.customer.card.number=ssn", "--encrypt", "$.notes={KEY_GUID}",
+			"--out", "{TMP}/docs.ndjson"}},
+		show: []string{"docs.ndjson", "restored.ndjson"}},
+	{name: "protect-json-not-a-string", args: []string{"protect-json", "--in", "{DOCS_NDJSON}",
+		"--tokenize", "$.notes=ssn"}},
+	{name: "protect-json-invalid-path", args: []string{"protect-json", "--in", "{DOCS_NDJSON}",
+		"--tokenize", "$..number=ssn"}},
+	{name: "tokenize-parquet", args: []string{"tokenize-parquet", "--in", "{CARDS_PARQUET}",
+		"--columns", "card=ssn,3=ssn", "--batch-size", "2", "--out", "{TMP}/cards.parquet"},
+		show: []string{"cards.parquet"}},
+	{name: "detokenize-parquet", args: []string{"detokenize-parquet",
+		"--in", "{TMP}/cards.parquet", "--columns", "card=ssn,ssn=ssn",
+		"--out", "{TMP}/restored.parquet"},
+		setup: [][]string{{"tokenize-parquet", "--in", "{CARDS_PARQUET}",
+			"--columns", "card=ssn,ssn=ssn", "--out", "{TMP}/cards.parquet"}},
+		show: []string{"restored.parquet"}},
+	{name: "tokenize-parquet-not-a-string", args: []string{"tokenize-parquet",
+		"--in", "{CARDS_PARQUET}", "--columns", "amount=ssn", "--out", "{TMP}/cards.parquet"},
+		show: []string{"cards.parquet"}},
+	{name: "tokenize-parquet-stdout", args: []string{"tokenize-parquet",
+		"--in", "{CARDS_PARQUET}", "--columns", "card=ssn", "--out", "-"}},
+	{name: "batch-encrypt-jsonl", args: []string{"batch-encrypt", "--input", "{RECORDS_JSONL}",
+		"--keyGuid", "{KEY_GUID}", "--mode", "GCM"}},
+	{name: "batch-encrypt-unknown-format", args: []string{"batch-encrypt",
+		"--input", "{PLAIN_FILE}", "--keyGuid", "{KEY_GUID}", "--mode", "GCM"}},
+	{name: "tokenize", args: []string{"tokenize", "--policyName", "ssn",
+		"--tokenData", "987-65-4321"}},
+	{name: "detokenize", args: []string{"detokenize", "--policyName", "ssn",
+		"--tokenData", "{TOKEN}"}},
+	{name: "create-access-policy", args: []string{"create-access-policy",
+		"--name", "readers", "--role", "reader", "--tokenization_permissions", "detokenize",
+		"--local-user", "alice", "--ad-group", "example.com || CN=Readers",
+		"--tagkey", "team", "--tagvalue", `{"name": "payments"}`}},
+	{name: "create-access-policy-invalid-group", args: []string{"create-access-policy",
+		"--name", "readers", "--role", "reader", "--tokenization_permissions", "detokenize",
+		"--ad-group", "CN=Readers"}},
+	{name: "list-audit-messages", args: []string{"list-audit-messages"}},
+	{name: "list-audit-messages-json", args: []string{"list-audit-messages",
+		"--json-output", "--max-items", "2", "--field", "message"}},
+	{name: "update-audit-settings-json", args: []string{"update-audit-settings",
+		"--retention-days", "90", "--output", "json"}},
+	{name: "renew-json", args: []string{"renew", "--output", "json"}},
+	{name: "update-audit-settings-invalid", args: []string{"update-audit-settings",
+		"--retention-days", "-1"}},
+	{name: "login-pat", noLogin: true, args: []string{"login", "--login-URL", "{LOGIN_URL}",
+		"--cacert", "{CACERT}", "--pat-file", "{PAT_FILE}", "--pat-name", "ci"}},
+	{name: "login-pat-env", noLogin: true, args: []string{"login", "--login-URL",
+		"{LOGIN_URL}", "--cacert", "{CACERT}", "--pat-env", "CI_PAT"},
+		env: map[string]string{"CI_PAT": "{PAT}"}},
+	{name: "login-pat-revoked", noLogin: true, args: []string{"login", "--login-URL",
+		"{LOGIN_URL}", "--cacert", "{CACERT}", "--pat-file", "{REVOKED_PAT_FILE}",
+		"--pat-name", "revoked"}},
+	{name: "login-pat-expired", noLogin: true, args: []string{"login", "--login-URL",
+		"{LOGIN_URL}", "--cacert", "{CACERT}", "--pat-file", "{EXPIRED_PAT_FILE}"}},
+	// later commands authenticate with the personal access token
+	{name: "login-pat-list", noLogin: true, args: []string{"list-personal-access-tokens"},
+		setup: [][]string{{"login", "--login-URL", "{LOGIN_URL}", "--cacert", "{CACERT}",
+			"--pat-file", "{PAT_FILE}"}}},
+	{name: "profile-login", noLogin: true, args: e2eProfileLogin("prod", "{LOGIN_URL}"),
+		show: []string{"cryptocli.data/profiles/prod.json", "cryptocli.data/current_profile"}},
+	// the login URL, CA certificate and user name are those saved
+	{name: "profile-login-saved", noLogin: true, args: []string{"login", "--profile", "staging",
+		"--password", vaulttest.DefaultPassword},
+		setup: e2eProfiles("prod")},
+	{name: "profile-list", noLogin: true, args: []string{"profile", "list"},
+		setup: e2eProfiles("prod")},
+	{name: "profile-list-empty", noLogin: true, args: []string{"profile", "list"}},
+	{name: "profile-show", noLogin: true, args: []string{"profile", "show"},
+		setup: e2eProfiles("staging")},
+	{name: "profile-show-not-found", noLogin: true, args: []string{"profile", "show", "test"},
+		setup: e2eProfiles("staging")},
+	{name: "profile-use", noLogin: true, args: []string{"profile", "use", "staging"},
+		setup: e2eProfiles("prod"), show: []string{"cryptocli.data/current_profile"}},
+	{name: "profile-use-not-found", noLogin: true, args: []string{"profile", "use", "test"},
+		setup: e2eProfiles("prod"), show: []string{"cryptocli.data/current_profile"}},
+	// commands reach the vault of the profile in use with its token
+	{name: "profile-use-renew", noLogin: true, args: []string{"renew"},
+		setup: append(e2eProfiles("prod"), []string{"profile", "use", "staging"})},
+	{name: "profile-env-renew", noLogin: true, args: []string{"renew"},
+		setup: e2eProfiles("prod"), env: map[string]string{ProfileEnv: "staging"}},
+	{name: "profile-flag-renew", noLogin: true, args: []string{"renew", "--profile", "prod"},
+		setup: e2eProfiles("staging"), env: map[string]string{ProfileEnv: "staging"}},
+	{name: "profile-env-not-found", noLogin: true, args: []string{"renew"},
+		setup: e2eProfiles("prod"), env: map[string]string{ProfileEnv: "test"}},
+	{name: "profile-list-json", noLogin: true, args: []string{"profile", "list", "--output",
+		"json"}, setup: e2eProfiles("prod")},
+	{name: "profile-list-table", noLogin: true, args: []string{"profile", "list", "--output",
+		"table", "--query", "[].{name: name, username: username, active: active}"},
+		setup: e2eProfiles("prod")},
+	{name: "profile-list-empty-json", noLogin: true, args: []string{"profile", "list",
+		"--output", "json"}},
+	{name: "profile-show-yaml", noLogin: true, args: []string{"profile", "show", "prod",
+		"--output", "yaml"}, setup: e2eProfiles("staging")},
+	{name: "profile-use-json", noLogin: true, args: []string{"profile", "use", "staging",
+		"--output", "json"}, setup: e2eProfiles("prod")},
+	{name: "profile-delete-json", noLogin: true, args: []string{"profile", "delete", "staging",
+		"--output", "json"}, setup: e2eProfiles("staging")},
+	// the remaining lifetime depends on the clock of the machine
+	{name: "session-status-json", args: []string{"session", "status", "--output", "json",
+		"--query", "{user: user, server: server, token_file: token_file, " +
+			"expires_at: expires_at, expired: expired}"}},
+	{name: "logout-json", args: []string{"logout", "--output", "json"}},
+	{name: "logout-not-logged-in-json", noLogin: true, args: []string{"logout", "--output",
+		"json"}},
+	{name: "profile-delete", noLogin: true, args: []string{"profile", "delete", "staging"},
+		setup: e2eProfiles("staging"),
+		show: []string{"cryptocli.data/profiles/staging.json",
+			"cryptocli.data/profiles/staging.token", "cryptocli.data/current_profile",
+			"cryptocli.data/profiles/prod.json"}},
+}
+
+// e2eRand is the repeatable random source of the mock vault
+func e2eRand() io.Reader {
+	return mathrand.NewChaCha8([32]byte{})
+}
+
+// e2eRandom is the random source of the mock vault. Fixtures which
+// were added after others draw from a source of their own, so that the
+// GUIDs, tokens and IVs of the other tests do not change.
+type e2eRandom struct {
+	mu     sync.Mutex
+	source io.Reader
+}
+
+func (r *e2eRandom) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.source.Read(p)
+}
+
+// swap makes source the random source and returns the one it replaces
+func (r *e2eRandom) swap(source io.Reader) io.Reader {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.source
+	r.source = source
+	return previous
+}
+
+// recorder records the requests to handler
+type recorder struct {
+	handler  http.Handler
+	mu       sync.Mutex
+	requests []string
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	request := r.Method + " " + r.URL.RequestURI() + "\n"
+	indented := &bytes.Buffer{}
+	if json.Indent(indented, body, "", "  ") == nil {
+		request += indented.String() + "\n"
+	} else if len(body) > 0 {
+		request += string(body) + "\n"
+	}
+	rec.mu.Lock()
+	rec.requests = append(rec.requests, request)
+	rec.mu.Unlock()
+
+	rec.handler.ServeHTTP(w, r)
+}
+
+func (rec *recorder) reset() {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.requests = nil
+}
+
+// e2eVault is a mock vault with the fixtures of the tests
+type e2eVault struct {
+	server    *httptest.Server
+	recorder  *recorder
+	random    *e2eRandom
+	variables map[string]string
+	// other records the requests to a second vault, without fixtures,
+	// whose variables are those of the first prefixed with OTHER_
+	other *recorder
+}
+
+func newE2EVault(t *testing.T) *e2eVault {
+	random := &e2eRandom{source: e2eRand()}
+	mock := vaulttest.New(vaulttest.Config{
+		Now:  func() time.Time { return e2eNow },
+		Rand: random})
+	rec := &recorder{handler: mock}
+	server := httptest.NewTLSServer(rec)
+	t.Cleanup(server.Close)
+
+	caCert := filepath.Join(t.TempDir(), "cacert.pem")
+	err := os.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+		Bytes: server.Certificate().Raw}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := server.Listener.Addr().String()
+	v := &e2eVault{server: server, recorder: rec, random: random, variables: map[string]string{
+		"LOGIN_URL": vaulttest.LoginURL(host, mock.VaultID()),
+		"CACERT":    caCert,
+		"HOST":      host,
+	}}
+	v.addFixtures(t, vault.NewClient(host, mock.NewSession(vaulttest.DefaultUsername),
+		server.Client()))
+
+	// the servers of httptest share their certificate, {CACERT}, and
+	// the tokens of the other vault are drawn from a source of its own
+	other := vaulttest.New(vaulttest.Config{
+		VaultID: "00000000-0000-4000-8000-000000000002",
+		Now:     func() time.Time { return e2eNow },
+		Rand:    mathrand.NewChaCha8([32]byte{2})})
+	v.other = &recorder{handler: other}
+	otherServer := httptest.NewTLSServer(v.other)
+	t.Cleanup(otherServer.Close)
+	otherHost := otherServer.Listener.Addr().String()
+	v.variables["OTHER_LOGIN_URL"] = vaulttest.LoginURL(otherHost, other.VaultID())
+	v.variables["OTHER_HOST"] = otherHost
+	return v
+}
+
+// addFixtures adds a key, data encrypted with it, a tokenization
+// policy, a token and personal access tokens
+func (v *e2eVault) addFixtures(t *testing.T, client *vault.Client) {
+	ctx := context.Background()
+	response, err := client.CreateKey(ctx, vault.CreateKeyRequest{Name: "test-key",
+		Cipher: "AES-256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key struct {
+		KeyGUID    string `json:"key_guid"`
+		KeysetGUID string `json:"keyset_guid"`
+	}
+	if err = response.Decode(&key); err != nil {
+		t.Fatal(err)
+	}
+	v.variables["KEY_GUID"] = key.KeyGUID
+	v.variables["KEYSET_GUID"] = key.KeysetGUID
+
+	encrypted, err := client.Encrypt(ctx, vault.EncryptRequest{KeyGUID: key.KeyGUID,
+		Data: base64.StdEncoding.EncodeToString([]byte("secret")), Mode: "GCM"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.variables["CIPHERTEXT"] = encrypted.Data
+	v.variables["IV"] = encrypted.IV
+
+	_, err = client.CreateTokenizationPolicy(ctx, vault.TokenizationPolicy{Name: "ssn",
+		KeyGUID: key.KeyGUID, Charset: "numeric", PreservedSuffixLength: 4, IsNew: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := client.Tokenize(ctx, vault.TokenizeRequest{PolicyName: "ssn",
+		TokenData: "123-45-6789"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.variables["TOKEN"] = token.TokenData
+
+	fixtures := t.TempDir()
+	plain := filepath.Join(fixtures, "plain.txt")
+	data := []byte("The quick brown fox jumps over the lazy dog\n")
+	if err = os.WriteFile(plain, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	v.variables["PLAIN_FILE"] = plain
+	v.variables["SSN_FILE"] = filepath.Join(fixtures, "ssn.txt")
+	if err = os.WriteFile(v.variables["SSN_FILE"], []byte("987-65-4321\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []struct{ variable, name, content string }{
+		{"RECORDS_CSV", "records.csv", "id,tokenData,note\n" +
+			"1,123-45-6789,first\n" +
+			"2,,\"no data, so it fails\"\n" +
+			"3,987-65-4321,third\n"},
+		{"CARDS_CSV", "cards.csv", "name,card_number,ssn\n" +
+			"Alice,4111-1111-1111-1111,123-45-6789\n" +
+			"\"Bob \"\"Jr.\"\", Smith\",,987-65-4321\n"},
+		{"DOCS_NDJSON", "docs.ndjson",
+			`{"id": 1, "customer": {"name": "Alice", "card": {"number": "4111-1111-1111-1111"}}, ` +
+				`"notes": {"vip": true, "text": "<b>prefers email</b>"}}` + "\n" +
+				`{"id":2,"customer":{"card":{"number":null}},"notes":"call \u00e9"}` + "\r\n" +
+				"\n" +
+				`{"id":3,"customer":{"card":{"number":5500000000000004}}}` + "\n"},
+		{"ACCOUNTS_JSON", "accounts.json", "{\n  \"accounts\": [\n" +
+			"    {\"owner\": \"Bob\", \"ssn\": \"123-45-6789\"},\n" +
+			"    {\"owner\": \"Eve\", \"ssn\": \"987-65-4321\"}\n  ]\n}\n"},
+		{"RECORDS_JSONL", "records.jsonl",
+			`{"id":1,"data":"Zmlyc3Q=","meta":{"source":"a"}}` + "\n" +
+				`{"id":2,"data":"c2Vjb25k","iv":"not base64"}` + "\n" +
+				`{"id":3,"data":"dGhpcmQ=","aad":"Y29udGV4dA=="}` + "\n"},
+	} {
+		v.variables[file.variable] = filepath.Join(fixtures, file.name)
+		if err = os.WriteFile(v.variables[file.variable], []byte(file.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v.variables["CARDS_PARQUET"] = filepath.Join(fixtures, "cards.parquet")
+	if err = writeE2EParquet(v.variables["CARDS_PARQUET"]); err != nil {
+		t.Fatal(err)
+	}
+
+	// the vault wraps the data key with IVs of its own
+	previous := v.random.swap(mathrand.NewChaCha8([32]byte{1}))
+	defer v.random.swap(previous)
+	envelope := &bytes.Buffer{}
+	w, err := client.NewEnvelopeWriter(ctx, envelope, vault.EnvelopeOptions{
+		KeyGUID: key.KeyGUID, ChunkSize: 16, Rand: e2eRand()})
+	if err == nil {
+		_, err = w.Write(data)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.variables["ENVELOPE_FILE"] = filepath.Join(fixtures, "plain.txt.enc")
+	v.variables["TAMPERED_FILE"] = filepath.Join(fixtures, "tampered.txt.enc")
+	tampered := append([]byte{}, envelope.Bytes()...)
+	tampered[len(tampered)-1] ^= 1
+	for name, content := range map[string][]byte{"ENVELOPE_FILE": envelope.Bytes(),
+		"TAMPERED_FILE": tampered} {
+		if err = os.WriteFile(v.variables[name], content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// personal access tokens: ci, valid, revoked and expired, each
+	// in a file named after the variable
+	v.random.swap(mathrand.NewChaCha8([32]byte{3}))
+	for _, pat := range []struct {
+		variable, name string
+		expiry         time.Time
+		revoked        bool
+	}{
+		{"PAT", "ci", e2eNow.AddDate(0, 6, 0), false},
+		{"REVOKED_PAT", "revoked", e2eNow.AddDate(0, 6, 0), true},
+		{"EXPIRED_PAT", "expired", e2eNow.Add(-time.Hour), false},
+	} {
+		expiry := pat.expiry.Unix()
+		response, err := client.CreatePersonalAccessToken(ctx,
+			vault.PersonalAccessToken{Name: pat.name, Expiry: &expiry})
+		if err == nil && pat.revoked {
+			_, err = client.UpdatePersonalAccessToken(ctx,
+				vault.PersonalAccessToken{Name: pat.name, Revoked: &pat.revoked})
+		}
+		var created struct {
+			Token string `json:"token"`
+		}
+		if err == nil {
+			err = response.Decode(&created)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		v.variables[pat.variable] = created.Token
+		file := pat.variable + "_FILE"
+		v.variables[file] = filepath.Join(fixtures, strings.ToLower(pat.variable)+".txt")
+		if err = os.WriteFile(v.variables[file], []byte(created.Token+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// e2eParquetRow is a row of the Parquet fixture
+type e2eParquetRow struct {
+	Name   string  `parquet:"name"`
+	Card   *string `parquet:"card,optional"`
+	SSN    string  `parquet:"ssn"`
+	Amount int64   `parquet:"amount"`
+}
+
+// writeE2EParquet writes the Parquet fixture, Snappy compressed with
+// two row groups
+func writeE2EParquet(name string) error {
+	card := "4111-1111-1111-1111"
+	out := &bytes.Buffer{}
+	writer := parquet.NewWriter(out, parquet.SchemaOf(e2eParquetRow{}),
+		parquet.Compression(&parquet.Snappy), parquet.KeyValueMetadata("source", "e2e"))
+	for i, row := range []e2eParquetRow{
+		{Name: "Alice", Card: &card, SSN: "123-45-6789", Amount: 1250},
+		{Name: "Bob", SSN: "987-65-4321", Amount: 80},
+		{Name: "Carol", Card: &card, SSN: "555-12-3456", Amount: 7},
+	} {
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		if i == 1 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(name, out.Bytes(), 0600)
+}
+
+// dumpParquet returns the schema, metadata, codecs and rows of a Parquet file
+// as text
+func dumpParquet(data []byte) ([]byte, error) {
+	file, err := parquet.OpenFile(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	dump := &bytes.Buffer{}
+	fmt.Fprintf(dump, "%s\n", file.Schema())
+	for _, kv := range file.Metadata().KeyValueMetadata {
+		fmt.Fprintf(dump, "metadata %s=%s\n", kv.Key, kv.Value)
+	}
+	columns := file.Schema().Columns()
+	for g, rowGroup := range file.RowGroups() {
+		fmt.Fprintf(dump, "row group %d:", g+1)
+		for i, chunk := range file.Metadata().RowGroups[g].Columns {
+			fmt.Fprintf(dump, " %s=%s", strings.Join(columns[i], "."), chunk.MetaData.Codec)
+		}
+		fmt.Fprintln(dump)
+		rows := rowGroup.Rows()
+		buffer := make([]parquet.Row, 10)
+		for {
+			n, err := rows.ReadRows(buffer)
+			for _, row := range buffer[:n] {
+				fields := []string{}
+				for _, value := range row {
+					field := "null"
+					if !value.IsNull() {
+						field = value.String()
+					}
+					fields = append(fields, field)
+				}
+				fmt.Fprintf(dump, "  %s\n", strings.Join(fields, " | "))
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		rows.Close()
+	}
+	return dump.Bytes(), nil
+}
+
+// expand expands the variables in arg
+func (v *e2eVault) expand(arg string) string {
+	for name, value := range v.variables {
+		arg = strings.ReplaceAll(arg, "{"+name+"}", value)
+	}
+	return arg
+}
+
+// normalize replaces the variables in output with their names, longest
+// value first, and the temporary directory with {TMP}
+func (v *e2eVault) normalize(output, tmp string) string {
+	names := []string{}
+	for name := range v.variables {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(v.variables[names[i]]) > len(v.variables[names[j]])
+	})
+	for _, name := range names {
+		output = strings.ReplaceAll(output, v.variables[name], "{"+name+"}")
+	}
+	return strings.ReplaceAll(output, tmp, "{TMP}")
+}
+
+// resetCommands restores the defaults of all flags and the state the
+// commands keep between runs, as a new process would have them
+func resetCommands() {
+	var reset func(cmd *cobra.Command)
+	reset = func(cmd *cobra.Command) {
+		for _, flags := range []*pflag.FlagSet{cmd.LocalFlags(), cmd.PersistentFlags()} {
+			flags.VisitAll(func(f *pflag.Flag) {
+				if slice, ok := f.Value.(pflag.SliceValue); ok {
+					slice.Replace(nil)
+				} else {
+					f.Value.Set(f.DefValue)
+				}
+				f.Changed = false
+			})
+		}
+		for _, child := range cmd.Commands() {
+			reset(child)
+		}
+	}
+	reset(rootCmd)
+
+	gTokenInfo = tokenInfo{}
+	gClient = nil
+	gTokenKey = nil
+	gClientCertPassword = ""
+	gRenewWindow = DefaultRenewWindow
+	stdinSecretRead = false
+	envelopeRand = e2eRand()
+}
+
+// capture runs f with os.Stdout and os.Stderr redirected and returns
+// what f wrote to them
+func capture(t *testing.T, f func()) (string, string) {
+	read := func(file **os.File) (func() string, error) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		saved := *file
+		*file = w
+		output := make(chan string)
+		go func() {
+			data, _ := io.ReadAll(r)
+			output <- string(data)
+		}()
+		return func() string {
+			w.Close()
+			*file = saved
+			return <-output
+		}, nil
+	}
+	stdout, err := read(&os.Stdout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stderr, err := read(&os.Stderr)
+	if err != nil {
+		stdout()
+		t.Fatal(err)
+	}
+	f()
+	return stdout(), stderr()
+}
+
+// runCommand runs the command of args and returns its output and exit
+// code
+func runCommand(t *testing.T, args []string) (string, string, int) {
+	resetCommands()
+	code := 0
+	stdout, stderr := capture(t, func() {
+		code = run(context.Background(), args)
+	})
+	return stdout, stderr, code
+}
+
+func TestCommands(t *testing.T) {
+	time.Local = time.UTC
+	for _, test := range e2eTests {
+		t.Run(test.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			t.Setenv("HOME", tmp)
+			for _, name := range []string{ProfileEnv, TokenKeyEnv, TokenPassphraseEnv,
+				"HTTPS_PROXY", "https_proxy", "PROXY"} {
+				t.Setenv(name, "")
+			}
+			v := newE2EVault(t)
+			for name, value := range test.env {
+				t.Setenv(name, v.expand(value))
+			}
+
+			if !test.noLogin {
+				_, _, code := runCommand(t, []string{"login",
+					"--login-URL", v.variables["LOGIN_URL"],
+					"--cacert", v.variables["CACERT"],
+					"--username", vaulttest.DefaultUsername,
+					"--password", vaulttest.DefaultPassword})
+				if code != ExitSuccess {
+					t.Fatalf("login failed with exit code %d", code)
+				}
+			}
+			for name, content := range test.files {
+				err := os.WriteFile(filepath.Join(tmp, name), []byte(v.expand(content)), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			expand := func(test []string) []string {
+				args := []string{}
+				for _, arg := range test {
+					args = append(args, strings.ReplaceAll(v.expand(arg), "{TMP}", tmp))
+				}
+				return args
+			}
+			for _, setup := range test.setup {
+				if stdout, _, code := runCommand(t, expand(setup)); code != ExitSuccess {
+					t.Fatalf("%s failed with exit code %d:\n%s", setup[0], code, stdout)
+				}
+			}
+			v.recorder.reset()
+			v.other.reset()
+
+			stdout, stderr, code := runCommand(t, expand(test.args))
+
+			got := &strings.Builder{}
+			fmt.Fprintf(got, "$ cryptocli %s\n", quoteArgs(test.args))
+			fmt.Fprintf(got, "-- requests --\n%s", strings.Join(v.recorder.requests, ""))
+			if len(v.other.requests) > 0 {
+				fmt.Fprintf(got, "-- requests to {OTHER_HOST} --\n%s",
+					strings.Join(v.other.requests, ""))
+			}
+			fmt.Fprintf(got, "-- stdout --\n%s", stdout)
+			fmt.Fprintf(got, "-- stderr --\n%s", stderr)
+			fmt.Fprintf(got, "-- exit code --\n%d\n", code)
+			for _, name := range test.show {
+				content, err := os.ReadFile(filepath.Join(tmp, name))
+				if os.IsNotExist(err) {
+					fmt.Fprintf(got, "-- {TMP}/%s (absent) --\n", name)
+					continue
+				}
+				if err == nil && strings.HasSuffix(name, ".parquet") {
+					content, err = dumpParquet(content)
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				fmt.Fprintf(got, "-- {TMP}/%s --\n%s", name, content)
+			}
+			output := v.normalize(got.String(), tmp)
+
+			golden := filepath.Join("testdata", "e2e", test.name+".golden")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(output), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v - run go test with -update to create it", err)
+			}
+			if output != string(want) {
+				t.Errorf("output differs from %s:\n--- got\n%s\n--- want\n%s",
+					golden, output, want)
+			}
+		})
+	}
+}
+
+// quoteArgs joins args as they are typed in a shell
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		plain := variablePattern.ReplaceAllString(arg, "")
+		if arg == "" || strings.ContainsAny(plain, " \t\"'|&;$*?<>(){}[]\\") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// variablePattern matches the variables of args
+var variablePattern = regexp.MustCompile(`\{[A-Z_]+\}`)
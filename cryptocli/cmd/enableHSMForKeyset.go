@@ -17,61 +17,29 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
 var enableHSMForKeysetCmd = &cobra.Command{
 	Use:   "enable-hsm-for-keyset",
 	Short: "Enable HSM for Keyset",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		keyset_guid, _ := flags.GetString("keyset_guid")
-		params["keyset_guid"] = keyset_guid
-
-		part_label, _ := flags.GetString("part_label")
-		params["part_label"] = part_label
-
-		part_password, _ := flags.GetString("part_password")
-		params["part_password"] = part_password
-
-		jsonParams, err := json.Marshal(params)
+		request := vault.EnableKeysetHSMRequest{}
+		request.KeysetGUID, _ = flags.GetString("keyset_guid")
+		request.PartLabel, _ = flags.GetString("part_label")
+		var err error
+		request.PartPassword, err = CommandSecretFlag(flags, "part_password", "Partition Password", false)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
 
-		endpoint := GetEndPoint("", "1.0", "EnableKeysetHSM")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().EnableKeysetHSM(cmd.Context(), request)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
+			return VaultError(err, "")
 		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if (retStr == "" && retStatus == 404) {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
-		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -82,7 +50,7 @@ func init() {
 	enableHSMForKeysetCmd.Flags().StringP("part_label", "l", "",
 		"Partition Label")
 	enableHSMForKeysetCmd.Flags().StringP("part_password", "p", "",
-		"Partition Password")
+		"Partition Password. Prompted for if omitted."+SecretUsage)
 
 	enableHSMForKeysetCmd.MarkFlagRequired("keyset_guid")
-}
\ No newline at end of file
+}
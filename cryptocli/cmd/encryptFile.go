@@ -0,0 +1,136 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	fileOptionKeyGUID    = "keyGuid"
+	fileOptionIn         = "in"
+	fileOptionOut        = "out"
+	fileOptionWrapMethod = "wrap-method"
+	fileOptionChunkSize  = "chunk-size"
+)
+
+// envelopeRand is the source of the data keys, crypto/rand if nil.
+// Tests set it to make envelopes repeatable.
+var envelopeRand io.Reader
+
+// envelopeError is the error of an envelope operation that failed with
+// err, printed after prefix
+func envelopeError(prefix string, err error) error {
+	var vaultError *vault.Error
+	if errors.As(err, &vaultError) {
+		return VaultError(err, "")
+	}
+	if errors.Is(err, vault.ErrInvalidEnvelope) {
+		return Errorf(ExitError, "\n%s\n%v\n\n", prefix, err)
+	}
+	return OperationError(prefix, err)
+}
+
+var encryptFileCmd = &cobra.Command{
+	Use:   "encrypt-file",
+	Short: "Encrypt a file with a data key wrapped by a key of the vault",
+	Long: `Encrypt a file with a data key wrapped by a key of the vault
+
+The file is encrypted locally in chunks with AES-256-GCM and a data key
+generated for the file. The vault encrypts or wraps the data key with the
+key --keyGuid. The output starts with a header holding the key GUID and
+version and the wrapped data key, so that decrypt-file needs no other
+options. Memory use does not depend on the file size.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		options := vault.EnvelopeOptions{}
+		options.KeyGUID, _ = flags.GetString(fileOptionKeyGUID)
+		options.WrapMethod, _ = flags.GetString(fileOptionWrapMethod)
+		options.ChunkSize, _ = flags.GetInt(fileOptionChunkSize)
+		options.Rand = envelopeRand
+		inName, _ := flags.GetString(fileOptionIn)
+		outName, _ := flags.GetString(fileOptionOut)
+
+		if options.WrapMethod != vault.WrapMethodEncrypt &&
+			options.WrapMethod != vault.WrapMethodWrap {
+			return UsageError("\nInvalid %s %q. Use %s or %s.\n\n", fileOptionWrapMethod,
+				options.WrapMethod, vault.WrapMethodEncrypt, vault.WrapMethodWrap)
+		}
+		if options.ChunkSize <= 0 || options.ChunkSize > vault.MaxChunkSize {
+			return UsageError("\nThe %s must be between 1 and %d\n\n", fileOptionChunkSize,
+				vault.MaxChunkSize)
+		}
+
+		in, err := openInput(inName)
+		if err != nil {
+			return Errorf(ExitError, "\nError opening input file - %v\n\n", err)
+		}
+		defer in.Close()
+		out, err := createOutput(outName)
+		if err != nil {
+			return Errorf(ExitError, "\nError creating output file - %v\n\n", err)
+		}
+
+		envelope, err := GetVault().NewEnvelopeWriter(Idempotent(cmd.Context()), out,
+			options)
+		if err != nil {
+			out.Abort()
+			return envelopeError("Encrypting the file failed:", err)
+		}
+		if _, err = io.Copy(envelope, in); err == nil {
+			err = envelope.Close()
+		}
+		if err != nil {
+			out.Abort()
+			return Errorf(ExitError, "\nEncrypting the file failed:\n%v\n\n", err)
+		}
+		if err = out.Commit(); err != nil {
+			return Errorf(ExitError, "\nError writing output file - %v\n\n", err)
+		}
+
+		if outName != stdStream {
+			header := envelope.Header()
+			fmt.Printf("\nFile encrypted to %s with key %s version %s\n\n", outName,
+				header.KeyGUID, header.KeyVersion)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(encryptFileCmd)
+	encryptFileCmd.Flags().StringP(fileOptionKeyGUID, "k", "",
+		"Key GUID of the key to wrap the data key with")
+	encryptFileCmd.Flags().StringP(fileOptionIn, "i", "",
+		"File to encrypt, or - for stdin")
+	encryptFileCmd.Flags().StringP(fileOptionOut, "o", "",
+		"File to write the encrypted file to, or - for stdout. "+
+			"It is replaced only once encryption succeeded.")
+	encryptFileCmd.Flags().String(fileOptionWrapMethod, vault.WrapMethodEncrypt,
+		"Endpoint of the vault to wrap the data key with: encrypt or wrap")
+	encryptFileCmd.Flags().Int(fileOptionChunkSize, vault.DefaultChunkSize,
+		"Size in bytes of the chunks the file is encrypted in")
+
+	encryptFileCmd.MarkFlagRequired(fileOptionKeyGUID)
+	encryptFileCmd.MarkFlagRequired(fileOptionIn)
+	encryptFileCmd.MarkFlagRequired(fileOptionOut)
+}
@@ -17,61 +17,35 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"fmt"
-	"os"
-	"encoding/json"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
 )
 
 var exportKeyCmd = &cobra.Command{
 	Use:   "export-key",
 	Short: "Export Key",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		key_guid, _ := flags.GetString("key_guid")
 		sha256, _ := flags.GetBool("sha256")
-
 		public_key, _ := flags.GetString("public_key")
-		params["public_key"] = public_key
 
-		jsonParams, err := json.Marshal(params)
-	    if (err != nil) {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-	    }
-
-		endpoint := GetEndPoint("", "1.0", "key/"+key_guid+"/export")
-		if sha256 {
-			endpoint = GetEndPoint2("", "1.0", "key/"+key_guid+"/export/?sha256=yes")
-		}
-		ret, err := DoPostFormData(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		publicKey, err := os.Open(public_key)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
+			return Errorf(ExitError, "Error opening public key file:  %v\n", err)
 		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
+		defer publicKey.Close()
 
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
+		request := vault.ExportKeyRequest{PublicKey: publicKey,
+			PublicKeyName: filepath.Base(public_key), SHA256: sha256}
 
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
+		response, err := GetVault().ExportKey(Idempotent(cmd.Context()), key_guid, request)
+		if err != nil {
+			return VaultError(err, "")
 		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -80,7 +54,7 @@ func init() {
 	exportKeyCmd.Flags().StringP("key_guid", "k", "", "Key GUID")
 	exportKeyCmd.Flags().StringP("public_key", "p", "", "Public Key File")
 	exportKeyCmd.Flags().BoolP("sha256", "s", false,
-    "True if you want to use SHA256 hash for wrapping. Default hash is SHA1")
+		"True if you want to use SHA256 hash for wrapping. Default hash is SHA1")
 
 	exportKeyCmd.MarkFlagRequired("key_guid")
 	exportKeyCmd.MarkFlagRequired("public_key")
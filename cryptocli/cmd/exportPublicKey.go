@@ -17,70 +17,47 @@ limitations under the License.
 package cmd
 
 import (
-    "bytes"
-    "os"
-    "fmt"
-    // external
-    "github.com/spf13/cobra"
+	"fmt"
+	// external
+	"github.com/spf13/cobra"
 )
 
-
 var exportPublicKeyCmd = &cobra.Command{
-    Use:   "export-public-key",
-    Short: "Export public key of asymmetric key",
-    Run: func(cmd *cobra.Command, args []string) {
-        flags := cmd.Flags()
-        key_guid, _ := flags.GetString("key_guid")
-        download, _ := flags.GetBool("download")
-
-        if download == true {
-            endpoint := GetEndPoint2("", "1.0", "key/"+key_guid+"/export/public?download=yes")
-            fname, err := DoGetDownload(endpoint, GetCACertFile(),
-                        AuthTokenKV())
-            if err != nil {
-                fmt.Printf("\nHTTP request failed: %s\n", err)
-                os.Exit(4)
-            } else {
-                fmt.Println("\nSuccessfully downloaded public key " +
-                "as - " + fname + "\n")
-                os.Exit(0)
-            }
-        }
-        endpoint := GetEndPoint("", "1.0", "key/"+key_guid+"/export/public")
-        ret, err := DoGet(endpoint,
-            GetCACertFile(),
-            AuthTokenKV(),
-            nil,
-            "application/json")
-        if err != nil {
-            fmt.Printf("\nHTTP request failed: %s\n", err)
-            os.Exit(4)
-        }
-        retBytes := ret["data"].(*bytes.Buffer)
-        retStatus := ret["status"].(int)
-        retStr := retBytes.String()
-
-        if retStr == "" && retStatus == 404 {
-            fmt.Println("\nAction denied\n")
-            os.Exit(5)
-        }
-
-        retMap := JsonStrToMap(retStr)
-        if _, present := retMap["error"]; present {
-            fmt.Println("\n" + retStr + "\n")
-            os.Exit(3)
-        }
-        fmt.Println("\n" + retStr + "\n")
-        os.Exit(0)
-    },
+	Use:   "export-public-key",
+	Short: "Export public key of asymmetric key",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		key_guid, _ := flags.GetString("key_guid")
+		download, _ := flags.GetBool("download")
+
+		if download {
+			file, err := GetVault().DownloadPublicKey(cmd.Context(), key_guid)
+			if err != nil {
+				return VaultError(err, "")
+			}
+			fname, err := SaveDownload(file)
+			if err != nil {
+				return OperationError("Saving public key failed:", err)
+			}
+			fmt.Println("\nSuccessfully downloaded public key " +
+				"as - " + fname + "\n")
+			return nil
+		}
+
+		response, err := GetVault().ExportPublicKey(cmd.Context(), key_guid)
+		if err != nil {
+			return VaultError(err, "")
+		}
+		return PrintVaultResult(response.JSON())
+	},
 }
 
 func init() {
-    rootCmd.AddCommand(exportPublicKeyCmd)
-    exportPublicKeyCmd.Flags().StringP("key_guid", "k", "",
-	"Key GUID")
-    exportPublicKeyCmd.Flags().BoolP("download", "d", false,
-    "True if you want to download the public key")
+	rootCmd.AddCommand(exportPublicKeyCmd)
+	exportPublicKeyCmd.Flags().StringP("key_guid", "k", "",
+		"Key GUID")
+	exportPublicKeyCmd.Flags().BoolP("download", "d", false,
+		"True if you want to download the public key")
 
 	exportPublicKeyCmd.MarkFlagRequired("key_guid")
 }
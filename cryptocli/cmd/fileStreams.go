@@ -0,0 +1,79 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// stdStream names stdin or stdout in place of a file
+const stdStream = "-"
+
+// openInput opens the file name for reading, or stdin for -
+func openInput(name string) (io.ReadCloser, error) {
+	if name == stdStream {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(name)
+}
+
+// outputFile is written to a temporary file next to the file it
+// replaces on Commit, so that failures leave no partial output. Output
+// to stdout is written as it comes.
+type outputFile struct {
+	*os.File
+	name string
+}
+
+// createOutput creates the output file name, or stdout for -
+func createOutput(name string) (*outputFile, error) {
+	if name == stdStream {
+		return &outputFile{File: os.Stdout}, nil
+	}
+	file, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return nil, err
+	}
+	return &outputFile{File: file, name: name}, nil
+}
+
+// Commit replaces the output file with what was written
+func (f *outputFile) Commit() error {
+	if f.name == "" {
+		return nil
+	}
+	if err := f.File.Close(); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+	if err := os.Rename(f.File.Name(), f.name); err != nil {
+		os.Remove(f.File.Name())
+		return err
+	}
+	return nil
+}
+
+// Abort discards what was written
+func (f *outputFile) Abort() {
+	if f.name == "" {
+		return
+	}
+	f.File.Close()
+	os.Remove(f.File.Name())
+}
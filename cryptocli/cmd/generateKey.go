@@ -17,76 +17,34 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var generateKeyCmd = &cobra.Command{
 	Use:   "generate-key-csr",
 	Short: "Generate Key and CSR",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		cipher, _ := flags.GetString("cipher")
-		params["cipher"] = cipher
-
-		if flags.Changed("public_key") {
-			public_key, _ := flags.GetString("public_key")
-			params["public_key"] = public_key
-		}
-
-		if flags.Changed("keyset_guid") {
-			keyset_guid, _ := flags.GetString("keyset_guid")
-			params["keyset_guid"] = keyset_guid
-		}
-		
-		if flags.Changed("subject_dn") {
-			subject_dn, _ := flags.GetString("subject_dn")
-			params["subject_dn"] = subject_dn
-		}
-
-		if flags.Changed("sans") {
-			sans, _ := flags.GetString("sans")
-			params["sans"] = sans
-		}
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "generate_key_csr")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		request := vault.GenerateKeyRequest{}
+		request.Cipher, _ = flags.GetString("cipher")
+		request.PublicKey, _ = flags.GetString("public_key")
+		request.KeysetGUID, _ = flags.GetString("keyset_guid")
+		request.SubjectDN, _ = flags.GetString("subject_dn")
+		request.SANs, _ = flags.GetString("sans")
+
+		response, err := GetVault().GenerateKeyCSR(cmd.Context(), request)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
+			return VaultError(err, "")
 		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
+		if err := PrintVaultResult(response.JSON()); err != nil {
+			return err
 		}
-
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+		if DefaultOutput() {
+			fmt.Printf("Key successfully geneated\n\n")
 		}
-		fmt.Println("Key successfully geneated\n")
-		os.Exit(0)
+		return nil
 	},
 }
 
@@ -101,6 +59,7 @@ func init() {
 	generateKeyCmd.Flags().StringP("subject_dn", "S", "",
 		"Subject distinguished name required for CSR generation. If not provided then a CSR with default values (CN=kcv.entrust.com, C=US) is created")
 	generateKeyCmd.Flags().StringP("sans", "s", "",
-	"Subject Alternative Names. It will only be considered when subject_dn is provided")
+		"Subject Alternative Names. It will only be considered when subject_dn is provided")
 
-	generateKeyCmd.MarkFlagRequired("cipher")}
\ No newline at end of file
+	generateKeyCmd.MarkFlagRequired("cipher")
+}
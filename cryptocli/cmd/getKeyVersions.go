@@ -17,60 +17,27 @@ limitations under the License.
 package cmd
 
 import (
-	"os"
-	"fmt"
-	"bytes"
-	"encoding/json"
 	"github.com/spf13/cobra"
 )
 
 var GetKeyVersionsCmd = &cobra.Command{
-	Use:	"get-key-versions",
-	Short:	"Get Key Versions",
-	Run: func(cmd *cobra.Command, args []string) {
-	    flags := cmd.Flags()
-	    params := map[string]interface{}{}
-
-	    key_guid, _ := flags.GetString("key_guid")
-
-	    jsonParams, err := json.Marshal(params)
-	    if (err != nil) {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
-	    }
-
-	    endpoint := GetEndPoint("", "1.0", "key/"+key_guid+"/versions")
-	    ret, err := DoGet(endpoint,
-	                        	GetCACertFile(),
-								AuthTokenKV(),
-								jsonParams,
-								"application/json")
-	   if err != nil {
-	    	fmt.Printf("\nHTTP request failed: %s\n", err)
-	    	os.Exit(4)
-	   }
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if (retStr == "" && retStatus == 404) {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
+	Use:   "get-key-versions",
+	Short: "Get Key Versions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		key_guid, _ := flags.GetString("key_guid")
+
+		response, err := GetVault().GetKeyVersions(cmd.Context(), key_guid)
+		if err != nil {
+			return VaultError(err, "")
 		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
 func init() {
-    rootCmd.AddCommand(GetKeyVersionsCmd)
-    GetKeyVersionsCmd.Flags().StringP("key_guid", "k", "", "Key GUID")
+	rootCmd.AddCommand(GetKeyVersionsCmd)
+	GetKeyVersionsCmd.Flags().StringP("key_guid", "k", "", "Key GUID")
 
-    GetKeyVersionsCmd.MarkFlagRequired("key_guid")
+	GetKeyVersionsCmd.MarkFlagRequired("key_guid")
 }
@@ -17,70 +17,32 @@ limitations under the License.
 package cmd
 
 import (
-	"os"
-	"fmt"
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
 var getListOfKeysCmd = &cobra.Command{
-	Use:	"list-of-keys",
-	Short:	"Get List of Keys",
-	Run: func(cmd *cobra.Command, args []string) {
-	    flags := cmd.Flags()
-	    params := map[string]interface{}{}
-
-		if flags.Changed("cryptographic_algorithm") {
-			cryptographic_algorithm, _ := flags.GetString("cryptographic_algorithm")
-			params["cryptographic_algorithm"] = cryptographic_algorithm
-		}
-
-		if flags.Changed("status") {
-			status, _ := flags.GetString("status")
-			params["status"] = status
-		}
+	Use:   "list-of-keys",
+	Short: "Get List of Keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		options := vault.ListKeysOptions{}
+		options.CryptographicAlgorithm, _ = flags.GetString("cryptographic_algorithm")
+		options.Status, _ = flags.GetString("status")
 
 		keyset_guid, _ := flags.GetString("keyset_guid")
-		
-	    jsonParams, err := json.Marshal(params)
-	    if (err != nil) {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
-	    }
-
-	    endpoint := GetEndPoint("", "1.0", "keys/"+keyset_guid)
-	    ret, err := DoGet(endpoint,
-	                        	GetCACertFile(),
-								AuthTokenKV(),
-								jsonParams,
-								"application/json")
-	   if err != nil {
-	    	fmt.Printf("\nHTTP request failed: %s\n", err)
-	    	os.Exit(4)
-	   }
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if (retStr == "" && retStatus == 404) {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
 
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
+		response, err := GetVault().ListKeys(cmd.Context(), keyset_guid, options)
+		if err != nil {
+			return VaultError(err, "")
 		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
 func init() {
-    rootCmd.AddCommand(getListOfKeysCmd)
-    getListOfKeysCmd.Flags().StringP("keyset_guid", "k", "", "Keyset GUID")
-    getListOfKeysCmd.Flags().StringP("cryptographic_algorithm", "c", "", "Cryptographic Algorithm")
-    getListOfKeysCmd.Flags().StringP("status", "s", "", "Key Status")
+	rootCmd.AddCommand(getListOfKeysCmd)
+	getListOfKeysCmd.Flags().StringP("keyset_guid", "k", "", "Keyset GUID")
+	getListOfKeysCmd.Flags().StringP("cryptographic_algorithm", "c", "", "Cryptographic Algorithm")
+	getListOfKeysCmd.Flags().StringP("status", "s", "", "Key Status")
 }
@@ -17,45 +17,18 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var getPlatformInfoCmd = &cobra.Command{
 	Use:   "get-platform-info",
 	Short: "Get Platform Info",
-	Run: func(cmd *cobra.Command, args []string) {
-		params := map[string]interface{}{}
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "GetPlatformInfo")
-		ret, err := DoGet(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response, err := GetVault().GetPlatformInfo(cmd.Context())
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStr := retBytes.String()
-
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+			return VaultError(err, "")
 		}
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -17,51 +17,18 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var getTokenizationSettingsCmd = &cobra.Command{
 	Use:   "get-tokenization-settings",
 	Short: "Get Tokenization settings",
-	Run: func(cmd *cobra.Command, args []string) {
-		params := map[string]interface{}{}
-
-		jsonParams, err := json.Marshal(params)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response, err := GetVault().GetTokenizationSettings(Idempotent(cmd.Context()))
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "GetTokenizationSettings")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nTokenization settings not found\n")
-			os.Exit(5)
-		}
-
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+			return VaultError(err, "Tokenization settings not found")
 		}
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
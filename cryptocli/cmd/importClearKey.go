@@ -17,73 +17,38 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var importClearKeyCmd = &cobra.Command{
 	Use:   "import-clear-key",
 	Short: "Import Clear Key",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		name, _ := flags.GetString("name")
-		params["name"] = name
-
-		if flags.Changed("description") {
-			description, _ := flags.GetString("description")
-			params["description"] = description
-		}
-
-		if flags.Changed("keyset_guid") {
-			keyset_guid, _ := flags.GetString("keyset_guid")
-			params["keyset_guid"] = keyset_guid
-		}
-
-		key_material, _ := flags.GetString("key_material")
-		params["key_material"] = key_material
-
-		cipher, _ := flags.GetString("cipher")
-		params["cipher"] = cipher
-
-		jsonParams, err := json.Marshal(params)
+		request := vault.ImportClearKeyRequest{}
+		request.Name, _ = flags.GetString("name")
+		request.Description, _ = flags.GetString("description")
+		request.KeysetGUID, _ = flags.GetString("keyset_guid")
+		var err error
+		request.KeyMaterial, err = CommandSecretFlag(flags, "key_material", "Key Material", true)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
+		request.Cipher, _ = flags.GetString("cipher")
 
-		endpoint := GetEndPoint("", "1.0", "clear_key_import")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().ImportClearKey(cmd.Context(), request)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
+			return VaultError(err, "")
 		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
+		if err := PrintVaultResult(response.JSON()); err != nil {
+			return err
 		}
-
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+		if DefaultOutput() {
+			fmt.Printf("Key successfully Imported: %s\n\n", request.Name)
 		}
-		fmt.Println("Key successfully Imported:", name,
-			"\n")
-		os.Exit(0)
+		return nil
 	},
 }
 
@@ -98,9 +63,8 @@ func init() {
 	importClearKeyCmd.Flags().StringP("cipher", "c", "",
 		"Cipher for this key")
 	importClearKeyCmd.Flags().StringP("key_material", "m", "",
-		"base64 key material for importing")
+		"base64 key material for importing. Prompted for if omitted."+SecretUsage)
 
-	importClearKeyCmd.MarkFlagRequired("key_material")
 	importClearKeyCmd.MarkFlagRequired("name")
 	importClearKeyCmd.MarkFlagRequired("cipher")
-}
\ No newline at end of file
+}
@@ -17,81 +17,43 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
+	"cli/pkg/vault"
 	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var importKeyCmd = &cobra.Command{
 	Use:   "import-key",
 	Short: "Import Key",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		name, _ := flags.GetString("name")
-		params["name"] = name
-
-		if flags.Changed("description") {
-			description, _ := flags.GetString("description")
-			params["description"] = description
-		}
-
-		if flags.Changed("keyset_guid") {
-			keyset_guid, _ := flags.GetString("keyset_guid")
-			params["keyset_guid"] = keyset_guid
+		request := vault.ImportKeyRequest{}
+		request.Name, _ = flags.GetString("name")
+		request.Description, _ = flags.GetString("description")
+		request.KeysetGUID, _ = flags.GetString("keyset_guid")
+		var err error
+		request.KeyMaterial, err = CommandSecretFlag(flags, "key_material", "Key Material", true)
+		if err != nil {
+			return err
 		}
-
-		key_material, _ := flags.GetString("key_material")
-		params["key_material"] = key_material
-
-		wrapping_key_guid, _ := flags.GetString("wrapping_key_guid")
-		params["wrapping_key_guid"] = wrapping_key_guid
-
-		cipher, _ := flags.GetString("cipher")
-		params["cipher"] = cipher
-
+		request.WrappingKeyGUID, _ = flags.GetString("wrapping_key_guid")
+		request.Cipher, _ = flags.GetString("cipher")
 		if flags.Changed("sha256") {
 			sha256, _ := flags.GetBool("sha256")
-			params["sha256"] = sha256
-		}
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			request.SHA256 = &sha256
 		}
 
-		endpoint := GetEndPoint("", "1.0", "key_import")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().ImportKey(cmd.Context(), request)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
+			return VaultError(err, "")
 		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
+		if err := PrintVaultResult(response.JSON()); err != nil {
+			return err
 		}
-
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+		if DefaultOutput() {
+			fmt.Printf("Key successfully imported: %s\n\n", request.Name)
 		}
-		fmt.Println("Key successfully imported:", name,
-			"\n")
-		os.Exit(0)
+		return nil
 	},
 }
 
@@ -106,14 +68,13 @@ func init() {
 	importKeyCmd.Flags().StringP("cipher", "c", "",
 		"Cipher for this key")
 	importKeyCmd.Flags().StringP("key_material", "m", "",
-		"wrapped key material for importing")
+		"wrapped key material for importing. Prompted for if omitted."+SecretUsage)
 	importKeyCmd.Flags().StringP("wrapping_key_guid", "w", "",
 		"Key GUID to unwrap the key_material")
 	importKeyCmd.Flags().BoolP("sha256", "s", false,
-    	"True if you want to use SHA256 hash for unwrapping. Default hash is SHA1")
+		"True if you want to use SHA256 hash for unwrapping. Default hash is SHA1")
 
-	importKeyCmd.MarkFlagRequired("key_material")
 	importKeyCmd.MarkFlagRequired("name")
 	importKeyCmd.MarkFlagRequired("cipher")
 	importKeyCmd.MarkFlagRequired("wrapping_key_guid")
-}
\ No newline at end of file
+}
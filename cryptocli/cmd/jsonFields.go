@@ -0,0 +1,454 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"cli/pkg/vault"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	jsonOptionTokenize = "tokenize"
+	jsonOptionEncrypt  = "encrypt"
+	jsonOptionFormat   = "format"
+
+	// jsonEncryptedPrefix starts the strings holding encrypted values,
+	// followed by the IV and the ciphertext separated by a colon
+	jsonEncryptedPrefix = "enc:v1:"
+)
+
+// Formats of JSON document files
+const (
+	jsonFormatDocument = "json"
+	jsonFormatLines    = "ndjson"
+)
+
+// jsonFormat returns the format of the document file name: format if
+// given, or else the format its extension says
+func jsonFormat(name, format string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".json":
+			format = jsonFormatDocument
+		case ".ndjson", ".jsonl":
+			format = jsonFormatLines
+		default:
+			return "", fmt.Errorf("Cannot tell the format of %s from its extension. "+
+				"Use --%s json or --%s ndjson", name, jsonOptionFormat, jsonOptionFormat)
+		}
+	}
+	if format != jsonFormatDocument && format != jsonFormatLines {
+		return "", fmt.Errorf("Invalid %s %q. Use json or ndjson", jsonOptionFormat, format)
+	}
+	return format, nil
+}
+
+// jsonDocumentReader reads a JSON file as a single record, or an NDJSON
+// file as a record per line. The records hold the bytes as read, line
+// breaks and blank lines included, so that they are written back as
+// they were.
+type jsonDocumentReader struct {
+	r       *bufio.Reader
+	whole   bool
+	records int
+}
+
+// Read returns the next document, or io.EOF after the last one
+func (r *jsonDocumentReader) Read() (*batchRecord, error) {
+	var data []byte
+	var err error
+	if r.whole {
+		if r.records > 0 {
+			return nil, io.EOF
+		}
+		if data, err = io.ReadAll(r.r); err != nil {
+			return nil, err
+		}
+	} else {
+		// blank lines go with the document that follows them
+		for err == nil && len(bytes.TrimSpace(data)) == 0 {
+			var line []byte
+			line, err = r.r.ReadBytes('\n')
+			data = append(data, line...)
+		}
+		if len(data) == 0 || (err != nil && err != io.EOF) {
+			return nil, err
+		}
+	}
+	r.records++
+	return &batchRecord{raw: data, number: r.records}, nil
+}
+
+// jsonDocumentWriter writes the documents of jsonDocumentReader
+type jsonDocumentWriter struct {
+	w *bufio.Writer
+}
+
+func (w *jsonDocumentWriter) WriteHeader() error {
+	return nil
+}
+
+func (w *jsonDocumentWriter) Write(record *batchRecord) error {
+	_, err := w.w.Write(record.raw)
+	return err
+}
+
+func (w *jsonDocumentWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// jsonField is a field of --tokenize or --encrypt: the values path
+// selects are tokenized with the policy or encrypted with the key
+type jsonField struct {
+	path    jsonPath
+	policy  string
+	keyGUID string
+}
+
+// parseJSONFields parses the path=policy pairs of --tokenize and the
+// path=keyGuid pairs of --encrypt
+func parseJSONFields(tokenize, encrypt []string) ([]jsonField, error) {
+	fields := []jsonField{}
+	for _, option := range []string{jsonOptionTokenize, jsonOptionEncrypt} {
+		pairs := tokenize
+		if option == jsonOptionEncrypt {
+			pairs = encrypt
+		}
+		for _, pair := range pairs {
+			i := strings.LastIndex(pair, "=")
+			if i <= 0 || i == len(pair)-1 {
+				return nil, fmt.Errorf("Invalid --%s %q. Use JSONPath=%s", option, pair,
+					map[string]string{jsonOptionTokenize: "policy",
+						jsonOptionEncrypt: "keyGuid"}[option])
+			}
+			path, err := parseJSONPath(strings.TrimSpace(pair[:i]))
+			if err != nil {
+				return nil, err
+			}
+			field := jsonField{path: path}
+			if option == jsonOptionTokenize {
+				field.policy = strings.TrimSpace(pair[i+1:])
+			} else {
+				field.keyGUID = strings.TrimSpace(pair[i+1:])
+			}
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("No fields given. Use --%s or --%s", jsonOptionTokenize,
+			jsonOptionEncrypt)
+	}
+	return fields, nil
+}
+
+// encodeJSONString returns s as a JSON string, leaving <, > and & as
+// they are
+func encodeJSONString(s string) []byte {
+	encoded := &bytes.Buffer{}
+	encoder := json.NewEncoder(encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return bytes.TrimSuffix(encoded.Bytes(), []byte("\n"))
+}
+
+// jsonFieldCommand tokenizes and encrypts the fields of JSON documents
+// or, to restore, detokenizes and decrypts them
+type jsonFieldCommand struct {
+	restore bool
+}
+
+// jsonValue is a value of a document selected by a field, and its
+// replacement
+type jsonValue struct {
+	record     *batchRecord
+	field      jsonField
+	start, end int
+	result     []byte
+}
+
+// where names the document of record for messages
+func (v *jsonValue) where() string {
+	return fmt.Sprintf("%s of document %d", v.field.path.text, v.record.number)
+}
+
+// tokenizeRequest returns the request to tokenize or detokenize the
+// value, a string or a number, or false for null
+func (c jsonFieldCommand) tokenizeRequest(value *jsonValue) (vault.TokenizeRequest, bool,
+	error) {
+	raw := value.record.raw[value.start:value.end]
+	request := vault.TokenizeRequest{PolicyName: value.field.policy}
+	switch raw[0] {
+	case 'n':
+		return request, false, nil
+	case '"':
+		if err := json.Unmarshal(raw, &request.TokenData); err != nil {
+			return request, false, err
+		}
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		request.TokenData = string(raw)
+	default:
+		return request, false, fmt.Errorf("%s is not a string or a number", value.where())
+	}
+	return request, true, nil
+}
+
+// tokenResult sets the result of value to its token, or its data, in
+// the type of the value
+func (c jsonFieldCommand) tokenResult(value *jsonValue, token string) error {
+	if value.record.raw[value.start] == '"' {
+		value.result = encodeJSONString(token)
+		return nil
+	}
+	var number json.Number
+	if json.Unmarshal([]byte(token), &number) != nil {
+		return fmt.Errorf("%s is a number, but %q is not", value.where(), token)
+	}
+	value.result = []byte(token)
+	return nil
+}
+
+// encryptRequest returns the request to encrypt the value, as JSON, or
+// to decrypt the string holding it
+func (c jsonFieldCommand) encryptRequest(value *jsonValue) (vault.BatchEncryptRequest, error) {
+	raw := value.record.raw[value.start:value.end]
+	request := vault.BatchEncryptRequest{KeyGUID: value.field.keyGUID, Mode: "GCM"}
+	if !c.restore {
+		request.Data = base64.StdEncoding.EncodeToString(raw)
+		return request, nil
+	}
+	var encrypted string
+	json.Unmarshal(raw, &encrypted)
+	parts := strings.Split(strings.TrimPrefix(encrypted, jsonEncryptedPrefix), ":")
+	if !strings.HasPrefix(encrypted, jsonEncryptedPrefix) || len(parts) != 2 {
+		return request, fmt.Errorf("%s is not a value encrypted by protect-json",
+			value.where())
+	}
+	request.IV, request.Data = parts[0], parts[1]
+	return request, nil
+}
+
+// encryptResult sets the result of value to the string holding the
+// encrypted value, or to the decrypted value
+func (c jsonFieldCommand) encryptResult(value *jsonValue, result vault.BatchResult) error {
+	if !c.restore {
+		value.result = encodeJSONString(jsonEncryptedPrefix + result.IV + ":" + result.Data)
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(result.Data)
+	if err != nil || !json.Valid(data) {
+		return fmt.Errorf("%s did not decrypt to a JSON value", value.where())
+	}
+	value.result = data
+	return nil
+}
+
+// jsonValueError returns the error of value if its result failed
+func jsonValueError(verb string, value *jsonValue, result vault.BatchResult) error {
+	if len(result.Error) == 0 || string(result.Error) == "null" {
+		return nil
+	}
+	return Errorf(ExitServerError, "\nFailed to %s %s:\n%s\n\n", verb, value.where(),
+		batchErrorText(result.Error))
+}
+
+// process sends the values of records which fields select and replaces
+// them with their results. A value which fails fails the run.
+func (c jsonFieldCommand) process(ctx context.Context, records []*batchRecord,
+	fields []jsonField) (int, error) {
+	paths := make([]jsonPath, len(fields))
+	for i, field := range fields {
+		paths[i] = field.path
+	}
+
+	tokenized, encrypted := []*jsonValue{}, []*jsonValue{}
+	tokenRequests := []vault.TokenizeRequest{}
+	encryptRequests := []vault.BatchEncryptRequest{}
+	values := map[*batchRecord][]*jsonValue{}
+	for _, record := range records {
+		matches, err := findJSONValues(record.raw, paths)
+		if err != nil {
+			return 0, Errorf(ExitError, "\nInvalid JSON in document %d - %v\n\n",
+				record.number, err)
+		}
+		for _, match := range matches {
+			value := &jsonValue{record: record, field: fields[match.path],
+				start: match.start, end: match.end}
+			if value.field.policy != "" {
+				request, send, err := c.tokenizeRequest(value)
+				if err != nil {
+					return 0, Errorf(ExitError, "\n%v\n\n", err)
+				}
+				if !send {
+					continue
+				}
+				tokenized = append(tokenized, value)
+				tokenRequests = append(tokenRequests, request)
+			} else {
+				request, err := c.encryptRequest(value)
+				if err != nil {
+					return 0, Errorf(ExitError, "\n%v\n\n", err)
+				}
+				encrypted = append(encrypted, value)
+				encryptRequests = append(encryptRequests, request)
+			}
+			values[record] = append(values[record], value)
+		}
+	}
+
+	if len(tokenRequests) > 0 {
+		send, verb := GetVault().BatchTokenize, "tokenize"
+		if c.restore {
+			send, verb = GetVault().BatchDetokenize, "detokenize"
+		}
+		results, err := batchResults(send(ctx, tokenRequests))
+		if err != nil {
+			return 0, VaultError(err, "")
+		}
+		if len(results) != len(tokenRequests) {
+			return 0, InvalidResponseError(fmt.Sprintf(
+				"\nThe vault returned %d results for %d values\n\n", len(results),
+				len(tokenRequests)))
+		}
+		for i, result := range results {
+			if err = jsonValueError(verb, tokenized[i], result); err != nil {
+				return 0, err
+			}
+			if err = c.tokenResult(tokenized[i], result.TokenData); err != nil {
+				return 0, InvalidResponseError(fmt.Sprintf("\n%v\n\n", err))
+			}
+		}
+	}
+	if len(encryptRequests) > 0 {
+		send, verb := GetVault().BatchEncrypt, "encrypt"
+		if c.restore {
+			send, verb = GetVault().BatchDecrypt, "decrypt"
+		}
+		results, err := batchResults(send(ctx, encryptRequests))
+		if err != nil {
+			return 0, VaultError(err, "")
+		}
+		if len(results) != len(encryptRequests) {
+			return 0, InvalidResponseError(fmt.Sprintf(
+				"\nThe vault returned %d results for %d values\n\n", len(results),
+				len(encryptRequests)))
+		}
+		for i, result := range results {
+			if err = jsonValueError(verb, encrypted[i], result); err != nil {
+				return 0, err
+			}
+			if err = c.encryptResult(encrypted[i], result); err != nil {
+				return 0, Errorf(ExitError, "\n%v\n\n", err)
+			}
+		}
+	}
+
+	for record, replaced := range values {
+		sort.Slice(replaced, func(i, j int) bool { return replaced[i].start < replaced[j].start })
+		doc := &bytes.Buffer{}
+		last := 0
+		for _, value := range replaced {
+			doc.Write(record.raw[last:value.start])
+			doc.Write(value.result)
+			last = value.end
+		}
+		doc.Write(record.raw[last:])
+		record.raw = doc.Bytes()
+	}
+	return 0, nil
+}
+
+// addFlags adds the options of JSON document files to cmd
+func (c jsonFieldCommand) addFlags(cmd *cobra.Command) {
+	tokenize, encrypt := "tokenize", "encrypt"
+	if c.restore {
+		tokenize, encrypt = "detokenize", "decrypt"
+	}
+	cmd.Flags().StringP(fileOptionIn, "i", "",
+		"JSON or NDJSON file to read, or - for stdin")
+	cmd.Flags().StringP(fileOptionOut, "o", "",
+		"File to write the documents to, or - for stdout. Defaults to stdout.")
+	cmd.Flags().String(jsonOptionFormat, "",
+		"Format of the documents: json for a single document or ndjson for a document "+
+			"per line. Defaults to the format the extension of the input file says.")
+	cmd.Flags().StringArray(jsonOptionTokenize, []string{},
+		"JSONPath=policy: "+tokenize+" the values the JSONPath selects with the "+
+			"tokenization policy. May be repeated.")
+	cmd.Flags().StringArray(jsonOptionEncrypt, []string{},
+		"JSONPath=keyGuid: "+encrypt+" the values the JSONPath selects with the key. "+
+			"May be repeated.")
+	addBatchEngineFlags(cmd, fileOptionOut, "documents")
+
+	cmd.MarkFlagRequired(fileOptionIn)
+	cmd.MarkFlagsOneRequired(jsonOptionTokenize, jsonOptionEncrypt)
+}
+
+// run processes the fields of the documents of --in
+func (c jsonFieldCommand) run(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	inName, _ := flags.GetString(fileOptionIn)
+	outName, _ := flags.GetString(fileOptionOut)
+	format, _ := flags.GetString(jsonOptionFormat)
+	tokenize, _ := flags.GetStringArray(jsonOptionTokenize)
+	encrypt, _ := flags.GetStringArray(jsonOptionEncrypt)
+	if outName == "" {
+		outName = stdStream
+	}
+
+	fields, err := parseJSONFields(tokenize, encrypt)
+	if err != nil {
+		return UsageError("\n%v\n\n", err)
+	}
+	format, err = jsonFormat(inName, format)
+	if err != nil {
+		return UsageError("\n%v\n\n", err)
+	}
+
+	file := &batchFile{inName: inName, outName: outName, outOption: fileOptionOut,
+		state: &batchCheckpoint{Command: cmd.Name(), Input: inName, Format: format,
+			Options: map[string]string{
+				jsonOptionTokenize: strings.Join(tokenize, "\n"),
+				jsonOptionEncrypt:  strings.Join(encrypt, "\n")}},
+		newReader: func(r io.Reader) (batchSource, error) {
+			return &jsonDocumentReader{r: bufio.NewReader(r),
+				whole: format == jsonFormatDocument}, nil
+		},
+		newWriter: func(w io.Writer) batchSink {
+			return &jsonDocumentWriter{w: bufio.NewWriter(w)}
+		},
+		process: func(ctx context.Context, records []*batchRecord) (int, error) {
+			return c.process(ctx, records, fields)
+		},
+	}
+	if err = file.run(cmd); err != nil {
+		return err
+	}
+	if outName != stdStream {
+		fmt.Printf("\n%d documents written to %s\n\n", file.state.Records, outName)
+	}
+	return nil
+}
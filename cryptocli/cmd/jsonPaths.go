@@ -0,0 +1,226 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonPathStep is a step of a JSONPath: a member name, an array index
+// or a wildcard selecting all members or elements
+type jsonPathStep struct {
+	name     string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// jsonPath is a JSONPath of the subset $, .name, ['name'], [n], .* and
+// [*]
+type jsonPath struct {
+	text  string
+	steps []jsonPathStep
+}
+
+// parseJSONPath parses the JSONPath text
+func parseJSONPath(text string) (jsonPath, error) {
+	path := jsonPath{text: text}
+	if !strings.HasPrefix(text, "$") {
+		return path, fmt.Errorf("JSONPath %s must start with $", text)
+	}
+	for rest := text[1:]; rest != ""; {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			return path, fmt.Errorf("JSONPath %s: recursive descent (..) is not supported",
+				text)
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			step.name = rest[1 : end+1]
+			step.wildcard = step.name == "*"
+			if step.name == "" {
+				return path, fmt.Errorf("JSONPath %s: empty member name", text)
+			}
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := jsonPathBracketEnd(rest)
+			if end < 0 {
+				return path, fmt.Errorf("JSONPath %s: missing ]", text)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			if inner == "*" {
+				step.wildcard = true
+			} else if inner != "" && (inner[0] == '\'' || inner[0] == '"') {
+				name, err := unquoteJSONPathName(inner)
+				if err != nil {
+					return path, fmt.Errorf("JSONPath %s: %v", text, err)
+				}
+				step.name = name
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return path, fmt.Errorf("JSONPath %s: invalid index [%s]", text, inner)
+				}
+				step.index = index
+				step.isIndex = true
+			}
+		default:
+			return path, fmt.Errorf("JSONPath %s: unexpected %q", text, rest[:1])
+		}
+		path.steps = append(path.steps, step)
+	}
+	return path, nil
+}
+
+// jsonPathBracketEnd returns the index of the ] closing the bracket
+// rest starts with, skipping quoted names, or -1
+func jsonPathBracketEnd(rest string) int {
+	var quote byte
+	for i := 1; i < len(rest); i++ {
+		switch c := rest[i]; {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '\'' || c == '"'):
+			quote = c
+		case quote == 0 && c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteJSONPathName returns the name quoted in single or double
+// quotes, in which a backslash escapes the next character
+func unquoteJSONPathName(quoted string) (string, error) {
+	quote := quoted[0]
+	if len(quoted) < 2 || quoted[len(quoted)-1] != quote {
+		return "", fmt.Errorf("unterminated name %s", quoted)
+	}
+	name := &strings.Builder{}
+	for i := 1; i < len(quoted)-1; i++ {
+		if quoted[i] == '\\' && i+1 < len(quoted)-1 {
+			i++
+		}
+		name.WriteByte(quoted[i])
+	}
+	return name.String(), nil
+}
+
+// jsonMatch is a value at doc[start:end] which paths[path] selects
+type jsonMatch struct {
+	path       int
+	start, end int
+}
+
+// findJSONValues returns the values of the JSON document doc which
+// paths select, in the order of the document. A value selected by a
+// path is not searched for the values of other paths.
+func findJSONValues(doc []byte, paths []jsonPath) ([]jsonMatch, error) {
+	if len(bytes.TrimSpace(doc)) == 0 {
+		return nil, nil
+	}
+	// state is a path whose first depth steps lead to the value
+	type state struct {
+		path, depth int
+	}
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.UseNumber()
+	matches := []jsonMatch{}
+
+	var walk func(active []state) error
+	walk = func(active []state) error {
+		selected := -1
+		for _, s := range active {
+			if s.depth < len(paths[s.path].steps) {
+				continue
+			}
+			if selected >= 0 {
+				return fmt.Errorf("%s and %s select the same value", paths[selected].text,
+					paths[s.path].text)
+			}
+			selected = s.path
+		}
+		if selected >= 0 || len(active) == 0 {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return err
+			}
+			if selected >= 0 {
+				end := int(decoder.InputOffset())
+				matches = append(matches, jsonMatch{path: selected, start: end - len(raw),
+					end: end})
+			}
+			return nil
+		}
+
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		delim, ok := token.(json.Delim)
+		if !ok {
+			// a scalar where the paths go on
+			return nil
+		}
+		for i := 0; decoder.More(); i++ {
+			name := ""
+			if delim == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				name, _ = key.(string)
+			}
+			next := []state{}
+			for _, s := range active {
+				step := paths[s.path].steps[s.depth]
+				if step.wildcard || (delim == '{' && !step.isIndex && step.name == name) ||
+					(delim == '[' && step.isIndex && step.index == i) {
+					next = append(next, state{s.path, s.depth + 1})
+				}
+			}
+			if err := walk(next); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+		return err
+	}
+
+	active := make([]state, len(paths))
+	for i := range paths {
+		active[i] = state{path: i}
+	}
+	if err := walk(active); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("data follows the JSON document")
+	}
+	return matches, nil
+}
@@ -17,88 +17,27 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
 var listTokenizationPoliciesCmd = &cobra.Command{
 	Use:   "list-tokenization-policies",
 	Short: "Get list of Tokenization Policies",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-
-		queryString := "?"
-		nameFilter, _ := flags.GetString("nameFilter")
-		if len(nameFilter) > 0 {
-			queryString = queryString + "name=" + nameFilter
-		}
-
-		offset, _ := flags.GetString("offset")
-		if len(offset) > 0 {
-			if len(queryString) > 1 {
-				queryString = queryString + "&"
-			}
-			queryString = queryString + "_offset=" + offset
-		}
-
-		counts, _ := flags.GetString("counts")
-		if len(counts) > 0 {
-			if len(queryString) > 1 {
-				queryString = queryString + "&"
-			}
-			queryString = queryString + "_counts=" + counts
-		}
-
-		limit, _ := flags.GetString("limit")
-		if len(limit) > 0 {
-			if len(queryString) > 1 {
-				queryString = queryString + "&"
-			}
-			queryString = queryString + "_limit=" + limit
-		}
-
-		ordering, _ := flags.GetString("sort")
-		if len(ordering) > 0 {
-			if len(queryString) > 1 {
-				queryString = queryString + "&"
-			}
-			queryString = queryString + "_ordering=" + ordering
-		}
-
-		if len(queryString) < 2 {
-			queryString = ""
-		}
-
-		endpoint := GetEndPoint2("", "1.0", "GetTokenPolicies"+queryString)
-		ret, err := DoGet(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			nil,
-			"application/json")
+		options := vault.PolicyListOptions{}
+		options.Name, _ = flags.GetString("nameFilter")
+		options.Offset, _ = flags.GetString("offset")
+		options.Counts, _ = flags.GetString("counts")
+		options.Limit, _ = flags.GetString("limit")
+		options.Ordering, _ = flags.GetString("sort")
+
+		response, err := GetVault().ListTokenizationPolicies(cmd.Context(), options)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -109,4 +48,4 @@ func init() {
 	listTokenizationPoliciesCmd.Flags().StringP("limit", "l", "", "Limit")
 	listTokenizationPoliciesCmd.Flags().StringP("counts", "c", "", "True if you want count information")
 	listTokenizationPoliciesCmd.Flags().StringP("sort", "s", "", "Sort")
-}
\ No newline at end of file
+}
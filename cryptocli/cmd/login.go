@@ -19,22 +19,45 @@ package cmd
 import (
 	"bufio"
 	"cli/getpasswd"
-	"encoding/json"
+	"cli/pkg/vault"
+	"context"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 const (
-	loginOptionLoginURL  = "login-URL"
-	loginOptionUserName  = "username"
-	loginOptionPassword  = "password"
-	loginOptionCACert    = "cacert"
-	loginOptionTokenFile = "token-file"
+	loginOptionLoginURL           = "login-URL"
+	loginOptionUserName           = "username"
+	loginOptionPassword           = "password"
+	loginOptionCACert             = "cacert"
+	loginOptionTokenFile          = "token-file"
+	loginOptionNode               = "node"
+	loginOptionDiscover           = "discover-nodes"
+	loginOptionSkipHostnameVerify = "skip-hostname-verify"
+	loginOptionPin                = "pin"
+	loginOptionPinCertificate     = "pin-certificate"
+	loginOptionClientCert         = "client-cert"
+	loginOptionClientKey          = "client-key"
+	loginOptionClientCertPassword = "client-cert-password"
+	loginOptionEncryptToken       = "encrypt-token"
+	loginOptionPATFile            = "pat-file"
+	loginOptionPATEnv             = "pat-env"
+	loginOptionPATName            = "pat-name"
+	loginOptionOIDC               = "oidc"
+	loginOptionOIDCIssuer         = "oidc-issuer"
+	loginOptionOIDCClientID       = "oidc-client-id"
+	loginOptionOIDCScope          = "oidc-scope"
+	loginOptionOIDCCACert         = "oidc-cacert"
 )
 
 type accessToken struct {
@@ -43,9 +66,37 @@ type accessToken struct {
 	User       string `json:"user"`
 }
 
+// sessionToken returns the access token of session, which may be nil
+// after a failed login
+func sessionToken(session *vault.Session) accessToken {
+	if session == nil {
+		return accessToken{}
+	}
+	return accessToken{Token: session.AccessToken,
+		Expiration: session.ExpiresAt, User: session.User}
+}
+
 var VaultName = "Cryptographic APIs Vault"
 
-var login_url_pattern = `^https://\d{1,3}(\.\d{1,3}){3}/token/1\.0/Login/[0-9a-fA-F-]+/?$`
+var login_path_pattern = `^/token/1\.0/Login/[0-9a-fA-F-]+/?$`
+
+// parseLoginURL validates loginURL and returns it with the vault
+// server it names as host:port
+func parseLoginURL(loginURL string) (*url.URL, error) {
+	uri, err := url.Parse(loginURL)
+	if err != nil {
+		return nil, err
+	}
+	if uri.Scheme != "https" || uri.Host == "" || uri.User != nil ||
+		uri.RawQuery != "" || uri.Fragment != "" {
+		return nil, fmt.Errorf("not an https URL of the form https://<host>[:<port>]/...")
+	}
+	if matched, _ := regexp.MatchString(login_path_pattern, uri.Path); !matched {
+		return nil, fmt.Errorf("invalid path %q", uri.Path)
+	}
+	uri.Host, err = NormalizeServer(uri.Host, DefaultServerPort)
+	return uri, err
+}
 
 func getCredentials(prefix, user, password string) (string, string) {
 
@@ -71,78 +122,404 @@ func getCredentials(prefix, user, password string) (string, string) {
 	return user, password
 }
 
-func loginAPI(cmd *cobra.Command, args []string) {
+func loginAPI(cmd *cobra.Command, args []string) error {
 	flags := cmd.Flags()
-	params := map[string]interface{}{}
 
 	username, _ := flags.GetString(loginOptionUserName)
-	password, _ := flags.GetString(loginOptionPassword)
-	if password == "" || username == "" {
+	password, err := CommandSecretFlag(flags, loginOptionPassword, "", false)
+	if err != nil {
+		return err
+	}
+	loginURL, _ := flags.GetString(loginOptionLoginURL)
+	cacert, _ := flags.GetString(loginOptionCACert)
+
+	// log into the profile in use, defaulting to its saved settings
+	var prof profile
+	if gAccessTokenFile == "" {
+		if prof.Name = resolveProfile(); prof.Name != "" {
+			saved, err := loadProfile(prof.Name)
+			if err == nil {
+				prof = saved
+			} else if err = validateProfileName(prof.Name); err != nil {
+				return Errorf(ExitUsage, "\n%v\n", err)
+			}
+		}
+	}
+	if username == "" {
+		username = prof.Username
+	}
+	if loginURL == "" {
+		loginURL = prof.LoginURL
+	}
+	if cacert == "" {
+		cacert = prof.CACertFile
+	}
+	missing := []string{}
+	if cacert == "" {
+		missing = append(missing, strconv.Quote(loginOptionCACert))
+	}
+	if loginURL == "" {
+		missing = append(missing, strconv.Quote(loginOptionLoginURL))
+	}
+	useOIDC, _ := flags.GetBool(loginOptionOIDC)
+	oidc := oidcSettings{}
+	if useOIDC {
+		oidc.Issuer, _ = flags.GetString(loginOptionOIDCIssuer)
+		oidc.ClientID, _ = flags.GetString(loginOptionOIDCClientID)
+		oidc.Scope, _ = flags.GetString(loginOptionOIDCScope)
+		oidc.CACertFile, _ = flags.GetString(loginOptionOIDCCACert)
+		if oidc.Issuer == "" {
+			oidc.Issuer = prof.OIDCIssuer
+		}
+		if oidc.ClientID == "" {
+			oidc.ClientID = prof.OIDCClientID
+		}
+		if oidc.Issuer == "" {
+			missing = append(missing, strconv.Quote(loginOptionOIDCIssuer))
+		}
+		if oidc.ClientID == "" {
+			missing = append(missing, strconv.Quote(loginOptionOIDCClientID))
+		}
+	}
+	if len(missing) > 0 {
+		return Errorf(ExitUsage, "\nrequired flag(s) %s not set\n", strings.Join(missing, ", "))
+	}
+
+	patFile, _ := flags.GetString(loginOptionPATFile)
+	patEnv, _ := flags.GetString(loginOptionPATEnv)
+	pat := ""
+	if useOIDC && (patFile != "" || patEnv != "") {
+		return Errorf(ExitUsage, "\n--%s cannot be combined with a personal access token\n",
+			loginOptionOIDC)
+	}
+	if patFile != "" || patEnv != "" {
+		var err error
+		if pat, err = readPAT(patFile, patEnv); err != nil {
+			return Errorf(ExitError, "\n%v\n", err)
+		}
+	} else if !useOIDC && (password == "" || username == "") {
 		fmt.Printf("\n")
 		username, password = getCredentials("", username, password)
 	}
 
-	params["username"] = username
-	params["password"] = password
+	encryptToken, _ := flags.GetBool(loginOptionEncryptToken)
+	if encryptToken || TokenEncryptionRequested() {
+		if err := NewTokenKey(); err != nil {
+			return Errorf(ExitError, "\n%v\n", err)
+		}
+	}
 
-	loginURL, _ := flags.GetString(loginOptionLoginURL)
-	matched, err := regexp.MatchString(login_url_pattern, loginURL)
-	if err != nil || !matched {
-		fmt.Printf("Invalid %s login URL: %s\nPlease provide a valid login URL for %s\nExpected format: https://<ip>/token/1.0/Login/<vaultid>/\n", VaultName, loginURL, VaultName)
-		os.Exit(1)
+	uri, err := parseLoginURL(loginURL)
+	if err != nil {
+		return Errorf(ExitUsage, "Invalid %s login URL: %s - %v\nPlease provide a valid login URL for %s\n"+
+			"Expected format: https://<host>[:<port>]/token/1.0/Login/<vaultid>/\n"+
+			"where <host> is a host name, an IPv4 address or an IPv6 address in brackets\n",
+			VaultName, loginURL, err, VaultName)
 	}
-	uri, err := url.Parse(loginURL)
+
+	nodes, _ := flags.GetStringArray(loginOptionNode)
+	skipHostnameVerify, _ := flags.GetBool(loginOptionSkipHostnameVerify)
+	info := tokenInfo{
+		Server:             uri.Host,
+		CACertFile:         cacert,
+		Servers:            []string{uri.Host},
+		SkipHostnameVerify: skipHostnameVerify}
+	info.Servers, err = appendNodes(info.Servers, nodes...)
 	if err != nil {
-		fmt.Printf("Invalid URL %s - %v\n", loginURL, err)
-		os.Exit(2)
+		return Errorf(ExitError, "\nInvalid --%s - %v\n", loginOptionNode, err)
+	}
+
+	pins, _ := flags.GetStringArray(loginOptionPin)
+	for _, pin := range pins {
+		pin, err = NormalizePin(pin)
+		if err != nil {
+			return Errorf(ExitError, "\n%v\n", err)
+		}
+		info.Pins = append(info.Pins, pin)
 	}
 
-	jsonParams, err := json.Marshal(params)
+	info.ClientCertFile, _ = flags.GetString(loginOptionClientCert)
+	info.ClientKeyFile, _ = flags.GetString(loginOptionClientKey)
+	gClientCertPassword, err = CommandSecretFlag(flags, loginOptionClientCertPassword, "", false)
 	if err != nil {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(2)
+		return err
+	}
+	if info.ClientKeyFile != "" && info.ClientCertFile == "" {
+		return Errorf(ExitError, "\n--%s requires --%s\n", loginOptionClientKey, loginOptionClientCert)
+	}
+	// later commands may run from another directory
+	for _, file := range []*string{&info.ClientCertFile, &info.ClientKeyFile} {
+		if *file != "" {
+			*file, _ = filepath.Abs(*file)
+		}
+	}
+	config, err := clientConfig(info)
+	if err != nil {
+		return Errorf(ExitError, "\n%v\n", err)
+	}
+
+	// pin the node certificates before sending credentials to them
+	if pinCertificate, _ := flags.GetBool(loginOptionPinCertificate); pinCertificate {
+		pins, err := trustOnFirstUse(cmd.Context(), info.nodes(), config)
+		if err != nil {
+			return err
+		}
+		info.Pins = append(info.Pins, pins...)
+		config.Pins = info.Pins
+	}
+
+	if err = InitClient(config); err != nil {
+		return Errorf(ExitError, "\n%v\n", err)
 	}
 
-	cacert, _ := flags.GetString(loginOptionCACert)
 	var respData accessToken
-	_, err = DoPost2(loginURL, cacert, map[string]string{},
-		jsonParams, ContentTypeJSON, &respData, nil)
+	if pat != "" {
+		patName, _ := flags.GetString(loginOptionPATName)
+		respData, err = patLogin(cmd.Context(), uri.Host, pat, patName)
+		info.AuthMethod = authMethodPAT
+	} else if useOIDC {
+		respData, err = oidcLogin(cmd.Context(), uri.String(), config, oidc)
+		info.AuthMethod = authMethodOIDC
+	} else {
+		var session *vault.Session
+		session, err = NewVault(uri.Host, "").Login(cmd.Context(), path.Base(uri.Path),
+			vault.LoginRequest{Username: username, Password: password})
+		respData = sessionToken(session)
+	}
 	if err != nil {
-		fmt.Printf("\nLogin failed:\n%v\n", err)
-		os.Exit(3)
+		return OperationError("Login failed:", err)
+	}
+	info.AccessToken = respData.Token
+	info.ExpiresAt = respData.Expiration
+	info.User = respData.User
+	if info.User == "" {
+		info.User = username
+	}
+
+	if discover, _ := flags.GetBool(loginOptionDiscover); discover {
+		discovered, err := discoverNodes(cmd.Context(), info)
+		if err == nil {
+			var servers []string
+			if servers, err = appendNodes(info.Servers, discovered...); err == nil {
+				info.Servers = servers
+			}
+		}
+		if err != nil {
+			fmt.Printf("\nCluster node discovery failed, continuing with "+
+				"the given nodes - %v\n", err)
+		}
 	}
 
-	tokenFile, _ := flags.GetString(loginOptionTokenFile)
-	tokenFile, err = SaveAccessToken(tokenFile, respData.Token, uri.Hostname(), cacert)
+	tokenFile := gAccessTokenFile
+	if prof.Name != "" {
+		prof.LoginURL = loginURL
+		prof.CACertFile = cacert
+		prof.Username = username
+		if useOIDC {
+			prof.OIDCIssuer = oidc.Issuer
+			prof.OIDCClientID = oidc.ClientID
+		}
+		if err = saveProfile(prof); err != nil {
+			return Errorf(ExitError, "\nError saving profile %s - %v\n", prof.Name, err)
+		}
+		tokenFile, _ = profileTokenFile(prof.Name)
+	}
+	tokenFile, err = SaveAccessToken(tokenFile, info)
 	if err != nil {
-		fmt.Printf("\nError saving access token to %s - %v\n", tokenFile, err)
-		os.Exit(4)
+		return Errorf(ExitError, "\nError saving access token to %s - %v\n", tokenFile, err)
 	}
 
-	fmt.Printf("\nLogin is successful.\nThe login session expires at %s.\n",
-		formatLoginExpiration(respData.Expiration))
+	expiresAt := ""
+	if respData.Expiration != "" || info.AuthMethod != authMethodPAT {
+		if expiresAt, err = formatLoginExpiration(respData.Expiration); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("\nLogin is successful.\n")
+	switch {
+	case info.AuthMethod != authMethodPAT:
+		fmt.Printf("The login session expires at %s.\n", expiresAt)
+	case respData.Expiration != "":
+		fmt.Printf("The personal access token expires at %s.\n", expiresAt)
+	default:
+		fmt.Printf("Give --%s to check the expiry of the personal access token.\n",
+			loginOptionPATName)
+	}
+	if len(info.Servers) > 1 {
+		fmt.Printf("Cluster nodes: %s\n", strings.Join(info.Servers, ", "))
+	}
+	if prof.Name != "" {
+		fmt.Printf("Profile %s is saved.\n", prof.Name)
+	}
 	fmt.Printf("Access Token is saved in %s.\n", tokenFile)
 	fmt.Printf("\n")
-	os.Exit(0)
+	return nil
+}
+
+// trustOnFirstUse shows the certificate of every node and returns the SPKI pins of those the user confirms to trust
+func trustOnFirstUse(ctx context.Context, nodes []string,
+	config ClientConfig) ([]string, error) {
+	pins := []string{}
+	reader := bufio.NewReader(os.Stdin)
+	for _, node := range nodes {
+		cert, err := FetchPeerCertificate(ctx, config, node)
+		if err != nil {
+			return nil, Errorf(ExitError, "\nError getting certificate of %s - %v\n", node, err)
+		}
+		pin := SPKIPin(cert)
+
+		fmt.Printf("\nCertificate presented by %s:\n", node)
+		fmt.Printf("  Subject    : %s\n", cert.Subject)
+		fmt.Printf("  Issuer     : %s\n", cert.Issuer)
+		fmt.Printf("  Expires    : %s\n", cert.NotAfter.Format(time.RFC1123))
+		fmt.Printf("  SPKI pin   : sha256/%s\n", pin)
+		fmt.Printf("Trust and pin this certificate's public key? [y/N]: ")
+
+		answer, _ := reader.ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			return nil, Errorf(ExitError, "\nCertificate of %s not trusted. Login aborted.\n\n", node)
+		}
+		pins = append(pins, pin)
+	}
+	return pins, nil
+}
+
+// appendNodes appends the nodes not already present to list, as
+// host:port with the port of the first node in list if they have none
+func appendNodes(list []string, nodes ...string) ([]string, error) {
+	defaultPort := DefaultServerPort
+	if len(list) > 0 {
+		if _, port, err := net.SplitHostPort(list[0]); err == nil {
+			defaultPort = port
+		}
+	}
+
+	for _, node := range nodes {
+		node = strings.TrimSpace(node)
+		if node == "" {
+			continue
+		}
+		node, err := NormalizeServer(node, defaultPort)
+		if err != nil {
+			return list, err
+		}
+		present := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, node) {
+				present = true
+				break
+			}
+		}
+		if !present {
+			list = append(list, node)
+		}
+	}
+	return list, nil
+}
+
+// discoverNodes returns the cluster node addresses reported by
+// GetPlatformInfo. Nodes are looked up in a "nodes" array whose
+// entries are either addresses or objects with an ip, ip_address,
+// hostname or host field.
+func discoverNodes(ctx context.Context, info tokenInfo) ([]string, error) {
+	platformInfo, err := NewVault(info.Server, info.AccessToken).GetPlatformInfo(
+		Idempotent(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []string{}
+	for _, entry := range platformInfo.Nodes {
+		switch node := entry.(type) {
+		case string:
+			nodes = append(nodes, node)
+		case map[string]interface{}:
+			for _, key := range []string{"ip", "ip_address", "hostname", "host"} {
+				if address, ok := node[key].(string); ok && address != "" {
+					nodes = append(nodes, address)
+					break
+				}
+			}
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes found in platform information")
+	}
+	return nodes, nil
 }
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Login to Tokenization Vault",
-	Run:   loginAPI,
+	RunE:  loginAPI,
 }
 
 func init() {
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.Flags().StringP(loginOptionCACert, "C", "",
-		"CA Certificate to verify Tokenization Vault server with")
+		"CA Certificate to verify Tokenization Vault server with. "+
+			"Required unless saved in the profile in use.")
 	loginCmd.Flags().StringP(loginOptionUserName, "u", "",
 		"Login username. You will be prompted to enter if not provided.")
 	loginCmd.Flags().StringP(loginOptionPassword, "p", "",
-		"Login password. You will be prompted to enter if not provided.")
+		"Login password. You will be prompted to enter if not provided."+SecretUsage)
 	loginCmd.Flags().StringP(loginOptionLoginURL, "l", "",
-		"API Login URL")
-
-	loginCmd.MarkFlagRequired(loginOptionCACert)
-	loginCmd.MarkFlagRequired(loginOptionLoginURL)
+		"API Login URL. Required unless saved in the profile in use.")
+	loginCmd.Flags().StringArrayP(loginOptionNode, "n", []string{},
+		"Address of another node of the vault cluster. Commands fail "+
+			"over to the nodes in the given order when the node in use "+
+			"is unreachable. This option is repeatable.")
+	loginCmd.Flags().Bool(loginOptionDiscover, false,
+		"Discover the vault cluster nodes from the platform information "+
+			"after logging in")
+	loginCmd.Flags().Bool(loginOptionSkipHostnameVerify, false,
+		"Accept a vault certificate issued by the CA even if it is not "+
+			"issued for the vault host name or IP address. Not recommended.")
+	loginCmd.Flags().StringArray(loginOptionPin, []string{},
+		"SPKI SHA-256 pin (sha256/<base64>) of a vault certificate public "+
+			"key. Only vault certificates with a pinned public key are "+
+			"accepted. This option is repeatable.")
+	loginCmd.Flags().Bool(loginOptionPinCertificate, false,
+		"Show the certificate of each vault node and, on confirmation, "+
+			"pin its public key for this and later sessions")
+	loginCmd.Flags().String(loginOptionClientCert, "",
+		"Client certificate presented to the vault for mutual TLS "+
+			"authentication in this and later sessions. Either a PEM "+
+			"certificate (chain) or a PKCS#12 (.p12/.pfx) bundle including "+
+			"the private key.")
+	loginCmd.Flags().String(loginOptionClientKey, "",
+		"PEM private key of the client certificate, if not included in "+
+			"the client certificate file")
+	loginCmd.Flags().String(loginOptionClientCertPassword, "",
+		"Password of the PKCS#12 client certificate. Later commands read it "+
+			"from the "+ClientCertPasswordEnv+" environment variable or "+
+			"prompt for it."+SecretUsage)
+	loginCmd.Flags().String(loginOptionPATFile, "",
+		"Log in with the personal access token read from this file instead "+
+			"of a username and password")
+	loginCmd.Flags().String(loginOptionPATEnv, "",
+		"Log in with the personal access token read from this environment "+
+			"variable instead of a username and password")
+	loginCmd.Flags().String(loginOptionPATName, "",
+		"Name of the personal access token. If given, its expiry is saved "+
+			"and login fails if it is revoked or expired.")
+	loginCmd.Flags().Bool(loginOptionOIDC, false,
+		"Log in through an OpenID Connect identity provider with the "+
+			"device authorization grant instead of a username and password")
+	loginCmd.Flags().String(loginOptionOIDCIssuer, "",
+		"Issuer URL of the OpenID Connect identity provider")
+	loginCmd.Flags().String(loginOptionOIDCClientID, "",
+		"Client ID of cryptocli at the OpenID Connect identity provider")
+	loginCmd.Flags().String(loginOptionOIDCScope, oidcDefaultScope,
+		"Scopes requested from the OpenID Connect identity provider")
+	loginCmd.Flags().String(loginOptionOIDCCACert, "",
+		"CA Certificate to verify the OpenID Connect identity provider with, "+
+			"if not issued by a CA trusted by the system")
+	loginCmd.Flags().Bool(loginOptionEncryptToken, false,
+		"Encrypt the saved access token with a key derived from a "+
+			"passphrase, read from the "+TokenPassphraseEnv+" environment "+
+			"variable or prompted for. Later commands need the same "+
+			"passphrase. Implied if "+TokenPassphraseEnv+" or "+TokenKeyEnv+
+			" (a base64 encoded AES-256 key) is set.")
 }
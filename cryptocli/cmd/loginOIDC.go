@@ -0,0 +1,311 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	authMethodOIDC = "oidc"
+
+	oidcDefaultScope    = "openid profile email"
+	oidcDeviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	oidcDefaultInterval = 5 * time.Second
+	oidcSlowDownBackoff = 5 * time.Second
+)
+
+// oidcSettings identifies the identity provider and the client
+// cryptocli is registered as
+type oidcSettings struct {
+	Issuer     string
+	ClientID   string
+	Scope      string
+	CACertFile string // verifies the identity provider, system roots if empty
+}
+
+// oidcProviderMetadata holds the endpoints of the OpenID Connect
+// discovery document used by the device authorization grant
+type oidcProviderMetadata struct {
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+}
+
+// deviceAuthorization is the device authorization response of RFC 8628
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// oidcNow and oidcSleep are the clock of the device authorization
+// grant, replaced by tests so as not to wait for the polling interval
+var (
+	oidcNow   = time.Now
+	oidcSleep = func(ctx context.Context, d time.Duration) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d):
+			return nil
+		}
+	}
+)
+
+// isLoopback reports whether host names this machine, where a stand-in
+// identity provider may be served over plain HTTP
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// newOIDCHTTPClient returns the client for requests to the identity
+// provider. It shares the proxy settings, but not the trusted CA, of
+// the vault client.
+func newOIDCHTTPClient(config ClientConfig, settings oidcSettings) (*http.Client, error) {
+	proxy, err := proxyFunc(config)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{}
+	if settings.CACertFile != "" {
+		caCert, err := os.ReadFile(settings.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading OIDC CA certificate - %v", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("No PEM certificates found in %s", settings.CACertFile)
+		}
+	}
+	tr := &http.Transport{
+		Proxy:               proxy,
+		TLSClientConfig:     tlsConfig,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &http.Client{Transport: tr, Timeout: 30 * time.Second}, nil
+}
+
+// validateIssuer requires HTTPS, except for a loopback issuer
+func validateIssuer(issuer string) (*url.URL, error) {
+	uri, err := url.Parse(issuer)
+	if err != nil {
+		return nil, err
+	}
+	if uri.Scheme != "https" && !(uri.Scheme == "http" && isLoopback(uri.Hostname())) {
+		return nil, fmt.Errorf("OIDC issuer must be an https URL")
+	}
+	return uri, nil
+}
+
+func oidcDecode(response *http.Response, v interface{}) error {
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid response (HTTP status %d) - %v",
+			response.StatusCode, err)
+	}
+	return nil
+}
+
+// oidcDiscover reads the endpoints from the discovery document of issuer
+func oidcDiscover(ctx context.Context, httpClient *http.Client,
+	issuer string) (oidcProviderMetadata, error) {
+	var metadata oidcProviderMetadata
+	uri, err := validateIssuer(issuer)
+	if err != nil {
+		return metadata, err
+	}
+	uri.Path = strings.TrimSuffix(uri.Path, "/") + "/.well-known/openid-configuration"
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return metadata, err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return metadata, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return metadata, fmt.Errorf("OIDC discovery at %s failed - HTTP status %d",
+			uri, response.StatusCode)
+	}
+	if err := oidcDecode(response, &metadata); err != nil {
+		return metadata, fmt.Errorf("OIDC discovery at %s failed - %v", uri, err)
+	}
+	if metadata.DeviceAuthorizationEndpoint == "" || metadata.TokenEndpoint == "" {
+		return metadata, fmt.Errorf("Identity provider %s does not support the "+
+			"device authorization grant", issuer)
+	}
+	return metadata, nil
+}
+
+func oidcPostForm(ctx context.Context, httpClient *http.Client, endpoint string,
+	form url.Values) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	return httpClient.Do(request)
+}
+
+// oidcDeviceLogin runs the OAuth 2.0 device authorization grant of
+// RFC 8628: it shows the user where to authorize cryptocli, then polls
+// the token endpoint until the identity provider issues an ID token.
+func oidcDeviceLogin(ctx context.Context, config ClientConfig,
+	settings oidcSettings) (string, error) {
+	httpClient, err := newOIDCHTTPClient(config, settings)
+	if err != nil {
+		return "", err
+	}
+	metadata, err := oidcDiscover(ctx, httpClient, settings.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	scope := settings.Scope
+	if scope == "" {
+		scope = oidcDefaultScope
+	}
+	response, err := oidcPostForm(ctx, httpClient, metadata.DeviceAuthorizationEndpoint,
+		url.Values{"client_id": {settings.ClientID}, "scope": {scope}})
+	if err != nil {
+		return "", err
+	}
+	var authorization deviceAuthorization
+	var authError oidcTokenResponse
+	if response.StatusCode != http.StatusOK {
+		if err := oidcDecode(response, &authError); err != nil || authError.Error == "" {
+			return "", fmt.Errorf("Device authorization failed - HTTP status %d",
+				response.StatusCode)
+		}
+		return "", fmt.Errorf("Device authorization failed - %s",
+			strings.TrimSpace(authError.Error+" "+authError.ErrorDescription))
+	}
+	if err := oidcDecode(response, &authorization); err != nil {
+		return "", fmt.Errorf("Device authorization failed - %v", err)
+	}
+	if authorization.DeviceCode == "" || authorization.VerificationURI == "" {
+		return "", fmt.Errorf("Device authorization failed - incomplete response")
+	}
+
+	fmt.Printf("\nTo log in, visit %s and enter the code %s\n",
+		authorization.VerificationURI, authorization.UserCode)
+	if authorization.VerificationURIComplete != "" {
+		fmt.Printf("or visit %s\n", authorization.VerificationURIComplete)
+	}
+	fmt.Printf("Waiting for authorization...\n")
+
+	interval := oidcDefaultInterval
+	if authorization.Interval > 0 {
+		interval = time.Duration(authorization.Interval) * time.Second
+	}
+	deadline := oidcNow().Add(time.Duration(authorization.ExpiresIn) * time.Second)
+	if authorization.ExpiresIn <= 0 {
+		deadline = oidcNow().Add(10 * time.Minute)
+	}
+
+	form := url.Values{
+		"grant_type":  {oidcDeviceGrantType},
+		"device_code": {authorization.DeviceCode},
+		"client_id":   {settings.ClientID}}
+	for {
+		if err := oidcSleep(ctx, interval); err != nil {
+			return "", err
+		}
+		if oidcNow().After(deadline) {
+			return "", fmt.Errorf("The device code expired before authorization")
+		}
+
+		response, err := oidcPostForm(ctx, httpClient, metadata.TokenEndpoint, form)
+		if err != nil {
+			return "", err
+		}
+		var token oidcTokenResponse
+		if err := oidcDecode(response, &token); err != nil {
+			return "", fmt.Errorf("Token request failed - %v", err)
+		}
+		switch token.Error {
+		case "":
+			if token.IDToken == "" {
+				return "", fmt.Errorf("Identity provider issued no ID token - " +
+					"is the openid scope granted?")
+			}
+			return token.IDToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += oidcSlowDownBackoff
+		case "access_denied":
+			return "", fmt.Errorf("Authorization was denied")
+		case "expired_token":
+			return "", fmt.Errorf("The device code expired before authorization")
+		default:
+			return "", fmt.Errorf("Token request failed - %s",
+				strings.TrimSpace(token.Error+" "+token.ErrorDescription))
+		}
+	}
+}
+
+// oidcLogin logs in through the identity provider and exchanges the
+// ID token for a vault access token at loginURL
+func oidcLogin(ctx context.Context, loginURL string, config ClientConfig,
+	settings oidcSettings) (accessToken, error) {
+	idToken, err := oidcDeviceLogin(ctx, config, settings)
+	if err != nil {
+		return accessToken{}, err
+	}
+
+	uri, err := url.Parse(loginURL)
+	if err != nil {
+		return accessToken{}, err
+	}
+	session, err := NewVault(uri.Host, "").Login(ctx, path.Base(uri.Path),
+		vault.LoginRequest{IDToken: idToken})
+	return sessionToken(session), err
+}
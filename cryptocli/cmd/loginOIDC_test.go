@@ -0,0 +1,235 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault/vaulttest"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testOIDCClientID   = "cryptocli"
+	testOIDCDeviceCode = "device-code"
+	testOIDCUserCode   = "ABCD-EFGH"
+	testOIDCUser       = "alice@example.com"
+)
+
+// testIDToken is an unsigned ID token naming testOIDCUser
+var testIDToken = "eyJhbGciOiJub25lIn0." +
+	base64.RawURLEncoding.EncodeToString([]byte(`{"email":"`+testOIDCUser+`"}`)) + ".sig"
+
+// testIdP is an identity provider serving the device authorization
+// grant. Its token endpoint answers the polls with the errors of
+// responses in turn, an empty one issuing testIDToken, and then keeps
+// answering authorization_pending.
+type testIdP struct {
+	*httptest.Server
+	expiresIn, interval int
+	responses           []string
+
+	mu    sync.Mutex
+	polls int
+}
+
+func newTestIdP(t *testing.T, expiresIn, interval int, responses ...string) *testIdP {
+	idp := &testIdP{expiresIn: expiresIn, interval: interval, responses: responses}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter,
+		r *http.Request) {
+		json.NewEncoder(w).Encode(oidcProviderMetadata{
+			DeviceAuthorizationEndpoint: idp.URL + "/device",
+			TokenEndpoint:               idp.URL + "/token"})
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("client_id") != testOIDCClientID ||
+			r.PostFormValue("scope") != oidcDefaultScope {
+			t.Errorf("device authorization request %v", r.PostForm)
+		}
+		json.NewEncoder(w).Encode(deviceAuthorization{DeviceCode: testOIDCDeviceCode,
+			UserCode: testOIDCUserCode, VerificationURI: idp.URL + "/activate",
+			ExpiresIn: idp.expiresIn, Interval: idp.interval})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("grant_type") != oidcDeviceGrantType ||
+			r.PostFormValue("device_code") != testOIDCDeviceCode ||
+			r.PostFormValue("client_id") != testOIDCClientID {
+			t.Errorf("token request %v", r.PostForm)
+		}
+		idp.mu.Lock()
+		response := "authorization_pending"
+		if idp.polls < len(idp.responses) {
+			response = idp.responses[idp.polls]
+		}
+		idp.polls++
+		idp.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if response == "" {
+			json.NewEncoder(w).Encode(map[string]string{"id_token": testIDToken,
+				"token_type": "Bearer"})
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": response})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+func (idp *testIdP) settings() oidcSettings {
+	return oidcSettings{Issuer: idp.URL, ClientID: testOIDCClientID}
+}
+
+func (idp *testIdP) pollCount() int {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	return idp.polls
+}
+
+// useOIDCClock replaces the clock of the device authorization grant by
+// one which advances as the grant waits, and returns the waits
+func useOIDCClock(t *testing.T) *[]time.Duration {
+	savedNow, savedSleep := oidcNow, oidcSleep
+	t.Cleanup(func() { oidcNow, oidcSleep = savedNow, savedSleep })
+	now := time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC)
+	waits := &[]time.Duration{}
+	oidcNow = func() time.Time { return now }
+	oidcSleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		now = now.Add(d)
+		return ctx.Err()
+	}
+	return waits
+}
+
+func TestOIDCDeviceLogin(t *testing.T) {
+	s := time.Second
+	for _, test := range []struct {
+		name                string
+		expiresIn, interval int
+		responses           []string
+		err                 string // empty if testIDToken is issued
+		waits               []time.Duration
+	}{
+		{"issued", 600, 2, []string{""}, "", []time.Duration{2 * s}},
+		{"pending", 600, 2, []string{"authorization_pending", "authorization_pending", ""},
+			"", []time.Duration{2 * s, 2 * s, 2 * s}},
+		{"default-interval", 600, 0, []string{"authorization_pending", ""}, "",
+			[]time.Duration{5 * s, 5 * s}},
+		// every slow_down adds 5s to the interval
+		{"slow-down", 600, 1, []string{"slow_down", "authorization_pending", "slow_down", ""},
+			"", []time.Duration{1 * s, 6 * s, 6 * s, 11 * s}},
+		{"access-denied", 600, 1, []string{"authorization_pending", "access_denied"},
+			"Authorization was denied", []time.Duration{s, s}},
+		{"expired-token", 600, 1, []string{"expired_token"},
+			"The device code expired before authorization", []time.Duration{s}},
+		// the device code expires after 10s, before the third poll
+		{"deadline", 10, 4, nil, "The device code expired before authorization",
+			[]time.Duration{4 * s, 4 * s, 4 * s}},
+		{"other-error", 600, 1, []string{"invalid_client"},
+			"Token request failed - invalid_client", []time.Duration{s}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			waits := useOIDCClock(t)
+			idp := newTestIdP(t, test.expiresIn, test.interval, test.responses...)
+
+			var idToken string
+			var err error
+			stdout, _ := capture(t, func() {
+				idToken, err = oidcDeviceLogin(context.Background(), ClientConfig{},
+					idp.settings())
+			})
+			if test.err == "" && (err != nil || idToken != testIDToken) {
+				t.Errorf("got %q, %v, want the ID token", idToken, err)
+			} else if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Errorf("got %q, %v, want error %q", idToken, err, test.err)
+			}
+			if !reflect.DeepEqual(*waits, test.waits) {
+				t.Errorf("waited %v, want %v", *waits, test.waits)
+			}
+			if test.err == "" && idp.pollCount() != len(test.responses) {
+				t.Errorf("polled %d times, want %d", idp.pollCount(), len(test.responses))
+			}
+			if !strings.Contains(stdout, idp.URL+"/activate") ||
+				!strings.Contains(stdout, testOIDCUserCode) {
+				t.Errorf("the user is not told where to authorize: %q", stdout)
+			}
+		})
+	}
+
+	// a canceled login stops waiting
+	useOIDCClock(t)
+	idp := newTestIdP(t, 600, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	capture(t, func() {
+		if _, err := oidcDeviceLogin(ctx, ClientConfig{}, idp.settings()); err == nil {
+			t.Error("the canceled login succeeded")
+		}
+	})
+}
+
+func TestOIDCLogin(t *testing.T) {
+	useOIDCClock(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{ProfileEnv, TokenKeyEnv, TokenPassphraseEnv} {
+		t.Setenv(name, "")
+	}
+	server := vaulttest.NewServer(vaulttest.Config{})
+	defer server.Close()
+	caCert := filepath.Join(home, "cacert.pem")
+	if err := os.WriteFile(caCert, server.CACertPEM(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	idp := newTestIdP(t, 600, 1, "authorization_pending", "")
+	tokenFile := filepath.Join(home, "token.json")
+
+	stdout, stderr, code := runCommand(t, []string{"login", "--oidc",
+		"--oidc-issuer", idp.URL, "--oidc-client-id", testOIDCClientID,
+		"--login-URL", server.LoginURL(), "--cacert", caCert, "--token-file", tokenFile})
+	if code != 0 {
+		t.Fatalf("login exited with %d: %s%s", code, stdout, stderr)
+	}
+
+	// the vault exchanged the ID token for an access token of its user
+	gTokenInfo = tokenInfo{}
+	if _, err := LoadAccessToken(tokenFile); err != nil {
+		t.Fatal(err)
+	}
+	info := gTokenInfo
+	if info.AuthMethod != authMethodOIDC || info.User != testOIDCUser ||
+		info.ExpiresAt == "" {
+		t.Errorf("saved %+v, want an OIDC session of %s", info, testOIDCUser)
+	}
+	_, err := server.Client(info.AccessToken).GetKeysetGUID(context.Background())
+	if err != nil {
+		t.Errorf("the saved access token is rejected: %v", err)
+	}
+}
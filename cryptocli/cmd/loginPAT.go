@@ -0,0 +1,138 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const authMethodPAT = "pat"
+
+var errPATRejected = errors.New("The personal access token was rejected - " +
+	"it is revoked, expired or invalid. Create a new one with " +
+	"create-personal-access-token.")
+
+// readPAT reads the personal access token from patFile or from the
+// environment variable patEnv
+func readPAT(patFile, patEnv string) (string, error) {
+	var pat string
+	switch {
+	case patFile != "" && patEnv != "":
+		return "", fmt.Errorf("--%s and --%s are mutually exclusive",
+			loginOptionPATFile, loginOptionPATEnv)
+	case patFile != "":
+		fileInfo, err := os.Stat(patFile)
+		if err != nil {
+			return "", fmt.Errorf("Error reading personal access token - %v", err)
+		}
+		warnFilePermissions(patFile, fileInfo)
+		data, err := os.ReadFile(patFile)
+		if err != nil {
+			return "", fmt.Errorf("Error reading personal access token - %v", err)
+		}
+		pat = string(data)
+	case patEnv != "":
+		pat = os.Getenv(patEnv)
+	}
+
+	pat = strings.TrimSpace(pat)
+	if pat == "" {
+		source := patFile
+		if source == "" {
+			source = "environment variable " + patEnv
+		}
+		return "", fmt.Errorf("No personal access token found in %s", source)
+	}
+	return pat, nil
+}
+
+// patExpiration returns the expiry of the personal access token
+// details, given as Unix time or RFC 3339 string
+func patExpiration(details map[string]interface{}) (time.Time, bool) {
+	for _, key := range []string{"expiry", "expires_at"} {
+		switch value := details[key].(type) {
+		case float64:
+			return time.Unix(int64(value), 0).UTC(), true
+		case string:
+			if expiration, err := time.Parse(time.RFC3339, value); err == nil {
+				return expiration, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// patLogin verifies that the vault at server accepts the personal
+// access token pat and returns it as the access token of the session.
+// If name is given, the token details are checked for revocation and
+// expiry.
+func patLogin(ctx context.Context, server, pat, name string) (accessToken, error) {
+	result := accessToken{Token: pat}
+
+	client := NewVault(server, pat)
+	var response *vault.Result
+	var err error
+	if name != "" {
+		var tokenDetails *vault.PersonalAccessTokenDetails
+		tokenDetails, err = client.GetPersonalAccessToken(Idempotent(ctx), name)
+		response = &tokenDetails.Result
+	} else {
+		response, err = client.ListPersonalAccessTokens(Idempotent(ctx))
+	}
+
+	var vaultError *vault.Error
+	if errors.As(err, &vaultError) {
+		if vaultError.StatusCode == http.StatusUnauthorized ||
+			vaultError.StatusCode == http.StatusForbidden ||
+			(vaultError.Empty() && vaultError.StatusCode == http.StatusNotFound) {
+			return result, errPATRejected
+		}
+		return result, fmt.Errorf("%s", vaultError.Body)
+	} else if err != nil {
+		return result, fmt.Errorf("HTTP request failed: %s", err)
+	}
+	if name == "" {
+		return result, nil
+	}
+
+	details := map[string]interface{}{}
+	if err := response.Decode(&details); err != nil {
+		return result, fmt.Errorf("Invalid response - %v", err)
+	}
+
+	if revoked, _ := details["revoked"].(bool); revoked {
+		return result, fmt.Errorf("The personal access token %s is revoked", name)
+	}
+	if expiration, ok := patExpiration(details); ok {
+		if !expiration.After(time.Now()) {
+			return result, fmt.Errorf("The personal access token %s expired at %s",
+				name, formatExpiration(expiration))
+		}
+		result.Expiration = expiration.Format(time.RFC3339)
+	}
+	if user, ok := details["user"].(string); ok {
+		result.User = user
+	}
+	return result, nil
+}
@@ -14,75 +14,45 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package cmd
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
-	"github.com/spf13/cobra"
-)
-
-var macGenCmd = &cobra.Command{
-	Use:   "mac-generate",
-	Short: "Mac Generate",
-	Run: func(cmd *cobra.Command, args []string) {
-		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		keyGuid, _ := flags.GetString("keyGuid")
-		params["keyGuid"] = keyGuid
-
-		data, _ := flags.GetString("data")
-		params["data"] = data
-
-		mode, _ := flags.GetString("mode")
-		params["mode"] = mode
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "mac/generate")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
-		} else {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(0)
-		}
-	},
-}
-
-func init() {
-	rootCmd.AddCommand(macGenCmd)
-	macGenCmd.Flags().StringP("keyGuid", "k", "", "Key GUID to be used for mac generation")
-	macGenCmd.Flags().StringP("data", "d", "", "Data to be used for mac generation")
-	macGenCmd.Flags().StringP("mode", "m", "", "Mac generation mode")
-
-	macGenCmd.MarkFlagRequired("keyGuid")
-	macGenCmd.MarkFlagRequired("data")
-	macGenCmd.MarkFlagRequired("mode")
-}
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"github.com/spf13/cobra"
+)
+
+// macGenData reads the data from --data or --in
+var macGenData = dataCommand{dataOption: "data", binaryData: true, binaryResult: true}
+
+var macGenCmd = &cobra.Command{
+	Use:   "mac-generate",
+	Short: "Mac Generate",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		keyGuid, _ := flags.GetString("keyGuid")
+		data, err := macGenData.readData(flags, "")
+		if err != nil {
+			return err
+		}
+		mode, _ := flags.GetString("mode")
+
+		response, err := GetVault().GenerateMAC(Idempotent(cmd.Context()),
+			vault.MACRequest{KeyGUID: keyGuid, Data: data, Mode: mode})
+		if err != nil {
+			return VaultError(err, "")
+		}
+		return macGenData.printResult(flags, response.MAC, response.JSON())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(macGenCmd)
+	macGenCmd.Flags().StringP("keyGuid", "k", "", "Key GUID to be used for mac generation")
+	macGenCmd.Flags().StringP("data", "d", "", "Data to be used for mac generation")
+	macGenCmd.Flags().StringP("mode", "m", "", "Mac generation mode")
+	macGenData.addFlags(macGenCmd, "data")
+
+	macGenCmd.MarkFlagRequired("keyGuid")
+	macGenCmd.MarkFlagsOneRequired("data", fileOptionIn)
+	macGenCmd.MarkFlagRequired("mode")
+}
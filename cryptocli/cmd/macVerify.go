@@ -14,80 +14,51 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package cmd
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
-	"github.com/spf13/cobra"
-)
-
-var macVerifyCmd = &cobra.Command{
-	Use:   "mac-verify",
-	Short: "Mac Verify",
-	Run: func(cmd *cobra.Command, args []string) {
-		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
-		keyGuid, _ := flags.GetString("keyGuid")
-		params["keyGuid"] = keyGuid
-
-		data, _ := flags.GetString("data")
-		params["data"] = data
-
-		mode, _ := flags.GetString("mode")
-		params["mode"] = mode
-
-		mac, _ := flags.GetString("mac")
-		params["mac"] = mac
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "mac/verify")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
-		} else {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(0)
-		}
-	},
-}
-
-func init() {
-	rootCmd.AddCommand(macVerifyCmd)
-	macVerifyCmd.Flags().StringP("keyGuid", "k", "", "Key GUID to be used for mac verification")
-	macVerifyCmd.Flags().StringP("data", "d", "", "Data to be verified")
-	macVerifyCmd.Flags().StringP("mode", "m", "", "Mac verification mode")
-	macVerifyCmd.Flags().StringP("mac", "M", "", "Mac for the verification")
-
-	macVerifyCmd.MarkFlagRequired("keyGuid")
-	macVerifyCmd.MarkFlagRequired("data")
-	macVerifyCmd.MarkFlagRequired("mode")
-	macVerifyCmd.MarkFlagRequired("mac")
-}
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// macVerifyData reads the data from --data or --in
+var macVerifyData = dataCommand{dataOption: "data", binaryData: true}
+
+var macVerifyCmd = &cobra.Command{
+	Use:   "mac-verify",
+	Short: "Mac Verify",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		keyGuid, _ := flags.GetString("keyGuid")
+		data, err := macVerifyData.readData(flags, "")
+		if err != nil {
+			return err
+		}
+		mode, _ := flags.GetString("mode")
+		mac, _ := flags.GetString("mac")
+
+		response, err := GetVault().VerifyMAC(Idempotent(cmd.Context()),
+			vault.MACRequest{KeyGUID: keyGuid, Data: data, Mode: mode, MAC: mac})
+		if err != nil {
+			return VaultError(err, "")
+		}
+		return macVerifyData.printResult(flags, strconv.FormatBool(response.Verified),
+			response.JSON())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(macVerifyCmd)
+	macVerifyCmd.Flags().StringP("keyGuid", "k", "", "Key GUID to be used for mac verification")
+	macVerifyCmd.Flags().StringP("data", "d", "", "Data to be verified")
+	macVerifyCmd.Flags().StringP("mode", "m", "", "Mac verification mode")
+	macVerifyCmd.Flags().StringP("mac", "M", "", "Mac for the verification")
+	macVerifyData.addFlags(macVerifyCmd, "data")
+
+	macVerifyCmd.MarkFlagRequired("keyGuid")
+	macVerifyCmd.MarkFlagsOneRequired("data", fileOptionIn)
+	macVerifyCmd.MarkFlagRequired("mode")
+	macVerifyCmd.MarkFlagRequired("mac")
+}
@@ -17,60 +17,30 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
+// maskData reads the data to mask from --tokenData or --in
+var maskData = dataCommand{dataOption: "tokenData"}
+
 var maskCmd = &cobra.Command{
 	Use:   "mask",
 	Short: "Mask",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		policyName, _ := flags.GetString("policyName")
-		params["policyName"] = policyName
-
-		tokenData, _ := flags.GetString("tokenData")
-		params["tokenData"] = tokenData
-
-		jsonParams, err := json.Marshal(params)
+		tokenData, err := maskData.readData(flags, "Data to be masked")
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
 
-		endpoint := GetEndPoint("", "1.0", "mask")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().Mask(Idempotent(cmd.Context()),
+			vault.TokenizeRequest{PolicyName: policyName, TokenData: tokenData})
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return maskData.printResult(flags, response.TokenData, response.JSON())
 	},
 }
 
@@ -79,8 +49,8 @@ func init() {
 	maskCmd.Flags().StringP("policyName", "n", "",
 		"Name of the policy to be used to masking")
 	maskCmd.Flags().StringP("tokenData", "d", "",
-		"Data to be masked")
+		"Data to be masked. Prompted for if omitted."+SecretUsage)
+	maskData.addFlags(maskCmd, "data to mask")
 
 	maskCmd.MarkFlagRequired("policyName")
-	maskCmd.MarkFlagRequired("tokenData")
 }
@@ -0,0 +1,252 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/spf13/cobra"
+)
+
+// parquetColumnCommand sends string columns of a Parquet file through a
+// batch tokenization endpoint of the vault, with a policy per column
+type parquetColumnCommand struct {
+	// verb says what the command does to the values, e.g. tokenize
+	verb string
+	send func(ctx context.Context, requests []vault.TokenizeRequest) (*vault.BatchResponse,
+		error)
+}
+
+// parquetValue is the value rows[row][index] sent to the vault
+type parquetValue struct {
+	row, index int
+	column     csvColumn
+}
+
+// addFlags adds the options of Parquet files to cmd
+func (c parquetColumnCommand) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP(fileOptionIn, "i", "", "Parquet file to read")
+	cmd.Flags().StringP(fileOptionOut, "o", "", "Parquet file to write")
+	cmd.Flags().StringSlice(csvOptionColumns, []string{},
+		"String columns to "+c.verb+" and their policies as column=policy, comma "+
+			"separated. Columns are given by name, with dots between the names of "+
+			"nested columns, or by number from 1 in the order of the leaf columns.")
+	cmd.Flags().Int(batchOptionBatchSize, defaultBatchSize,
+		"Number of values sent to the vault per request")
+
+	cmd.MarkFlagRequired(fileOptionIn)
+	cmd.MarkFlagRequired(fileOptionOut)
+	cmd.MarkFlagRequired(csvOptionColumns)
+}
+
+// resolveParquetColumns sets the index of columns among the leaf
+// columns of schema, which must hold strings
+func resolveParquetColumns(columns []csvColumn, schema *parquet.Schema) error {
+	paths := schema.Columns()
+	for i := range columns {
+		column := &columns[i]
+		column.index = -1
+		if column.number > 0 {
+			if column.number > len(paths) {
+				return fmt.Errorf("column %d does not exist, the file has %d columns",
+					column.number, len(paths))
+			}
+			column.index = column.number - 1
+		} else {
+			for j, path := range paths {
+				if strings.Join(path, ".") == column.name {
+					column.index = j
+					break
+				}
+			}
+			if column.index < 0 {
+				return fmt.Errorf("column %s is not in the schema of the file", column.name)
+			}
+		}
+		path := paths[column.index]
+		column.name = strings.Join(path, ".")
+		leaf, _ := schema.Lookup(path...)
+		if leaf.Node.Type().Kind() != parquet.ByteArray {
+			return fmt.Errorf("column %s holds %s values, not strings", column.name,
+				leaf.Node.Type().Kind())
+		}
+	}
+	return nil
+}
+
+// process sends the non-null values of columns of rows, the first of
+// which is row first of the file, and puts their results in their
+// place. A value which fails fails the run.
+func (c parquetColumnCommand) process(ctx context.Context, rows []parquet.Row, first int,
+	columns []csvColumn) error {
+	values := []parquetValue{}
+	requests := []vault.TokenizeRequest{}
+	for i, row := range rows {
+		for j, value := range row {
+			if value.IsNull() {
+				continue
+			}
+			for _, column := range columns {
+				if value.Column() == column.index {
+					values = append(values, parquetValue{i, j, column})
+					requests = append(requests, vault.TokenizeRequest{PolicyName: column.policy,
+						TokenData: string(value.ByteArray())})
+				}
+			}
+		}
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	response, err := c.send(ctx, requests)
+	if err != nil {
+		return VaultError(err, "")
+	}
+	if len(response.Results) != len(requests) {
+		return InvalidResponseError(fmt.Sprintf(
+			"\nThe vault returned %d results for %d values\n\n", len(response.Results),
+			len(requests)))
+	}
+	for i, result := range response.Results {
+		target := values[i]
+		if len(result.Error) > 0 && string(result.Error) != "null" {
+			return Errorf(ExitServerError, "\nFailed to %s column %s of row %d:\n%s\n\n",
+				c.verb, target.column.name, first+target.row, batchErrorText(result.Error))
+		}
+		value := rows[target.row][target.index]
+		rows[target.row][target.index] = parquet.ByteArrayValue([]byte(result.TokenData)).Level(
+			value.RepetitionLevel(), value.DefinitionLevel(), value.Column())
+	}
+	return nil
+}
+
+// copyRowGroup writes the rows of rowGroup to writer as a row group of
+// their own, batchSize values at a time, and returns their number
+func (c parquetColumnCommand) copyRowGroup(ctx context.Context, rowGroup parquet.RowGroup,
+	writer *parquet.Writer, first, batchSize int, columns []csvColumn) (int, error) {
+	rows := rowGroup.Rows()
+	defer rows.Close()
+	size := batchSize / len(columns)
+	if size < 1 {
+		size = 1
+	}
+	buffer := make([]parquet.Row, size)
+	count := 0
+	for {
+		n, err := rows.ReadRows(buffer)
+		if n > 0 {
+			RenewSessionIfDue(ctx)
+			if err := c.process(ctx, buffer[:n], first+count, columns); err != nil {
+				return count, err
+			}
+			if _, err := writer.WriteRows(buffer[:n]); err != nil {
+				return count, Errorf(ExitError, "\nFailed to write the rows: %v\n\n", err)
+			}
+			count += n
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, Errorf(ExitError, "\nFailed to read the rows: %v\n\n", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return count, Errorf(ExitError, "\nFailed to write the rows: %v\n\n", err)
+	}
+	return count, nil
+}
+
+// run processes the columns of the Parquet file --in into --out, a row
+// group at a time
+func (c parquetColumnCommand) run(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	inName, _ := flags.GetString(fileOptionIn)
+	outName, _ := flags.GetString(fileOptionOut)
+	pairs, _ := flags.GetStringSlice(csvOptionColumns)
+	batchSize, _ := flags.GetInt(batchOptionBatchSize)
+
+	columns, err := parseCSVColumns(pairs)
+	if err != nil {
+		return UsageError("\n%v\n\n", err)
+	}
+	if batchSize < 1 {
+		return UsageError("\n--%s must be at least 1\n\n", batchOptionBatchSize)
+	}
+	if inName == stdStream || outName == stdStream {
+		return UsageError("\nParquet files cannot be streamed. Give files to --%s and --%s.\n\n",
+			fileOptionIn, fileOptionOut)
+	}
+
+	in, err := os.Open(inName)
+	if err != nil {
+		return Errorf(ExitError, "\nFailed to open %s: %v\n\n", inName, err)
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return Errorf(ExitError, "\nFailed to open %s: %v\n\n", inName, err)
+	}
+	file, err := parquet.OpenFile(in, info.Size())
+	if err != nil {
+		return Errorf(ExitError, "\nFailed to read %s: %v\n\n", inName, err)
+	}
+	if err = resolveParquetColumns(columns, file.Schema()); err != nil {
+		return UsageError("\n%v\n\n", err)
+	}
+
+	partial := outName + batchPartialSuffix
+	out, err := os.Create(partial)
+	if err != nil {
+		return Errorf(ExitError, "\nFailed to create %s: %v\n\n", partial, err)
+	}
+	defer os.Remove(partial)
+	defer out.Close()
+	// the leaf columns of the schema keep the codec of the input
+	options := []parquet.WriterOption{file.Schema()}
+	for _, kv := range file.Metadata().KeyValueMetadata {
+		options = append(options, parquet.KeyValueMetadata(kv.Key, kv.Value))
+	}
+	writer := parquet.NewWriter(out, options...)
+
+	rows := 0
+	for _, rowGroup := range file.RowGroups() {
+		n, err := c.copyRowGroup(cmd.Context(), rowGroup, writer, rows+1, batchSize, columns)
+		rows += n
+		if err != nil {
+			return err
+		}
+	}
+	if err = writer.Close(); err == nil {
+		err = out.Close()
+	}
+	if err == nil {
+		err = os.Rename(partial, outName)
+	}
+	if err != nil {
+		return Errorf(ExitError, "\nFailed to write %s: %v\n\n", outName, err)
+	}
+	fmt.Printf("\n%d rows written to %s\n\n", rows, outName)
+	return nil
+}
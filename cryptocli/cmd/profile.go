@@ -0,0 +1,371 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// ProfileEnv names the environment variable selecting the profile
+	ProfileEnv = "CRYPTOCLI_PROFILE"
+
+	profilesSubdir         = "profiles"
+	currentProfileFilename = "current_profile"
+	profileFileExt         = ".json"
+	profileTokenFileExt    = ".token"
+)
+
+var gProfile string
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// profile holds the login settings of a named vault and user. The
+// access token of a profile is kept in its own token file.
+type profile struct {
+	Name       string `json:"name"`
+	LoginURL   string `json:"login_url"`
+	CACertFile string `json:"cacert_file"`
+	Username   string `json:"username,omitempty"`
+
+	OIDCIssuer   string `json:"oidc_issuer,omitempty"`
+	OIDCClientID string `json:"oidc_client_id,omitempty"`
+}
+
+func getProfilesDir() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	profilesDir := filepath.Join(dataDir, profilesSubdir)
+	if err := os.MkdirAll(profilesDir, 0700); err != nil {
+		return "", err
+	}
+	return profilesDir, nil
+}
+
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("Invalid profile name %q - use letters, digits, "+
+			"'.', '_' and '-' only", name)
+	}
+	return nil
+}
+
+func profileFile(name string) (string, error) {
+	if err := validateProfileName(name); err != nil {
+		return "", err
+	}
+	profilesDir, err := getProfilesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(profilesDir, name+profileFileExt), nil
+}
+
+// profileTokenFile returns the token file of the profile name
+func profileTokenFile(name string) (string, error) {
+	file, err := profileFile(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(file, profileFileExt) + profileTokenFileExt, nil
+}
+
+func loadProfile(name string) (profile, error) {
+	var prof profile
+	file, err := profileFile(name)
+	if err != nil {
+		return prof, err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return prof, fmt.Errorf("Profile %q not found", name)
+		}
+		return prof, err
+	}
+	if err := json.Unmarshal(data, &prof); err != nil {
+		return prof, fmt.Errorf("Invalid or corrupt profile %s - %v", file, err)
+	}
+	prof.Name = name
+	return prof, nil
+}
+
+func profileExists(name string) bool {
+	file, err := profileFile(name)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(file)
+	return err == nil
+}
+
+func saveProfile(prof profile) error {
+	file, err := profileFile(prof.Name)
+	if err != nil {
+		return err
+	}
+	data, err := JSONMarshalIndent(prof)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0600)
+}
+
+func listProfiles() ([]string, error) {
+	profilesDir, err := getProfilesDir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(profilesDir, "*"+profileFileExt))
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), profileFileExt))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func currentProfileFile() (string, error) {
+	dataDir, err := GetDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, currentProfileFilename), nil
+}
+
+// getCurrentProfile returns the profile selected with profile use
+func getCurrentProfile() string {
+	file, err := currentProfileFile()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// resolveProfile returns the profile commands run with - the one
+// given by --profile, else by the CRYPTOCLI_PROFILE environment
+// variable, else by profile use. Empty means no profile.
+func resolveProfile() string {
+	if gProfile != "" {
+		return gProfile
+	}
+	if name := os.Getenv(ProfileEnv); name != "" {
+		return name
+	}
+	return getCurrentProfile()
+}
+
+// resolveTokenFile returns the token file commands run with, unless
+// given by --token-file, the token file of the profile in use
+func resolveTokenFile() (string, error) {
+	if gAccessTokenFile != "" {
+		return gAccessTokenFile, nil
+	}
+	name := resolveProfile()
+	if name == "" {
+		return "", nil
+	}
+	if !profileExists(name) {
+		return "", fmt.Errorf("Profile %q not found. Create it by running "+
+			"login with --profile %s", name, name)
+	}
+	return profileTokenFile(name)
+}
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named profiles of vaults and users",
+	Long: "A profile saves the login URL, CA certificate and user name of a " +
+		"vault, and the access token of its login session. Log in with " +
+		"--profile <name> to create or refresh a profile. Commands use the " +
+		"profile given by --profile, the " + ProfileEnv + " environment " +
+		"variable or profile use, in this order.",
+}
+
+// profileStatus is a profile as listed and shown with --output
+type profileStatus struct {
+	profile
+	TokenFile string `json:"token_file"`
+	LoggedIn  bool   `json:"logged_in"`
+	Active    bool   `json:"active"`
+	Error     string `json:"error,omitempty"`
+}
+
+// getProfileStatus returns the status of the profile name, which is
+// active if it is the profile in use
+func getProfileStatus(name, active string) (profileStatus, error) {
+	status := profileStatus{profile: profile{Name: name}, Active: name == active}
+	prof, err := loadProfile(name)
+	if err != nil {
+		return status, err
+	}
+	status.profile = prof
+	status.TokenFile, _ = profileTokenFile(name)
+	if _, err := os.Stat(status.TokenFile); err == nil {
+		status.LoggedIn = true
+	}
+	return status, nil
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := listProfiles()
+		if err != nil {
+			return Errorf(ExitError, "\nError listing profiles - %v\n\n", err)
+		}
+		if len(names) == 0 {
+			return PrintStatus("\nNo profiles found. Create one by running login "+
+				"with --profile <name>.\n\n", []profileStatus{})
+		}
+
+		active := resolveProfile()
+		statuses := []profileStatus{}
+		message := &strings.Builder{}
+		message.WriteString("\n")
+		for _, name := range names {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			status, err := getProfileStatus(name, active)
+			if err != nil {
+				status.Error = err.Error()
+				fmt.Fprintf(message, "%s %s (%v)\n", marker, name, err)
+			} else {
+				fmt.Fprintf(message, "%s %-20s %s %s\n", marker, name, status.Username,
+					status.LoginURL)
+			}
+			statuses = append(statuses, status)
+		}
+		message.WriteString("\n")
+		return PrintStatus(message.String(), statuses)
+	},
+}
+
+var profileShowCmd = &cobra.Command{
+	Use:   "show [name]",
+	Short: "Show a profile, by default the one in use",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		active := resolveProfile()
+		name := active
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == "" {
+			return Errorf(ExitError, "\nNo profile in use\n\n")
+		}
+
+		status, err := getProfileStatus(name, active)
+		if err != nil {
+			return Errorf(ExitError, "\n%v\n\n", err)
+		}
+		loggedIn := "no"
+		if status.LoggedIn {
+			loggedIn = "yes"
+		}
+		return PrintStatus(fmt.Sprintf("\nProfile    : %s\n"+
+			"Login URL  : %s\n"+
+			"CA Cert    : %s\n"+
+			"User Name  : %s\n"+
+			"Token File : %s\n"+
+			"Logged in  : %s\n\n", status.Name, status.LoginURL, status.CACertFile,
+			status.Username, status.TokenFile, loggedIn), status)
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Use a profile for subsequent commands",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if _, err := loadProfile(name); err != nil {
+			return Errorf(ExitError, "\n%v\n\n", err)
+		}
+
+		file, err := currentProfileFile()
+		if err == nil {
+			err = os.WriteFile(file, []byte(name+"\n"), 0600)
+		}
+		if err != nil {
+			return Errorf(ExitError, "\nError saving current profile - %v\n\n", err)
+		}
+		return PrintStatus(fmt.Sprintf("\nUsing profile %s.\n\n", name),
+			map[string]string{"profile": name, "status": "in_use"})
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile and its access token",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		file, err := profileFile(name)
+		if err != nil {
+			return Errorf(ExitError, "\n%v\n\n", err)
+		}
+		if err := os.Remove(file); err != nil {
+			if os.IsNotExist(err) {
+				return Errorf(ExitError, "\nProfile %q not found\n\n", name)
+			}
+			return Errorf(ExitError, "\nError deleting profile %s - %v\n\n", name, err)
+		}
+
+		tokenFile, _ := profileTokenFile(name)
+		if err := os.Remove(tokenFile); err != nil && !os.IsNotExist(err) {
+			return Errorf(ExitError, "\nError deleting token file %s - %v\n\n", tokenFile, err)
+		}
+
+		if getCurrentProfile() == name {
+			if currentFile, err := currentProfileFile(); err == nil {
+				os.Remove(currentFile)
+			}
+		}
+		return PrintStatus(fmt.Sprintf("\nProfile %s deleted.\n\n", name),
+			map[string]string{"profile": name, "status": "deleted"})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileShowCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+}
@@ -0,0 +1,57 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// protectJSONFields processes the fields of the documents of --in
+var protectJSONFields = jsonFieldCommand{restore: false}
+
+var protectJSONCmd = &cobra.Command{
+	Use:   "protect-json",
+	Short: "Tokenize and encrypt fields of JSON documents",
+	Long: `Tokenize and encrypt fields of JSON documents
+
+The values --tokenize selects by JSONPath are tokenized with the
+tokenization policy given for each path, and the values --encrypt
+selects are encrypted with AES-GCM by the key given for each path,
+e.g.
+
+  --tokenize '$.customer.card.number=pan' --encrypt '$.notes=<keyGuid>'
+
+Strings and numbers can be tokenized; any value can be encrypted and
+is replaced by a string holding the IV and ciphertext. The input is a
+JSON document, or NDJSON with a document per line, and all other bytes
+of the documents are written as they are, so that unprotect-json with
+the same options restores the original documents exactly. JSONPaths
+may use $, .name, ['name'], [n], .* and [*].
+
+Values of many documents are sent to the vault together, in batches
+of --batch-size documents. A value the vault fails to process stops
+the command. With --out, the run can be continued with --resume once
+the cause is fixed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return protectJSONFields.run(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(protectJSONCmd)
+	protectJSONFields.addFlags(protectJSONCmd)
+}
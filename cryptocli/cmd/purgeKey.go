@@ -17,53 +17,28 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var purgeKeyCmd = &cobra.Command{
 	Use:   "purge-key",
 	Short: "Purge Key",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-
 		key_guid, _ := flags.GetString("key_guid")
 
-		endpoint := GetEndPoint("", "1.0", "key/"+key_guid+"/purge")
-		ret, err := DoDelete(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			nil,
-			"application/json")
+		response, err := GetVault().PurgeKey(cmd.Context(), key_guid)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
+			return VaultError(err, "")
 		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
-		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(purgeKeyCmd)
 	purgeKeyCmd.Flags().StringP("key_guid", "k", "",
-	"Key GUID")
+		"Key GUID")
 
 	purgeKeyCmd.MarkFlagRequired("key_guid")
-}
\ No newline at end of file
+}
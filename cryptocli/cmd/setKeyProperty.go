@@ -17,57 +17,22 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"fmt"
-	"os"
-	"encoding/json"
 	"github.com/spf13/cobra"
 )
 
 var setKeyPropertyCmd = &cobra.Command{
 	Use:   "set-key-property",
 	Short: "Set Key Property",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		key_guid, _ := flags.GetString("key_guid")
-
 		description, _ := flags.GetString("description")
-		params["description"] = description
 
-		jsonParams, err := json.Marshal(params)
-	    if (err != nil) {
-		fmt.Println("Error building JSON request: ", err)
-		os.Exit(1)
-	    }
-
-		endpoint := GetEndPoint("", "1.0", "key/"+key_guid)
-		ret, err := DoPatch(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().SetKeyDescription(cmd.Context(), key_guid, description)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
+			return VaultError(err, "")
 		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -78,4 +43,4 @@ func init() {
 
 	setKeyPropertyCmd.MarkFlagRequired("key_guid")
 	setKeyPropertyCmd.MarkFlagRequired("description")
-}
\ No newline at end of file
+}
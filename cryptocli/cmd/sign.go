@@ -17,65 +17,31 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
+// signData reads the data to sign from --data or --in
+var signData = dataCommand{dataOption: "data", binaryData: true, binaryResult: true}
+
 var signCmd = &cobra.Command{
 	Use:   "sign",
 	Short: "Sign",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		keyGuid, _ := flags.GetString("keyGuid")
-		params["keyGuid"] = keyGuid
-
-		data, _ := flags.GetString("data")
-		params["data"] = data
-
-		if flags.Changed("mode") {
-			mode, _ := flags.GetString("mode")
-			params["mode"] = mode
-		}
-
-		jsonParams, err := json.Marshal(params)
+		data, err := signData.readData(flags, "")
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
+		mode, _ := flags.GetString("mode")
 
-		endpoint := GetEndPoint("", "1.0", "sign")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().Sign(Idempotent(cmd.Context()),
+			vault.SignRequest{KeyGUID: keyGuid, Data: data, Mode: mode})
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return signData.printResult(flags, response.Signature, response.JSON())
 	},
 }
 
@@ -84,7 +50,8 @@ func init() {
 	signCmd.Flags().StringP("keyGuid", "k", "", "Key GUID to be used for signing")
 	signCmd.Flags().StringP("data", "d", "", "Data to be signed")
 	signCmd.Flags().StringP("mode", "m", "", "Mode of signing")
+	signData.addFlags(signCmd, "data to sign")
 
 	signCmd.MarkFlagRequired("keyGuid")
-	signCmd.MarkFlagRequired("data")
+	signCmd.MarkFlagsOneRequired("data", fileOptionIn)
 }
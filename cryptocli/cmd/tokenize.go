@@ -17,67 +17,32 @@ limitations under the License.
 package cmd
 
 import (
-	// standard
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
+// tokenizeData reads the data to tokenize from --tokenData or --in
+var tokenizeData = dataCommand{dataOption: "tokenData"}
+
 var tokenizeCmd = &cobra.Command{
 	Use:   "tokenize",
 	Short: "Tokenize",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		policyName, _ := flags.GetString("policyName")
-		params["policyName"] = policyName
-
-		tokenData, _ := flags.GetString("tokenData")
-		params["tokenData"] = tokenData
-
-		if flags.Changed("keyGuid") {
-			keyGuid, _ := flags.GetString("keyGuid")
-			params["keyGuid"] = keyGuid
-		}
-
-		jsonParams, err := json.Marshal(params)
+		tokenData, err := tokenizeData.readData(flags, "Data to be tokenized")
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
+		keyGuid, _ := flags.GetString("keyGuid")
 
-		endpoint := GetEndPoint("", "1.0", "token")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().Tokenize(Idempotent(cmd.Context()),
+			vault.TokenizeRequest{PolicyName: policyName, TokenData: tokenData,
+				KeyGUID: keyGuid})
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				jsonData, _ := JSONMarshalIndent(retMap)
-				fmt.Println("\n" + string(jsonData) + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return tokenizeData.printResult(flags, response.TokenData, response.JSON())
 	},
 }
 
@@ -86,10 +51,10 @@ func init() {
 	tokenizeCmd.Flags().StringP("policyName", "n", "",
 		"Name of the policy to be used to tokenization")
 	tokenizeCmd.Flags().StringP("tokenData", "d", "",
-		"Data to be tokenized")
+		"Data to be tokenized. Prompted for if omitted."+SecretUsage)
 	tokenizeCmd.Flags().StringP("keyGuid", "k", "",
 		"If you want to tokenize data using specific version of the key. If not provided, latest key version will be used")
+	tokenizeData.addFlags(tokenizeCmd, "data to tokenize")
 
 	tokenizeCmd.MarkFlagRequired("policyName")
-	tokenizeCmd.MarkFlagRequired("tokenData")
 }
@@ -0,0 +1,49 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// unprotectJSONFields processes the fields of the documents of --in
+var unprotectJSONFields = jsonFieldCommand{restore: true}
+
+var unprotectJSONCmd = &cobra.Command{
+	Use:   "unprotect-json",
+	Short: "Detokenize and decrypt fields of JSON documents protected by protect-json",
+	Long: `Detokenize and decrypt fields of JSON documents protected by protect-json
+
+The values --tokenize selects by JSONPath are detokenized with the
+tokenization policy given for each path, and the values --encrypt
+selects are decrypted by the key given for each path. Given the
+options protect-json was run with, the original documents are restored
+exactly.
+
+Values of many documents are sent to the vault together, in batches
+of --batch-size documents. A value the vault fails to process stops
+the command. With --out, the run can be continued with --resume once
+the cause is fixed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return unprotectJSONFields.run(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unprotectJSONCmd)
+	unprotectJSONFields.addFlags(unprotectJSONCmd)
+}
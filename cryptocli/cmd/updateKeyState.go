@@ -17,57 +17,22 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
 	"github.com/spf13/cobra"
 )
 
 var updateKeyStateCmd = &cobra.Command{
 	Use:   "update-key-state",
 	Short: "Update Key State",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		key_guid, _ := flags.GetString("key_guid")
-
 		state, _ := flags.GetString("state")
-		params["state"] = state
-
-		jsonParams, err := json.Marshal(params)
-		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
 
-		endpoint := GetEndPoint("", "1.0", "key/"+key_guid+"/state")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().UpdateKeyState(cmd.Context(), key_guid, state)
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
+			return VaultError(err, "")
 		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nAction denied\n")
-			os.Exit(5)
-		}
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			fmt.Println("\n" + retStr + "\n")
-			os.Exit(3)
-		}
-		fmt.Println("\n" + retStr + "\n")
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -75,7 +40,7 @@ func init() {
 	rootCmd.AddCommand(updateKeyStateCmd)
 	updateKeyStateCmd.Flags().StringP("key_guid", "k", "", "Key GUID")
 	updateKeyStateCmd.Flags().StringP("state", "s", "", "State. Supported states are enable and disable.")
-	
+
 	updateKeyStateCmd.MarkFlagRequired("key_guid")
 	updateKeyStateCmd.MarkFlagRequired("state")
-}
\ No newline at end of file
+}
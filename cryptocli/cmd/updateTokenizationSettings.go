@@ -17,82 +17,50 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
 var updateTokenizationSettingsCmd = &cobra.Command{
 	Use:   "update-tokenization-settings",
 	Short: "Update Tokenization settings",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
+		settings := vault.TokenizationSettings{}
 
 		degradedModeAvailProvided := flags.Changed("degraded-mode-availability")
 		oidcProvided := flags.Changed("oidc-enabled")
 		if !degradedModeAvailProvided && !oidcProvided {
-			fmt.Println("Specify any one of Tokenization settings to update")
-			os.Exit(1)
+			return Errorf(ExitUsage, "Specify any one of Tokenization settings to update\n")
 		}
 
 		if flags.Changed("degraded-mode-availability") {
 			degradedModeAvailability, _ := flags.GetString("degraded-mode-availability")
 			if degradedModeAvailability != "enable" && degradedModeAvailability != "disable" {
-				fmt.Printf("\nInvalid -d, --degraded-mode-availability option %s. "+
+				return Errorf(ExitUsage, "\nInvalid -d, --degraded-mode-availability option %s. "+
 					"Supported: enable (or) disable\n", degradedModeAvailability)
-				os.Exit(1)
 			}
-			params["degraded_mode_availability"] = (degradedModeAvailability == "enable")
-		}		
+			enabled := degradedModeAvailability == "enable"
+			settings.DegradedModeAvailability = &enabled
+		}
 
 		if flags.Changed("oidc-enabled") {
 			oidcEnabled, _ := flags.GetString("oidc-enabled")
 			if oidcEnabled != "enable" && oidcEnabled != "disable" {
-				fmt.Printf("\nInvalid -o, --oidc-enabled option %s. "+
-								"Supported: enable (or) disable\n", oidcEnabled)
-				os.Exit(1)
+				return Errorf(ExitUsage, "\nInvalid -o, --oidc-enabled option %s. "+
+					"Supported: enable (or) disable\n", oidcEnabled)
 			}
-			params["oidc_enabled"] = (oidcEnabled == "enable")
+			enabled := oidcEnabled == "enable"
+			settings.OIDCEnabled = &enabled
 		}
 
-		revision, _ := flags.GetInt("revision")
-		params["revision"] = revision
+		settings.Revision, _ = flags.GetInt("revision")
 
-		jsonParams, err := json.Marshal(params)
+		response, err := GetVault().UpdateTokenizationSettings(cmd.Context(), settings)
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
-		}
-
-		endpoint := GetEndPoint("", "1.0", "UpdateTokenizationSettings")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
-		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		}
-		retBytes := ret["data"].(*bytes.Buffer)
-		retStatus := ret["status"].(int)
-		retStr := retBytes.String()
-
-		if retStr == "" && retStatus == 404 {
-			fmt.Println("\nTokenization Settings not found\n")
-			os.Exit(5)
-		}
-
-		fmt.Println("\n" + retStr + "\n")
-
-		retMap := JsonStrToMap(retStr)
-		if _, present := retMap["error"]; present {
-			os.Exit(3)
+			return VaultError(err, "Tokenization Settings not found")
 		}
-		os.Exit(0)
+		return PrintVaultResult(response.JSON())
 	},
 }
 
@@ -17,68 +17,36 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
+	"strconv"
+
 	"github.com/spf13/cobra"
 )
 
+// verifyData reads the signed data from --data or --in
+var verifyData = dataCommand{dataOption: "data", binaryData: true}
+
 var verifyCmd = &cobra.Command{
 	Use:   "verify",
 	Short: "Verify",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		keyGuid, _ := flags.GetString("keyGuid")
-		params["keyGuid"] = keyGuid
-
-		data, _ := flags.GetString("data")
-		params["data"] = data
-
-		if flags.Changed("mode") {
-			mode, _ := flags.GetString("mode")
-			params["mode"] = mode
-		}
-
-		signature, _ := flags.GetString("signature")
-		params["signature"] = signature
-
-		jsonParams, err := json.Marshal(params)
+		data, err := verifyData.readData(flags, "")
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
+		mode, _ := flags.GetString("mode")
+		signature, _ := flags.GetString("signature")
 
-		endpoint := GetEndPoint("", "1.0", "verify")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().Verify(Idempotent(cmd.Context()),
+			vault.VerifyRequest{KeyGUID: keyGuid, Data: data, Mode: mode,
+				Signature: signature})
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return verifyData.printResult(flags, strconv.FormatBool(response.Verified),
+			response.JSON())
 	},
 }
 
@@ -88,8 +56,9 @@ func init() {
 	verifyCmd.Flags().StringP("data", "d", "", "Data to be verified")
 	verifyCmd.Flags().StringP("mode", "m", "", "Mode of signing")
 	verifyCmd.Flags().StringP("signature", "s", "", "Signature")
+	verifyData.addFlags(verifyCmd, "signed data")
 
 	verifyCmd.MarkFlagRequired("keyGuid")
-	verifyCmd.MarkFlagRequired("data")
+	verifyCmd.MarkFlagsOneRequired("data", fileOptionIn)
 	verifyCmd.MarkFlagRequired("signature")
 }
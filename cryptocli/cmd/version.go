@@ -24,8 +24,9 @@ import (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Version of Entrust Cryptographic APIs Vault CLI",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("1.2")
+		return nil
 	},
 }
 
@@ -17,65 +17,31 @@ limitations under the License.
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"os"
+	"cli/pkg/vault"
 	"github.com/spf13/cobra"
 )
 
+// wrapData reads the key to wrap from --data or --in
+var wrapData = dataCommand{dataOption: "data", binaryData: true, binaryResult: true}
+
 var wrapCmd = &cobra.Command{
 	Use:   "wrap",
 	Short: "Wrap",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		flags := cmd.Flags()
-		params := map[string]interface{}{}
-
 		keyGuid, _ := flags.GetString("keyGuid")
-		params["keyGuid"] = keyGuid
-
-		data, _ := flags.GetString("data")
-		params["data"] = data
-
-		if flags.Changed("mode") {
-			mode, _ := flags.GetString("mode")
-			params["mode"] = mode
-		}
-
-		jsonParams, err := json.Marshal(params)
+		data, err := wrapData.readData(flags, "")
 		if err != nil {
-			fmt.Println("Error building JSON request: ", err)
-			os.Exit(1)
+			return err
 		}
+		mode, _ := flags.GetString("mode")
 
-		endpoint := GetEndPoint("", "1.0", "wrap")
-		ret, err := DoPost(endpoint,
-			GetCACertFile(),
-			AuthTokenKV(),
-			jsonParams,
-			"application/json")
+		response, err := GetVault().Wrap(Idempotent(cmd.Context()),
+			vault.SignRequest{KeyGUID: keyGuid, Data: data, Mode: mode})
 		if err != nil {
-			fmt.Printf("\nHTTP request failed: %s\n", err)
-			os.Exit(4)
-		} else {
-			retBytes := ret["data"].(*bytes.Buffer)
-			retStatus := ret["status"].(int)
-			retStr := retBytes.String()
-
-			if retStr == "" && retStatus == 404 {
-				fmt.Println("\nAction denied\n")
-				os.Exit(5)
-			}
-
-			retMap := JsonStrToMap(retStr)
-			if _, present := retMap["error"]; present {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(3)
-			} else {
-				fmt.Println("\n" + retStr + "\n")
-				os.Exit(0)
-			}
+			return VaultError(err, "")
 		}
+		return wrapData.printResult(flags, response.Data, response.JSON())
 	},
 }
 
@@ -84,7 +50,8 @@ func init() {
 	wrapCmd.Flags().StringP("keyGuid", "k", "", "Key GUID to be used for wrapping")
 	wrapCmd.Flags().StringP("data", "d", "", "Data to be wrapped")
 	wrapCmd.Flags().StringP("mode", "m", "", "Mode of wrapping")
+	wrapData.addFlags(wrapCmd, "key to wrap")
 
 	wrapCmd.MarkFlagRequired("keyGuid")
-	wrapCmd.MarkFlagRequired("data")
+	wrapCmd.MarkFlagsOneRequired("data", fileOptionIn)
 }
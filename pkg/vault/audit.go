@@ -0,0 +1,101 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetAuditSettings returns the audit log settings
+func (c *Client) GetAuditSettings(ctx context.Context) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "GetAuditSetting/", struct{}{},
+		response)
+}
+
+// AuditSettings is the request of UpdateAuditSettings. Retention is
+// in days and TotalSize in bytes, 0 meaning no limit. Nil settings are
+// left unchanged.
+type AuditSettings struct {
+	Retention *int32 `json:"auditlog_retention,omitempty"`
+	TotalSize *int64 `json:"auditlog_totsize,omitempty"`
+}
+
+// UpdateAuditSettings updates the audit log settings. The vault
+// answers with no content.
+func (c *Client) UpdateAuditSettings(ctx context.Context,
+	settings AuditSettings) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "UpdateAuditSetting/", settings,
+		response)
+}
+
+// AuditMessage is an audit log message
+type AuditMessage struct {
+	CreatedAt   string                 `json:"created_at"`
+	UserContext string                 `json:"user_context"`
+	Message     string                 `json:"message"`
+	Info        map[string]interface{} `json:"info,omitempty"`
+}
+
+// AuditMessages is the response of ListAuditMessages
+type AuditMessages struct {
+	Result
+	Messages  []AuditMessage `json:"audit_messages"`
+	NextToken string         `json:"next_token,omitempty"`
+}
+
+// AuditListOptions filters and pages the audit messages. Zero fields
+// are not sent.
+type AuditListOptions struct {
+	Filters   string   `json:"filters,omitempty"`
+	MaxItems  int      `json:"max_items,omitempty"`
+	Fields    []string `json:"fields,omitempty"`
+	NextToken string   `json:"next_token,omitempty"`
+}
+
+// ListAuditMessages lists the audit log messages
+func (c *Client) ListAuditMessages(ctx context.Context,
+	options AuditListOptions) (*AuditMessages, error) {
+	response := &AuditMessages{}
+	return response, c.do(ctx, http.MethodPost, "ListAuditMessages/", options, response)
+}
+
+// GetAuditMessageTemplate returns the template of an audit message
+func (c *Client) GetAuditMessageTemplate(ctx context.Context,
+	messageID int) (*Result, error) {
+	response := &Result{}
+	params := struct {
+		MessageID int `json:"message_id"`
+	}{messageID}
+	return response, c.do(ctx, http.MethodPost, "GetAuditMessageTemplate/", params,
+		response)
+}
+
+// ListAuditMessageTemplates lists the templates of audit messages
+func (c *Client) ListAuditMessageTemplates(ctx context.Context,
+	options AuditListOptions) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "ListAuditMessageTemplates/", options,
+		response)
+}
+
+// DownloadAuditBundle downloads the audit log bundle
+func (c *Client) DownloadAuditBundle(ctx context.Context) (*Download, error) {
+	return c.download(ctx, "GetAuditBundle/")
+}
@@ -0,0 +1,215 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// EncryptRequest is the request of Encrypt and Decrypt. Binary values
+// are base64 encoded.
+type EncryptRequest struct {
+	KeyGUID string `json:"keyGuid"`
+	Data    string `json:"data"`
+	Mode    string `json:"mode"`
+	IV      string `json:"iv"`
+	AAD     string `json:"aad"`
+}
+
+// EncryptResponse is the response of Encrypt and Decrypt
+type EncryptResponse struct {
+	Result
+	Data string `json:"data"`
+	IV   string `json:"iv,omitempty"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// Encrypt encrypts data with a key of the vault
+func (c *Client) Encrypt(ctx context.Context,
+	request EncryptRequest) (*EncryptResponse, error) {
+	response := &EncryptResponse{}
+	return response, c.do(ctx, http.MethodPost, "encrypt/", request, response)
+}
+
+// Decrypt decrypts data encrypted by Encrypt
+func (c *Client) Decrypt(ctx context.Context,
+	request EncryptRequest) (*EncryptResponse, error) {
+	response := &EncryptResponse{}
+	return response, c.do(ctx, http.MethodPost, "decrypt/", request, response)
+}
+
+// SignRequest is the request of Sign, Wrap and Unwrap. An empty Mode
+// selects the default of the key.
+type SignRequest struct {
+	KeyGUID string `json:"keyGuid"`
+	Data    string `json:"data"`
+	Mode    string `json:"mode,omitempty"`
+}
+
+// SignResponse is the response of Sign
+type SignResponse struct {
+	Result
+	Signature string `json:"signature"`
+}
+
+// Sign signs data with a key of the vault
+func (c *Client) Sign(ctx context.Context, request SignRequest) (*SignResponse, error) {
+	response := &SignResponse{}
+	return response, c.do(ctx, http.MethodPost, "sign/", request, response)
+}
+
+// VerifyRequest is the request of Verify
+type VerifyRequest struct {
+	KeyGUID   string `json:"keyGuid"`
+	Data      string `json:"data"`
+	Mode      string `json:"mode,omitempty"`
+	Signature string `json:"signature"`
+}
+
+// VerifyResponse is the response of Verify and VerifyMAC
+type VerifyResponse struct {
+	Result
+	Verified bool `json:"verified"`
+}
+
+// Verify verifies a signature made by Sign
+func (c *Client) Verify(ctx context.Context, request VerifyRequest) (*VerifyResponse, error) {
+	response := &VerifyResponse{}
+	return response, c.do(ctx, http.MethodPost, "verify/", request, response)
+}
+
+// WrapResponse is the response of Wrap and Unwrap
+type WrapResponse struct {
+	Result
+	Data string `json:"data"`
+}
+
+// Wrap wraps a key with a key of the vault
+func (c *Client) Wrap(ctx context.Context, request SignRequest) (*WrapResponse, error) {
+	response := &WrapResponse{}
+	return response, c.do(ctx, http.MethodPost, "wrap/", request, response)
+}
+
+// Unwrap unwraps a key wrapped by Wrap
+func (c *Client) Unwrap(ctx context.Context, request SignRequest) (*WrapResponse, error) {
+	response := &WrapResponse{}
+	return response, c.do(ctx, http.MethodPost, "unwrap/", request, response)
+}
+
+// DigestRequest is the request of Digest. Data is base64 encoded.
+type DigestRequest struct {
+	Data string `json:"data"`
+	Mode string `json:"mode"`
+}
+
+// DigestResponse is the response of Digest
+type DigestResponse struct {
+	Result
+	Digest string `json:"digest"`
+}
+
+// Digest hashes data
+func (c *Client) Digest(ctx context.Context, request DigestRequest) (*DigestResponse, error) {
+	response := &DigestResponse{}
+	return response, c.do(ctx, http.MethodPost, "digest/", request, response)
+}
+
+// MACRequest is the request of GenerateMAC and VerifyMAC. MAC is only
+// sent to VerifyMAC.
+type MACRequest struct {
+	KeyGUID string `json:"keyGuid"`
+	Data    string `json:"data"`
+	Mode    string `json:"mode"`
+	MAC     string `json:"mac,omitempty"`
+}
+
+// MACResponse is the response of GenerateMAC
+type MACResponse struct {
+	Result
+	MAC string `json:"mac"`
+}
+
+// GenerateMAC computes the message authentication code of data
+func (c *Client) GenerateMAC(ctx context.Context, request MACRequest) (*MACResponse, error) {
+	response := &MACResponse{}
+	return response, c.do(ctx, http.MethodPost, "mac/generate/", request, response)
+}
+
+// VerifyMAC verifies a message authentication code made by GenerateMAC
+func (c *Client) VerifyMAC(ctx context.Context, request MACRequest) (*VerifyResponse, error) {
+	response := &VerifyResponse{}
+	return response, c.do(ctx, http.MethodPost, "mac/verify/", request, response)
+}
+
+// BatchEncryptRequest is a record of BatchEncrypt, BatchDecrypt and
+// BatchEncryptDecrypt. Operation, encrypt or decrypt, is only sent to
+// BatchEncryptDecrypt.
+type BatchEncryptRequest struct {
+	KeyGUID   string `json:"keyGuid"`
+	Data      string `json:"data"`
+	Mode      string `json:"mode"`
+	IV        string `json:"iv,omitempty"`
+	AAD       string `json:"aad,omitempty"`
+	Operation string `json:"operation,omitempty"`
+}
+
+// BatchResult is the result of a record of a batch request. Error is
+// set instead of the data fields if the record failed.
+type BatchResult struct {
+	Data      string          `json:"data,omitempty"`
+	IV        string          `json:"iv,omitempty"`
+	Tag       string          `json:"tag,omitempty"`
+	TokenData string          `json:"tokenData,omitempty"`
+	Error     json.RawMessage `json:"error,omitempty"`
+}
+
+// BatchResponse is the response of a batch request, with a result per
+// record in the order of the request
+type BatchResponse struct {
+	Result
+	Results []BatchResult
+}
+
+// UnmarshalJSON decodes the JSON array of results
+func (r *BatchResponse) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &r.Results)
+}
+
+// BatchEncrypt encrypts a batch of records
+func (c *Client) BatchEncrypt(ctx context.Context,
+	records []BatchEncryptRequest) (*BatchResponse, error) {
+	response := &BatchResponse{}
+	return response, c.do(ctx, http.MethodPost, "batch/encrypt/", records, response)
+}
+
+// BatchDecrypt decrypts a batch of records
+func (c *Client) BatchDecrypt(ctx context.Context,
+	records []BatchEncryptRequest) (*BatchResponse, error) {
+	response := &BatchResponse{}
+	return response, c.do(ctx, http.MethodPost, "batch/decrypt/", records, response)
+}
+
+// BatchEncryptDecrypt encrypts or decrypts each record of a batch as
+// given by its Operation
+func (c *Client) BatchEncryptDecrypt(ctx context.Context,
+	records []BatchEncryptRequest) (*BatchResponse, error) {
+	response := &BatchResponse{}
+	return response, c.do(ctx, http.MethodPost, "batch/encrypt-decrypt/", records,
+		response)
+}
@@ -0,0 +1,480 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// An envelope is data encrypted with a local data key which a key of
+// the vault encrypts. It starts with envelopeMagic, the length of the
+// JSON encoded EnvelopeHeader as 4 byte big endian integer and the
+// header. Chunks of at most ChunkSize bytes of data follow, each sealed
+// with AES-256-GCM and preceded by a 4 byte big endian frame header
+// holding the length of the sealed chunk and, in its top bit, whether
+// the chunk is the last one. The nonce of a chunk is NoncePrefix, the
+// chunk number as 4 byte big endian integer and 1 for the last chunk or
+// 0 for the others. The bytes up to the end of the header are the
+// additional data of every chunk. Reordered, dropped or truncated
+// chunks and altered headers thus fail authentication.
+
+const (
+	envelopeMagic = "CVENV\x00\x01\n"
+
+	// EnvelopeVersion is the version of the envelope format
+	EnvelopeVersion = 1
+	// EnvelopeAlgorithm encrypts the data of envelopes
+	EnvelopeAlgorithm = "AES-256-GCM"
+	// DefaultChunkSize is the default size of the chunks of envelopes
+	DefaultChunkSize = 64 * 1024
+	// MaxChunkSize is the largest chunk size of envelopes
+	MaxChunkSize = 16 * 1024 * 1024
+
+	// WrapMethodEncrypt wraps the data key with the encrypt endpoint
+	WrapMethodEncrypt = "encrypt"
+	// WrapMethodWrap wraps the data key with the wrap endpoint
+	WrapMethodWrap = "wrap"
+
+	maxHeaderSize    = 64 * 1024
+	dataKeySize      = 32
+	noncePrefixSize  = 7
+	lastChunkFlag    = 1 << 31
+	frameHeaderSize  = 4
+	envelopeWrapMode = "GCM"
+)
+
+// ErrInvalidEnvelope is returned for data which is not a valid
+// envelope or fails authentication
+var ErrInvalidEnvelope = errors.New("invalid envelope")
+
+// EnvelopeHeader describes an envelope: how its data is encrypted and
+// the data key, wrapped by a key of the vault. Binary values are base64
+// encoded.
+type EnvelopeHeader struct {
+	Version        int    `json:"version"`
+	Algorithm      string `json:"algorithm"`
+	ChunkSize      int    `json:"chunk_size"`
+	NoncePrefix    string `json:"nonce_prefix"`
+	KeyGUID        string `json:"key_guid"`
+	KeyVersion     string `json:"key_version,omitempty"`
+	KeyVersionGUID string `json:"key_version_guid,omitempty"`
+	WrapMethod     string `json:"wrap_method"`
+	WrappedKey     string `json:"wrapped_key"`
+	WrapIV         string `json:"wrap_iv,omitempty"`
+}
+
+// EnvelopeOptions are the options of NewEnvelopeWriter. The zero
+// values select WrapMethodEncrypt, DefaultChunkSize and crypto/rand as
+// Rand, the source of the data key and the nonces.
+type EnvelopeOptions struct {
+	KeyGUID    string
+	WrapMethod string
+	ChunkSize  int
+	Rand       io.Reader
+}
+
+// invalidEnvelope returns ErrInvalidEnvelope with details
+func invalidEnvelope(format string, args ...interface{}) error {
+	return fmt.Errorf("%w - %s", ErrInvalidEnvelope, fmt.Sprintf(format, args...))
+}
+
+// keyVersion returns the version and the version GUID of the key
+// keyGUID as GetKey reports them
+func (c *Client) keyVersion(ctx context.Context, keyGUID string) (string, string, error) {
+	response, err := c.GetKey(ctx, keyGUID)
+	if err != nil {
+		return "", "", err
+	}
+	var key struct {
+		Version     interface{} `json:"version"`
+		VersionGUID string      `json:"version_guid"`
+	}
+	if err = response.Decode(&key); err != nil {
+		return "", "", fmt.Errorf("invalid key details - %v", err)
+	}
+	version := ""
+	if key.Version != nil {
+		version = fmt.Sprint(key.Version)
+	}
+	return version, key.VersionGUID, nil
+}
+
+// wrapDataKey wraps dataKey with the key of header as its WrapMethod
+// says and sets WrappedKey and WrapIV
+func (c *Client) wrapDataKey(ctx context.Context, header *EnvelopeHeader,
+	dataKey []byte) error {
+	data := base64.StdEncoding.EncodeToString(dataKey)
+	switch header.WrapMethod {
+	case WrapMethodEncrypt:
+		response, err := c.Encrypt(ctx, EncryptRequest{KeyGUID: header.KeyGUID,
+			Data: data, Mode: envelopeWrapMode})
+		if err != nil {
+			return err
+		}
+		header.WrappedKey = response.Data
+		header.WrapIV = response.IV
+	case WrapMethodWrap:
+		response, err := c.Wrap(ctx, SignRequest{KeyGUID: header.KeyGUID, Data: data})
+		if err != nil {
+			return err
+		}
+		header.WrappedKey = response.Data
+	default:
+		return fmt.Errorf("unsupported wrap method %q", header.WrapMethod)
+	}
+	if header.WrappedKey == "" {
+		return fmt.Errorf("the vault returned no wrapped data key")
+	}
+	return nil
+}
+
+// unwrapDataKey returns the data key of header, unwrapped by the vault
+func (c *Client) unwrapDataKey(ctx context.Context, header *EnvelopeHeader) ([]byte, error) {
+	var data string
+	switch header.WrapMethod {
+	case WrapMethodEncrypt:
+		response, err := c.Decrypt(ctx, EncryptRequest{KeyGUID: header.KeyGUID,
+			Data: header.WrappedKey, Mode: envelopeWrapMode, IV: header.WrapIV})
+		if err != nil {
+			return nil, err
+		}
+		data = response.Data
+	case WrapMethodWrap:
+		response, err := c.Unwrap(ctx, SignRequest{KeyGUID: header.KeyGUID,
+			Data: header.WrappedKey})
+		if err != nil {
+			return nil, err
+		}
+		data = response.Data
+	default:
+		return nil, invalidEnvelope("unsupported wrap method %q", header.WrapMethod)
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(dataKey) != dataKeySize {
+		return nil, fmt.Errorf("the vault returned an invalid data key")
+	}
+	return dataKey, nil
+}
+
+// envelopeCipher returns the AEAD of dataKey
+func envelopeCipher(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of chunk number n
+func chunkNonce(prefix []byte, n uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], n)
+	if last {
+		nonce[noncePrefixSize+4] = 1
+	}
+	return nonce
+}
+
+// EnvelopeWriter encrypts the data written to it into an envelope.
+// Close must be called to write the last chunk.
+type EnvelopeWriter struct {
+	w           io.Writer
+	header      EnvelopeHeader
+	aad         []byte
+	aead        cipher.AEAD
+	noncePrefix []byte
+	buffer      []byte
+	sealed      []byte
+	chunk       uint32
+	err         error
+}
+
+// NewEnvelopeWriter generates a data key, wraps it with the key of
+// options, writes the header of the envelope to w and returns a writer
+// of the data to encrypt
+func (c *Client) NewEnvelopeWriter(ctx context.Context, w io.Writer,
+	options EnvelopeOptions) (*EnvelopeWriter, error) {
+	header := EnvelopeHeader{Version: EnvelopeVersion, Algorithm: EnvelopeAlgorithm,
+		ChunkSize: options.ChunkSize, KeyGUID: options.KeyGUID,
+		WrapMethod: options.WrapMethod}
+	if header.ChunkSize == 0 {
+		header.ChunkSize = DefaultChunkSize
+	}
+	if header.ChunkSize < 0 || header.ChunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("the chunk size must be between 1 and %d", MaxChunkSize)
+	}
+	if header.WrapMethod == "" {
+		header.WrapMethod = WrapMethodEncrypt
+	}
+
+	random := options.Rand
+	if random == nil {
+		random = rand.Reader
+	}
+	secret := make([]byte, dataKeySize+noncePrefixSize)
+	if _, err := io.ReadFull(random, secret); err != nil {
+		return nil, err
+	}
+	dataKey, noncePrefix := secret[:dataKeySize], secret[dataKeySize:]
+	header.NoncePrefix = base64.StdEncoding.EncodeToString(noncePrefix)
+
+	var err error
+	header.KeyVersion, header.KeyVersionGUID, err = c.keyVersion(ctx, header.KeyGUID)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.wrapDataKey(ctx, &header, dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := envelopeCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	aad := make([]byte, len(envelopeMagic)+4, len(envelopeMagic)+4+len(encoded))
+	copy(aad, envelopeMagic)
+	binary.BigEndian.PutUint32(aad[len(envelopeMagic):], uint32(len(encoded)))
+	aad = append(aad, encoded...)
+	if _, err = w.Write(aad); err != nil {
+		return nil, err
+	}
+	return &EnvelopeWriter{w: w, header: header, aad: aad, aead: aead,
+		noncePrefix: noncePrefix,
+		buffer:      make([]byte, 0, header.ChunkSize)}, nil
+}
+
+// Header returns the header of the envelope
+func (e *EnvelopeWriter) Header() EnvelopeHeader {
+	return e.header
+}
+
+// seal writes the buffered data as a chunk
+func (e *EnvelopeWriter) seal(last bool) error {
+	if !last && e.chunk == ^uint32(0) {
+		return fmt.Errorf("the data is too large for the chunk size")
+	}
+	e.sealed = append(e.sealed[:0], 0, 0, 0, 0)
+	e.sealed = e.aead.Seal(e.sealed, chunkNonce(e.noncePrefix, e.chunk, last),
+		e.buffer, e.aad)
+	frame := uint32(len(e.sealed) - frameHeaderSize)
+	if last {
+		frame |= lastChunkFlag
+	}
+	binary.BigEndian.PutUint32(e.sealed, frame)
+	if _, err := e.w.Write(e.sealed); err != nil {
+		return err
+	}
+	e.buffer = e.buffer[:0]
+	e.chunk++
+	return nil
+}
+
+// Write encrypts p. A chunk is written once it is full and more data
+// follows, as only the last chunk may be partial.
+func (e *EnvelopeWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(e.buffer) == cap(e.buffer) {
+			if e.err = e.seal(false); e.err != nil {
+				return written, e.err
+			}
+		}
+		n := copy(e.buffer[len(e.buffer):cap(e.buffer)], p)
+		e.buffer = e.buffer[:len(e.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close writes the last chunk. It does not close the underlying writer.
+func (e *EnvelopeWriter) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.err = e.seal(true); e.err != nil {
+		return e.err
+	}
+	e.err = errors.New("envelope writer is closed")
+	return nil
+}
+
+// ReadEnvelopeHeader reads the header of the envelope r. It returns
+// the header and its encoding, the additional data of the chunks.
+func ReadEnvelopeHeader(r io.Reader) (*EnvelopeHeader, []byte, error) {
+	prefix := make([]byte, len(envelopeMagic)+4)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, nil, invalidEnvelope("the data is not an envelope")
+		}
+		return nil, nil, err
+	}
+	if string(prefix[:len(envelopeMagic)]) != envelopeMagic {
+		return nil, nil, invalidEnvelope("the data is not an envelope")
+	}
+	size := binary.BigEndian.Uint32(prefix[len(envelopeMagic):])
+	if size > maxHeaderSize {
+		return nil, nil, invalidEnvelope("the header is too large")
+	}
+	encoded := make([]byte, size)
+	if _, err := io.ReadFull(r, encoded); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, nil, invalidEnvelope("the header is truncated")
+		}
+		return nil, nil, err
+	}
+
+	header := &EnvelopeHeader{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	if err := decoder.Decode(header); err != nil {
+		return nil, nil, invalidEnvelope("invalid header - %v", err)
+	}
+	if header.Version != EnvelopeVersion {
+		return nil, nil, invalidEnvelope("unsupported version %d", header.Version)
+	}
+	if header.Algorithm != EnvelopeAlgorithm {
+		return nil, nil, invalidEnvelope("unsupported algorithm %q", header.Algorithm)
+	}
+	if header.ChunkSize <= 0 || header.ChunkSize > MaxChunkSize {
+		return nil, nil, invalidEnvelope("invalid chunk size %d", header.ChunkSize)
+	}
+	return header, append(prefix, encoded...), nil
+}
+
+// EnvelopeReader decrypts an envelope. Each chunk is authenticated
+// before its data is returned, but only reading up to io.EOF proves
+// that the data is complete.
+type EnvelopeReader struct {
+	r           io.Reader
+	header      EnvelopeHeader
+	aad         []byte
+	aead        cipher.AEAD
+	noncePrefix []byte
+	sealed      []byte
+	plain       []byte
+	data        []byte
+	chunk       uint32
+	last        bool
+	err         error
+}
+
+// NewEnvelopeReader reads the header of the envelope r, unwraps its
+// data key with the vault and returns a reader of the decrypted data
+func (c *Client) NewEnvelopeReader(ctx context.Context, r io.Reader) (*EnvelopeReader, error) {
+	header, aad, err := ReadEnvelopeHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	noncePrefix, err := base64.StdEncoding.DecodeString(header.NoncePrefix)
+	if err != nil || len(noncePrefix) != noncePrefixSize {
+		return nil, invalidEnvelope("invalid nonce prefix")
+	}
+	dataKey, err := c.unwrapDataKey(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := envelopeCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &EnvelopeReader{r: r, header: *header, aad: aad, aead: aead,
+		noncePrefix: noncePrefix}, nil
+}
+
+// Header returns the header of the envelope
+func (e *EnvelopeReader) Header() EnvelopeHeader {
+	return e.header
+}
+
+// open reads and decrypts the next chunk
+func (e *EnvelopeReader) open() error {
+	if e.last {
+		// nothing may follow the last chunk
+		if n, _ := io.ReadFull(e.r, make([]byte, 1)); n > 0 {
+			return invalidEnvelope("data follows the last chunk")
+		}
+		return io.EOF
+	}
+	frame := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(e.r, frame); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return invalidEnvelope("the data is truncated")
+		}
+		return err
+	}
+	size := binary.BigEndian.Uint32(frame)
+	last := size&lastChunkFlag != 0
+	size &^= lastChunkFlag
+	if size < uint32(e.aead.Overhead()) ||
+		size > uint32(e.header.ChunkSize+e.aead.Overhead()) {
+		return invalidEnvelope("invalid chunk size")
+	}
+	if cap(e.sealed) < int(size) {
+		e.sealed = make([]byte, size)
+	}
+	e.sealed = e.sealed[:size]
+	if _, err := io.ReadFull(e.r, e.sealed); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return invalidEnvelope("the data is truncated")
+		}
+		return err
+	}
+	data, err := e.aead.Open(e.plain[:0], chunkNonce(e.noncePrefix, e.chunk, last),
+		e.sealed, e.aad)
+	if err != nil {
+		return invalidEnvelope("chunk %d is not authentic", e.chunk)
+	}
+	if !last && e.chunk == ^uint32(0) {
+		return invalidEnvelope("too many chunks")
+	}
+	e.plain = data
+	e.data = data
+	e.chunk++
+	e.last = last
+	return nil
+}
+
+// Read reads decrypted data
+func (e *EnvelopeReader) Read(p []byte) (int, error) {
+	for len(e.data) == 0 {
+		if e.err != nil {
+			return 0, e.err
+		}
+		e.err = e.open()
+	}
+	n := copy(p, e.data)
+	e.data = e.data[n:]
+	return n, nil
+}
@@ -0,0 +1,161 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault_test
+
+import (
+	"bytes"
+	"cli/pkg/vault"
+	"cli/pkg/vault/vaulttest"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// newEnvelopeClient returns a client of a mock vault and the GUID of
+// an AES-256 key
+func newEnvelopeClient(t *testing.T) (*vault.Client, string) {
+	server := vaulttest.NewServer(vaulttest.Config{})
+	t.Cleanup(server.Close)
+	client := server.Client(server.Vault.NewSession(vaulttest.DefaultUsername))
+	response, err := client.CreateKey(context.Background(),
+		vault.CreateKeyRequest{Name: "envelope", Cipher: "AES-256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key struct {
+		KeyGUID string `json:"key_guid"`
+	}
+	if err = response.Decode(&key); err != nil {
+		t.Fatal(err)
+	}
+	return client, key.KeyGUID
+}
+
+// seal returns data encrypted into an envelope
+func seal(t *testing.T, client *vault.Client, options vault.EnvelopeOptions,
+	data []byte) []byte {
+	envelope := &bytes.Buffer{}
+	w, err := client.NewEnvelopeWriter(context.Background(), envelope, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// uneven writes cross the chunk boundaries
+	for len(data) > 0 {
+		n := 7
+		if n > len(data) {
+			n = len(data)
+		}
+		if _, err = w.Write(data[:n]); err != nil {
+			t.Fatal(err)
+		}
+		data = data[n:]
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return envelope.Bytes()
+}
+
+// open returns the data of envelope
+func open(client *vault.Client, envelope []byte) ([]byte, error) {
+	r, err := client.NewEnvelopeReader(context.Background(), bytes.NewReader(envelope))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestEnvelope(t *testing.T) {
+	client, keyGUID := newEnvelopeClient(t)
+	data := bytes.Repeat([]byte("0123456789"), 10)
+
+	for _, test := range []struct {
+		name    string
+		options vault.EnvelopeOptions
+		data    []byte
+	}{
+		{"encrypt", vault.EnvelopeOptions{KeyGUID: keyGUID, ChunkSize: 16}, data},
+		{"wrap", vault.EnvelopeOptions{KeyGUID: keyGUID, ChunkSize: 16,
+			WrapMethod: vault.WrapMethodWrap}, data},
+		{"full chunks", vault.EnvelopeOptions{KeyGUID: keyGUID, ChunkSize: 20}, data},
+		{"default chunk size", vault.EnvelopeOptions{KeyGUID: keyGUID}, data},
+		{"empty", vault.EnvelopeOptions{KeyGUID: keyGUID}, nil},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			envelope := seal(t, client, test.options, test.data)
+			got, err := open(client, envelope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, test.data) {
+				t.Errorf("got %q, want %q", got, test.data)
+			}
+
+			header, _, err := vault.ReadEnvelopeHeader(bytes.NewReader(envelope))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if header.KeyGUID != keyGUID || header.KeyVersion != "1" {
+				t.Errorf("got key %s version %s, want %s version 1",
+					header.KeyGUID, header.KeyVersion, keyGUID)
+			}
+		})
+	}
+}
+
+func TestEnvelopeTampering(t *testing.T) {
+	client, keyGUID := newEnvelopeClient(t)
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	envelope := seal(t, client, vault.EnvelopeOptions{KeyGUID: keyGUID, ChunkSize: 16},
+		data)
+	header, encoded, err := vault.ReadEnvelopeHeader(bytes.NewReader(envelope))
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := 4 + header.ChunkSize + 16
+
+	flipped := func(i int) []byte {
+		tampered := append([]byte{}, envelope...)
+		tampered[i] ^= 1
+		return tampered
+	}
+	swapped := append([]byte{}, encoded...)
+	swapped = append(swapped, envelope[len(encoded)+frame:len(encoded)+2*frame]...)
+	swapped = append(swapped, envelope[len(encoded):len(encoded)+frame]...)
+	swapped = append(swapped, envelope[len(encoded)+2*frame:]...)
+
+	for _, test := range []struct {
+		name     string
+		envelope []byte
+	}{
+		{"not an envelope", []byte("plain text")},
+		{"header", flipped(len(encoded) - 2)},
+		{"data", flipped(len(encoded) + 10)},
+		{"truncated", envelope[:len(envelope)-1]},
+		{"last chunk dropped", envelope[:len(encoded)+2*frame]},
+		{"chunks swapped", swapped},
+		{"trailing data", append(append([]byte{}, envelope...), 0)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := open(client, test.envelope)
+			if !errors.Is(err, vault.ErrInvalidEnvelope) {
+				t.Errorf("got %v, want %v", err, vault.ErrInvalidEnvelope)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// CreateKeyRequest is the request of CreateKey. An empty KeysetGUID
+// selects the keyset of the vault.
+type CreateKeyRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	KeysetGUID  string `json:"keyset_guid,omitempty"`
+	Cipher      string `json:"cipher"`
+}
+
+// CreateKey creates a key
+func (c *Client) CreateKey(ctx context.Context, request CreateKeyRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "key/", request, response)
+}
+
+// GenerateKeyRequest is the request of GenerateKeyCSR. Empty fields
+// are not sent.
+type GenerateKeyRequest struct {
+	Cipher     string `json:"cipher,omitempty"`
+	PublicKey  string `json:"public_key,omitempty"`
+	KeysetGUID string `json:"keyset_guid,omitempty"`
+	SubjectDN  string `json:"subject_dn,omitempty"`
+	SANs       string `json:"sans,omitempty"`
+}
+
+// GenerateKeyCSR generates an asymmetric key and a certificate signing
+// request for it
+func (c *Client) GenerateKeyCSR(ctx context.Context,
+	request GenerateKeyRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "generate_key_csr/", request, response)
+}
+
+// ImportKeyRequest is the request of ImportKey. KeyMaterial is wrapped
+// by the key WrappingKeyGUID; SHA256, if set, selects SHA-256 for the
+// unwrapping.
+type ImportKeyRequest struct {
+	Name            string `json:"name"`
+	Description     string `json:"description,omitempty"`
+	KeysetGUID      string `json:"keyset_guid,omitempty"`
+	KeyMaterial     string `json:"key_material"`
+	WrappingKeyGUID string `json:"wrapping_key_guid"`
+	Cipher          string `json:"cipher"`
+	SHA256          *bool  `json:"sha256,omitempty"`
+}
+
+// ImportKey imports a wrapped key
+func (c *Client) ImportKey(ctx context.Context, request ImportKeyRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "key_import/", request, response)
+}
+
+// ImportClearKeyRequest is the request of ImportClearKey
+type ImportClearKeyRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	KeysetGUID  string `json:"keyset_guid,omitempty"`
+	KeyMaterial string `json:"key_material"`
+	Cipher      string `json:"cipher"`
+}
+
+// ImportClearKey imports a key given in clear
+func (c *Client) ImportClearKey(ctx context.Context,
+	request ImportClearKeyRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "clear_key_import/", request, response)
+}
+
+// keyPath returns the path of action on the key keyGUID
+func keyPath(keyGUID, action string) string {
+	return "key/" + url.PathEscape(keyGUID) + "/" + action
+}
+
+// GetKey returns the details of a key
+func (c *Client) GetKey(ctx context.Context, keyGUID string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, keyPath(keyGUID, ""), nil, response)
+}
+
+// GetKeyValue returns the value of a key
+func (c *Client) GetKeyValue(ctx context.Context, keyGUID string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, keyPath(keyGUID, "value/"), nil,
+		response)
+}
+
+// GetKeyVersions returns the versions of a key
+func (c *Client) GetKeyVersions(ctx context.Context, keyGUID string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, keyPath(keyGUID, "versions/"),
+		struct{}{}, response)
+}
+
+// ListKeysOptions filters the keys of ListKeys. Empty fields are not
+// sent.
+type ListKeysOptions struct {
+	CryptographicAlgorithm string `json:"cryptographic_algorithm,omitempty"`
+	Status                 string `json:"status,omitempty"`
+}
+
+// ListKeys lists the keys of a keyset
+func (c *Client) ListKeys(ctx context.Context, keysetGUID string,
+	options ListKeysOptions) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, "keys/"+url.PathEscape(keysetGUID)+"/",
+		options, response)
+}
+
+// RotateKey adds a version to a key
+func (c *Client) RotateKey(ctx context.Context, keyGUID string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, keyPath(keyGUID, "rotate/"),
+		struct{}{}, response)
+}
+
+// UpdateKeyState sets the state of a key, e.g. Active or Deactivated
+func (c *Client) UpdateKeyState(ctx context.Context, keyGUID,
+	state string) (*Result, error) {
+	response := &Result{}
+	params := struct {
+		State string `json:"state"`
+	}{state}
+	return response, c.do(ctx, http.MethodPost, keyPath(keyGUID, "state/"), params,
+		response)
+}
+
+// SetKeyDescription sets the description of a key
+func (c *Client) SetKeyDescription(ctx context.Context, keyGUID,
+	description string) (*Result, error) {
+	response := &Result{}
+	params := struct {
+		Description string `json:"description"`
+	}{description}
+	return response, c.do(ctx, http.MethodPatch, keyPath(keyGUID, ""), params, response)
+}
+
+// DeleteKeyRequest is the request of ScheduleKeyDeletion. Operation
+// schedules or cancels the deletion after RetentionPeriod days.
+type DeleteKeyRequest struct {
+	Operation       string `json:"operation"`
+	RetentionPeriod int    `json:"retention_period"`
+}
+
+// ScheduleKeyDeletion schedules or cancels the deletion of a key
+func (c *Client) ScheduleKeyDeletion(ctx context.Context, keyGUID string,
+	request DeleteKeyRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, keyPath(keyGUID, "delete/"), request,
+		response)
+}
+
+// PurgeKey deletes a key at once
+func (c *Client) PurgeKey(ctx context.Context, keyGUID string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodDelete, keyPath(keyGUID, "purge/"), nil,
+		response)
+}
+
+// ExportKeyRequest is the request of ExportKey. The key is wrapped by
+// the public key read from PublicKey, with SHA-256 if SHA256 is set.
+type ExportKeyRequest struct {
+	PublicKey     io.Reader
+	PublicKeyName string // file name of the public key
+	SHA256        bool
+}
+
+// ExportKey exports a key wrapped by a public key
+func (c *Client) ExportKey(ctx context.Context, keyGUID string,
+	request ExportKeyRequest) (*Result, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, err := w.CreateFormFile("public_key", request.PublicKeyName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(fw, request.PublicKey); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	path := keyPath(keyGUID, "export/")
+	if request.SHA256 {
+		path += "?sha256=yes"
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(path),
+		bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", w.FormDataContentType())
+	if c.token != "" {
+		httpRequest.Header.Set(AuthHeader, c.token)
+	}
+	response := &Result{}
+	return response, c.send(httpRequest, response)
+}
+
+// ExportPublicKey returns the public key of an asymmetric key
+func (c *Client) ExportPublicKey(ctx context.Context, keyGUID string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, keyPath(keyGUID, "export/public/"), nil,
+		response)
+}
+
+// DownloadPublicKey downloads the public key of an asymmetric key as
+// a file
+func (c *Client) DownloadPublicKey(ctx context.Context, keyGUID string) (*Download, error) {
+	return c.download(ctx, keyPath(keyGUID, "export/public?download=yes"))
+}
+
+// GetKeysetGUID returns the GUID of the keyset of the vault
+func (c *Client) GetKeysetGUID(ctx context.Context) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, "GetKeysetGUID/", struct{}{}, response)
+}
+
+// GetHSMInfo returns information on the HSM of the vault
+func (c *Client) GetHSMInfo(ctx context.Context) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, "GetHSMInfo/", struct{}{}, response)
+}
+
+// PlatformInfo is the response of GetPlatformInfo. Nodes holds the
+// cluster nodes, as addresses or as objects with an address field.
+type PlatformInfo struct {
+	Result
+	Nodes []interface{} `json:"nodes"`
+}
+
+// GetPlatformInfo returns information on the platform of the vault
+func (c *Client) GetPlatformInfo(ctx context.Context) (*PlatformInfo, error) {
+	response := &PlatformInfo{}
+	return response, c.do(ctx, http.MethodGet, "GetPlatformInfo/", struct{}{}, response)
+}
+
+// EnableKeysetHSMRequest is the request of EnableKeysetHSM
+type EnableKeysetHSMRequest struct {
+	KeysetGUID   string `json:"keyset_guid"`
+	PartLabel    string `json:"part_label"`
+	PartPassword string `json:"part_password"`
+}
+
+// EnableKeysetHSM protects the keys of a keyset by an HSM partition
+func (c *Client) EnableKeysetHSM(ctx context.Context,
+	request EnableKeysetHSMRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "EnableKeysetHSM/", request, response)
+}
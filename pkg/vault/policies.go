@@ -0,0 +1,170 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Principal is a user or group an access policy applies to. Exactly
+// one of the fields is set.
+type Principal struct {
+	LocalUser *LocalUserPrincipal `json:"local_user,omitempty"`
+	ADUser    *ADUserPrincipal    `json:"ad_user,omitempty"`
+	ADGroup   *ADGroupPrincipal   `json:"ad_group,omitempty"`
+}
+
+// LocalUserPrincipal is a local user of the vault
+type LocalUserPrincipal struct {
+	Username string `json:"username"`
+}
+
+// ADUserPrincipal is an Active Directory user, given by UPN or by
+// logon name
+type ADUserPrincipal struct {
+	UPN       string `json:"upn,omitempty"`
+	LogonName string `json:"logon_name,omitempty"`
+}
+
+// ADGroupPrincipal is an Active Directory group
+type ADGroupPrincipal struct {
+	DN          string `json:"dn"`
+	DisplayName string `json:"display_name"`
+}
+
+// LocalUser returns the principal of a local user
+func LocalUser(username string) Principal {
+	return Principal{LocalUser: &LocalUserPrincipal{Username: username}}
+}
+
+// ADUserByUPN returns the principal of an AD user by UPN
+func ADUserByUPN(upn string) Principal {
+	return Principal{ADUser: &ADUserPrincipal{UPN: upn}}
+}
+
+// ADUserByLogonName returns the principal of an AD user by logon name
+func ADUserByLogonName(logonName string) Principal {
+	return Principal{ADUser: &ADUserPrincipal{LogonName: logonName}}
+}
+
+// ADGroup returns the principal of an AD group
+func ADGroup(dn, displayName string) Principal {
+	return Principal{ADGroup: &ADGroupPrincipal{DN: dn, DisplayName: displayName}}
+}
+
+// AccessPolicy is the request of CreateAccessPolicy. Tag values are
+// strings or JSON objects.
+type AccessPolicy struct {
+	Name                    string                 `json:"name"`
+	Role                    string                 `json:"role"`
+	TokenizationPermissions []string               `json:"tokenization_permissions"`
+	Description             string                 `json:"desc,omitempty"`
+	Principals              []Principal            `json:"principals,omitempty"`
+	Tags                    map[string]interface{} `json:"tags,omitempty"`
+}
+
+// CreateAccessPolicy creates an access policy
+func (c *Client) CreateAccessPolicy(ctx context.Context,
+	policy AccessPolicy) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "CreatePolicy/", policy, response)
+}
+
+// UpdateAccessPolicyRequest is the request of UpdateAccessPolicy.
+// Revision is the revision of the policy being updated. Empty fields
+// are left unchanged; ClearDescription removes the description.
+type UpdateAccessPolicyRequest struct {
+	PolicyID                string                 `json:"policy_id"`
+	Revision                int                    `json:"revision"`
+	TokenizationPermissions []string               `json:"tokenization_permissions,omitempty"`
+	Role                    string                 `json:"role,omitempty"`
+	Description             string                 `json:"desc,omitempty"`
+	ClearDescription        bool                   `json:"-"`
+	Principals              []Principal            `json:"principals,omitempty"`
+	Tags                    map[string]interface{} `json:"tags,omitempty"`
+}
+
+// MarshalJSON sends a cleared description as null
+func (r UpdateAccessPolicyRequest) MarshalJSON() ([]byte, error) {
+	type request UpdateAccessPolicyRequest
+	if !r.ClearDescription {
+		return json.Marshal(request(r))
+	}
+	return json.Marshal(struct {
+		request
+		Description *string `json:"desc"`
+	}{request: request(r)})
+}
+
+// UpdateAccessPolicy updates an access policy
+func (c *Client) UpdateAccessPolicy(ctx context.Context,
+	request UpdateAccessPolicyRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "UpdatePolicy/", request, response)
+}
+
+// PolicyVersion selects a version of an access policy. A zero Version
+// selects the current version where the version is optional.
+type PolicyVersion struct {
+	PolicyID string `json:"policy_id,omitempty"`
+	Version  int    `json:"version,omitempty"`
+}
+
+// GetAccessPolicy returns an access policy
+func (c *Client) GetAccessPolicy(ctx context.Context,
+	request PolicyVersion) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "GetPolicy/", request, response)
+}
+
+// ListAccessPolicies lists the access policies
+func (c *Client) ListAccessPolicies(ctx context.Context,
+	options ListOptions) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "ListPolicies/", options, response)
+}
+
+// ListPolicyVersions lists the versions of an access policy, or of all
+// access policies if policyID is empty
+func (c *Client) ListPolicyVersions(ctx context.Context,
+	policyID string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "ListPolicyVersions/",
+		PolicyVersion{PolicyID: policyID}, response)
+}
+
+// SetPolicyVersion makes a version of an access policy current
+func (c *Client) SetPolicyVersion(ctx context.Context,
+	request PolicyVersion) (*Result, error) {
+	response := &Result{}
+	params := struct {
+		PolicyID string `json:"policy_id"`
+		Version  int    `json:"version"`
+	}{request.PolicyID, request.Version}
+	return response, c.do(ctx, http.MethodPost, "SetPolicyVersion/", params, response)
+}
+
+// DeleteAccessPolicy deletes an access policy
+func (c *Client) DeleteAccessPolicy(ctx context.Context, policyID string) (*Result, error) {
+	response := &Result{}
+	params := struct {
+		PolicyID string `json:"policy_id"`
+	}{policyID}
+	return response, c.do(ctx, http.MethodPost, "DeletePolicy/", params, response)
+}
@@ -0,0 +1,56 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// LoginRequest is the request of Login, with either the credentials
+// of a user or an OpenID Connect ID token
+type LoginRequest struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	IDToken  string `json:"id_token,omitempty"`
+}
+
+// Session is the response of Login and Renew. ExpiresAt is in RFC 3339
+// format.
+type Session struct {
+	Result
+	AccessToken string `json:"access_token"`
+	ExpiresAt   string `json:"expires_at"`
+	User        string `json:"user,omitempty"`
+}
+
+// Login logs into the vault vaultID. The access token of the session
+// authenticates the requests of a Client, see WithToken.
+func (c *Client) Login(ctx context.Context, vaultID string,
+	request LoginRequest) (*Session, error) {
+	response := &Session{}
+	return response, c.do(ctx, http.MethodPost, "Login/"+url.PathEscape(vaultID)+"/",
+		request, response)
+}
+
+// Renew renews the session of the access token of c and returns its
+// new access token.
+func (c *Client) Renew(ctx context.Context) (*Session, error) {
+	response := &Session{}
+	return response, c.do(ctx, http.MethodPost, "Renew/", struct{}{}, response)
+}
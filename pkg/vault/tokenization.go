@@ -0,0 +1,249 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// TokenizeRequest is the request of the tokenization endpoints. An
+// empty KeyGUID selects the latest version of the key of the policy;
+// masking and rekeying ignore it.
+type TokenizeRequest struct {
+	PolicyName string `json:"policyName"`
+	TokenData  string `json:"tokenData"`
+	KeyGUID    string `json:"keyGuid,omitempty"`
+}
+
+// TokenizeResponse is the response of the tokenization endpoints
+type TokenizeResponse struct {
+	Result
+	TokenData string `json:"tokenData"`
+}
+
+func (c *Client) tokenize(ctx context.Context, path string,
+	request TokenizeRequest) (*TokenizeResponse, error) {
+	response := &TokenizeResponse{}
+	return response, c.do(ctx, http.MethodPost, path, request, response)
+}
+
+// Tokenize replaces data by a token of the same format
+func (c *Client) Tokenize(ctx context.Context,
+	request TokenizeRequest) (*TokenizeResponse, error) {
+	return c.tokenize(ctx, "token/", request)
+}
+
+// Detokenize returns the data of a token
+func (c *Client) Detokenize(ctx context.Context,
+	request TokenizeRequest) (*TokenizeResponse, error) {
+	return c.tokenize(ctx, "detoken/", request)
+}
+
+// DetokenizeMask returns the data of a token, masked by the mask
+// policy of the tokenization policy
+func (c *Client) DetokenizeMask(ctx context.Context,
+	request TokenizeRequest) (*TokenizeResponse, error) {
+	return c.tokenize(ctx, "detoken_mask/", request)
+}
+
+// Mask masks data by a mask policy
+func (c *Client) Mask(ctx context.Context,
+	request TokenizeRequest) (*TokenizeResponse, error) {
+	return c.tokenize(ctx, "mask/", request)
+}
+
+// Rekey tokenizes a token again with the latest version of the key
+func (c *Client) Rekey(ctx context.Context,
+	request TokenizeRequest) (*TokenizeResponse, error) {
+	return c.tokenize(ctx, "rekey/", request)
+}
+
+func (c *Client) batchTokenize(ctx context.Context, path string,
+	records []TokenizeRequest) (*BatchResponse, error) {
+	response := &BatchResponse{}
+	return response, c.do(ctx, http.MethodPost, path, records, response)
+}
+
+// BatchTokenize tokenizes a batch of records
+func (c *Client) BatchTokenize(ctx context.Context,
+	records []TokenizeRequest) (*BatchResponse, error) {
+	return c.batchTokenize(ctx, "batch/token/", records)
+}
+
+// BatchDetokenize detokenizes a batch of records
+func (c *Client) BatchDetokenize(ctx context.Context,
+	records []TokenizeRequest) (*BatchResponse, error) {
+	return c.batchTokenize(ctx, "batch/detoken/", records)
+}
+
+// BatchMask masks a batch of records
+func (c *Client) BatchMask(ctx context.Context,
+	records []TokenizeRequest) (*BatchResponse, error) {
+	return c.batchTokenize(ctx, "batch/mask/", records)
+}
+
+// BatchRekey rekeys a batch of records
+func (c *Client) BatchRekey(ctx context.Context,
+	records []TokenizeRequest) (*BatchResponse, error) {
+	return c.batchTokenize(ctx, "batch/rekey/", records)
+}
+
+// TokenizationPolicy is the request of CreateTokenizationPolicy.
+// CharsetOption holds the sums of 1 for space, 2 for punctuation, 4
+// for numbers, 8 for alphabets and 16 for fullwidth characters.
+type TokenizationPolicy struct {
+	Name                  string   `json:"name"`
+	Description           string   `json:"description,omitempty"`
+	KeyGUID               string   `json:"keyGuid"`
+	PreservedPrefixLength int      `json:"preservedPrefixLength"`
+	PreservedSuffixLength int      `json:"preservedSuffixLength"`
+	IsNew                 bool     `json:"isNew"`
+	Charset               string   `json:"charset"`
+	CharsetOption         []string `json:"charsetOption"`
+}
+
+// MaskPolicy is the request of CreateMaskPolicy
+type MaskPolicy struct {
+	Name                  string `json:"name"`
+	Description           string `json:"description,omitempty"`
+	IsNew                 bool   `json:"isNew"`
+	PreservedPrefixLength int    `json:"preservedPrefixLength"`
+	PreservedSuffixLength int    `json:"preservedSuffixLength"`
+	Charset               string `json:"charset"`
+	MaskChar              string `json:"maskChar"`
+}
+
+// PolicyListOptions filters and pages the lists of tokenization and
+// mask policies. Empty fields are not sent.
+type PolicyListOptions struct {
+	Name     string // name filter
+	Offset   string
+	Limit    string
+	Counts   string // "true" to include count information
+	Ordering string
+}
+
+// query returns the options as query string of a URL
+func (o PolicyListOptions) query() string {
+	query := url.Values{}
+	for name, value := range map[string]string{
+		"name":      o.Name,
+		"_offset":   o.Offset,
+		"_limit":    o.Limit,
+		"_counts":   o.Counts,
+		"_ordering": o.Ordering,
+	} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	if len(query) == 0 {
+		return ""
+	}
+	return "?" + query.Encode()
+}
+
+// CreateTokenizationPolicy creates or, if IsNew is false, updates a
+// tokenization policy
+func (c *Client) CreateTokenizationPolicy(ctx context.Context,
+	policy TokenizationPolicy) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "CreateTokenPolicy/", policy, response)
+}
+
+// GetTokenizationPolicy returns the tokenization policy name
+func (c *Client) GetTokenizationPolicy(ctx context.Context, name string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet,
+		"GetTokenPolicy/"+url.PathEscape(name)+"/", nil, response)
+}
+
+// ListTokenizationPolicies lists the tokenization policies
+func (c *Client) ListTokenizationPolicies(ctx context.Context,
+	options PolicyListOptions) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, "GetTokenPolicies"+options.query(),
+		nil, response)
+}
+
+// DeleteTokenizationPolicy deletes the tokenization policy name
+func (c *Client) DeleteTokenizationPolicy(ctx context.Context, name string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodDelete,
+		"DeleteTokenPolicy/"+url.PathEscape(name)+"/", nil, response)
+}
+
+// CreateMaskPolicy creates or, if IsNew is false, updates a mask policy
+func (c *Client) CreateMaskPolicy(ctx context.Context, policy MaskPolicy) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "CreateMaskPolicy/", policy, response)
+}
+
+// GetMaskPolicy returns the mask policy name
+func (c *Client) GetMaskPolicy(ctx context.Context, name string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet,
+		"GetMaskPolicy/"+url.PathEscape(name)+"/", nil, response)
+}
+
+// ListMaskPolicies lists the mask policies
+func (c *Client) ListMaskPolicies(ctx context.Context,
+	options PolicyListOptions) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodGet, "GetMaskPolicies"+options.query(),
+		nil, response)
+}
+
+// DeleteMaskPolicy deletes the mask policy name
+func (c *Client) DeleteMaskPolicy(ctx context.Context, name string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodDelete,
+		"DeleteMaskPolicy/"+url.PathEscape(name)+"/", nil, response)
+}
+
+// GetTokenizationInfo returns information on the tokenization vault
+func (c *Client) GetTokenizationInfo(ctx context.Context) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "GetTokenizationInfo/",
+		struct{}{}, response)
+}
+
+// GetTokenizationSettings returns the settings of the tokenization vault
+func (c *Client) GetTokenizationSettings(ctx context.Context) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "GetTokenizationSettings/",
+		struct{}{}, response)
+}
+
+// TokenizationSettings is the request of UpdateTokenizationSettings.
+// Nil settings are left unchanged.
+type TokenizationSettings struct {
+	DegradedModeAvailability *bool `json:"degraded_mode_availability,omitempty"`
+	OIDCEnabled              *bool `json:"oidc_enabled,omitempty"`
+	Revision                 int   `json:"revision"`
+}
+
+// UpdateTokenizationSettings updates the settings of the tokenization
+// vault
+func (c *Client) UpdateTokenizationSettings(ctx context.Context,
+	settings TokenizationSettings) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "UpdateTokenizationSettings/",
+		settings, response)
+}
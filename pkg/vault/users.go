@@ -0,0 +1,273 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+type usernameParams struct {
+	Username string `json:"username"`
+}
+
+type nameParams struct {
+	Name string `json:"name"`
+}
+
+// CreateLocalUserRequest is the request of CreateLocalUser. The email
+// address is the username of the user.
+type CreateLocalUserRequest struct {
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	AccountState bool   `json:"account_state"`
+}
+
+// CreateLocalUser creates a local user
+func (c *Client) CreateLocalUser(ctx context.Context,
+	request CreateLocalUserRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "CreateLocalUser/", request, response)
+}
+
+// UpdateLocalUserRequest is the request of UpdateLocalUser. Revision
+// is the revision of the user being updated. Empty fields are left
+// unchanged; AccountState enables or disables the user.
+type UpdateLocalUserRequest struct {
+	Username     string `json:"username"`
+	Revision     int    `json:"revision"`
+	Name         string `json:"name,omitempty"`
+	AccountState *bool  `json:"account_state,omitempty"`
+}
+
+// UpdateLocalUser updates a local user
+func (c *Client) UpdateLocalUser(ctx context.Context,
+	request UpdateLocalUserRequest) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "UpdateLocalUser/", request, response)
+}
+
+// GetLocalUser returns a local user by username or ID
+func (c *Client) GetLocalUser(ctx context.Context, username string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "GetLocalUser/",
+		usernameParams{username}, response)
+}
+
+// ListLocalUsers lists the local users
+func (c *Client) ListLocalUsers(ctx context.Context, options ListOptions) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "ListLocalUsers/", options, response)
+}
+
+// DeleteLocalUser deletes a local user by username or ID
+func (c *Client) DeleteLocalUser(ctx context.Context, username string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "DeleteLocalUser/",
+		usernameParams{username}, response)
+}
+
+// PersonalAccessToken is the request of CreatePersonalAccessToken and
+// UpdatePersonalAccessToken. Expiry is in seconds since the Unix epoch.
+// Updates leave nil fields unchanged.
+type PersonalAccessToken struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	Expiry      *int64  `json:"expiry,omitempty"`
+	Revoked     *bool   `json:"revoked,omitempty"`
+}
+
+// PersonalAccessTokenDetails is the response of GetPersonalAccessToken.
+// Expiry is given in seconds since the Unix epoch or as RFC 3339 time.
+type PersonalAccessTokenDetails struct {
+	Result
+	Name    string      `json:"name"`
+	User    string      `json:"user"`
+	Revoked bool        `json:"revoked"`
+	Expiry  interface{} `json:"expiry"`
+}
+
+// CreatePersonalAccessToken creates a personal access token of the
+// user
+func (c *Client) CreatePersonalAccessToken(ctx context.Context,
+	token PersonalAccessToken) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "CreatePersonalAccessToken/", token,
+		response)
+}
+
+// UpdatePersonalAccessToken updates a personal access token of the user
+func (c *Client) UpdatePersonalAccessToken(ctx context.Context,
+	token PersonalAccessToken) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "UpdatePersonalAccessToken/", token,
+		response)
+}
+
+// GetPersonalAccessToken returns a personal access token of the user
+func (c *Client) GetPersonalAccessToken(ctx context.Context,
+	name string) (*PersonalAccessTokenDetails, error) {
+	response := &PersonalAccessTokenDetails{}
+	return response, c.do(ctx, http.MethodPost, "GetPersonalAccessToken/",
+		nameParams{name}, response)
+}
+
+// ListPersonalAccessTokens lists the personal access tokens of the user
+func (c *Client) ListPersonalAccessTokens(ctx context.Context) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "ListPersonalAccessTokens/", nil,
+		response)
+}
+
+// DeletePersonalAccessToken deletes a personal access token of the user
+func (c *Client) DeletePersonalAccessToken(ctx context.Context,
+	name string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "DeletePersonalAccessToken/",
+		nameParams{name}, response)
+}
+
+// ADServer is an Active Directory server. CACert is the base64 encoded
+// PEM certificate of the CA of the server.
+type ADServer struct {
+	ServerURL   string `json:"server_url"`
+	TLS         bool   `json:"tls,omitempty"`
+	UserBaseDN  string `json:"user_base_dn,omitempty"`
+	GroupBaseDN string `json:"group_base_dn,omitempty"`
+	Timeout     int    `json:"timeout,omitempty"`
+	CACert      string `json:"cacert,omitempty"`
+}
+
+// ADSetting is the request of UpdateADSetting and ChangeADDomain.
+// Empty fields are left unchanged; ClearServiceAccount removes the
+// service account.
+type ADSetting struct {
+	Name                string     `json:"name,omitempty"`
+	ID                  string     `json:"ad_setting_id,omitempty"`
+	Revision            int        `json:"revision,omitempty"`
+	ServiceAccount      string     `json:"service_account,omitempty"`
+	ClearServiceAccount bool       `json:"-"`
+	ServicePassword     string     `json:"service_password,omitempty"`
+	UIDAttribute        string     `json:"uid_attribute,omitempty"`
+	Type                string     `json:"type,omitempty"`
+	NetBIOSName         string     `json:"netbios_name,omitempty"`
+	Servers             []ADServer `json:"servers,omitempty"`
+}
+
+// MarshalJSON sends a cleared service account as empty string
+func (s ADSetting) MarshalJSON() ([]byte, error) {
+	type setting ADSetting
+	if !s.ClearServiceAccount {
+		return json.Marshal(setting(s))
+	}
+	return json.Marshal(struct {
+		setting
+		ServiceAccount string `json:"service_account"`
+	}{setting: setting(s)})
+}
+
+// ADSettingResponse is the response of UpdateADSetting and
+// ChangeADDomain
+type ADSettingResponse struct {
+	Result
+	Name     string `json:"name"`
+	ID       string `json:"ad_setting_id"`
+	Revision int    `json:"revision"`
+}
+
+// ListADSettings lists the Active Directory settings
+func (c *Client) ListADSettings(ctx context.Context, options ListOptions) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "ListADSettings/", options, response)
+}
+
+// GetADSetting returns an Active Directory setting
+func (c *Client) GetADSetting(ctx context.Context, id string) (*Result, error) {
+	response := &Result{}
+	params := struct {
+		ID string `json:"ad_setting_id"`
+	}{id}
+	return response, c.do(ctx, http.MethodPost, "GetADSetting/", params, response)
+}
+
+// UpdateADSetting updates an Active Directory setting
+func (c *Client) UpdateADSetting(ctx context.Context,
+	setting ADSetting) (*ADSettingResponse, error) {
+	response := &ADSettingResponse{}
+	return response, c.do(ctx, http.MethodPost, "UpdateADSetting/", setting, response)
+}
+
+// ChangeADDomain changes the Active Directory domain of the vault
+func (c *Client) ChangeADDomain(ctx context.Context,
+	setting ADSetting) (*ADSettingResponse, error) {
+	response := &ADSettingResponse{}
+	return response, c.do(ctx, http.MethodPost, "ChangeADDomain/", setting, response)
+}
+
+// GetADUser looks up Active Directory users by name
+func (c *Client) GetADUser(ctx context.Context, name string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "GetADUser/", nameParams{name}, response)
+}
+
+// GetADGroup looks up Active Directory groups by name
+func (c *Client) GetADGroup(ctx context.Context, name string) (*Result, error) {
+	response := &Result{}
+	return response, c.do(ctx, http.MethodPost, "GetADGroup/", nameParams{name}, response)
+}
+
+// ADDomain is the Active Directory domain of UpdateTenantAuthMethodToAD
+type ADDomain struct {
+	DomainName         string     `json:"domain_name,omitempty"`
+	DomainType         string     `json:"type,omitempty"`
+	ServiceAccountName string     `json:"service_account_name,omitempty"`
+	ServiceAccountPw   string     `json:"service_account_pw,omitempty"`
+	UID                string     `json:"uid,omitempty"`
+	Servers            []ADServer `json:"servers,omitempty"`
+}
+
+// ADMember is an Active Directory user
+type ADMember struct {
+	CN                string `json:"cn,omitempty"`
+	DistinguishedName string `json:"distinguishedName,omitempty"`
+	Mail              string `json:"mail,omitempty"`
+	UPN               string `json:"upn,omitempty"`
+}
+
+// AuthMethodToADRequest is the request of UpdateTenantAuthMethodToAD.
+// InitialADMember becomes the first administrator of the vault.
+type AuthMethodToADRequest struct {
+	ADDomain        ADDomain `json:"ad_domain"`
+	Name            string   `json:"name"`
+	InitialADMember ADMember `json:"initial_admember"`
+}
+
+// AuthMethodToADResponse is the response of UpdateTenantAuthMethodToAD
+type AuthMethodToADResponse struct {
+	Result
+	Status string `json:"result"`
+}
+
+// UpdateTenantAuthMethodToAD makes Active Directory the authentication
+// method of the vault
+func (c *Client) UpdateTenantAuthMethodToAD(ctx context.Context,
+	request AuthMethodToADRequest) (*AuthMethodToADResponse, error) {
+	response := &AuthMethodToADResponse{}
+	return response, c.do(ctx, http.MethodPost, "UpdateTenantAuthMethodToAD/", request,
+		response)
+}
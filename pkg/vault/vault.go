@@ -0,0 +1,264 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vault is a client of the REST API of the Entrust
+// Cryptographic APIs Vault. It covers every endpoint cryptocli uses,
+// with a typed request and response per endpoint.
+//
+// A Client is bound to one vault server and one access token:
+//
+//	client := vault.NewClient("vault.example.com:443", token, http.DefaultClient)
+//	response, err := client.Encrypt(ctx, vault.EncryptRequest{
+//		KeyGUID: keyGUID, Data: data, Mode: "GCM"})
+//
+// Failed requests return *Error if the vault answered, so that callers
+// can tell error responses from network errors.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+)
+
+// APIVersion is the version of the REST API the package uses
+const APIVersion = "1.0"
+
+// AuthHeader carries the access token of a request
+const AuthHeader = "X-TOKEN-AUTH"
+
+const contentTypeJSON = "application/json"
+
+// Doer sends HTTP requests. *http.Client is a Doer; others may add
+// retries, logging or failover.
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// Client calls the vault REST API. A Client is safe for concurrent use.
+type Client struct {
+	server     string
+	token      string
+	httpClient Doer
+}
+
+// NewClient returns a Client of the vault at server, given as host or
+// host:port, that authenticates with the access token token. Requests
+// are sent with httpClient, or http.DefaultClient if it is nil.
+func NewClient(server, token string, httpClient Doer) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{server: server, token: token, httpClient: httpClient}
+}
+
+// Server returns the vault server of c
+func (c *Client) Server() string {
+	return c.server
+}
+
+// WithToken returns a copy of c that authenticates with token
+func (c *Client) WithToken(token string) *Client {
+	copied := *c
+	copied.token = token
+	return &copied
+}
+
+// Error is an error response of the vault
+type Error struct {
+	URL        string // request URL
+	StatusCode int    // e.g. 404
+	Status     string // e.g. "404 Not Found"
+	Body       []byte // JSON error of the vault, empty if none was sent
+}
+
+func (e *Error) Error() string {
+	if len(e.Body) > 0 {
+		return fmt.Sprintf("%s\n%s\n%s", e.URL, e.Status, string(e.Body))
+	}
+	return fmt.Sprintf("%s\n%s", e.URL, e.Status)
+}
+
+// Empty reports whether the vault sent no error details. The vault
+// answers 404 without a body to deny actions the user is not
+// permitted and to report resources it cannot find.
+func (e *Error) Empty() bool {
+	return len(e.Body) == 0
+}
+
+// Result holds the JSON body of a successful response. Every response
+// type embeds it, so the response can be passed on as the vault sent
+// it, including fields the response type does not cover.
+type Result struct {
+	body json.RawMessage
+}
+
+// JSON returns the response body, empty if the vault sent none
+func (r *Result) JSON() []byte {
+	return r.body
+}
+
+// Decode decodes the response body into v
+func (r *Result) Decode(v interface{}) error {
+	return json.Unmarshal(r.body, v)
+}
+
+func (r *Result) setBody(body []byte) {
+	r.body = body
+}
+
+type responseBody interface {
+	setBody(body []byte)
+}
+
+// url returns the URL of path, relative to the API root. path keeps
+// its trailing slash and query string as the endpoint expects them.
+func (c *Client) url(path string) string {
+	return fmt.Sprintf("https://%s/token/%s/%s", c.server, APIVersion, path)
+}
+
+// newRequest builds a request with the JSON encoding of params as body.
+// The body can be sent again, so that the Doer may retry the request.
+func (c *Client) newRequest(ctx context.Context, method, path string,
+	params interface{}) (*http.Request, error) {
+	var body []byte
+	if params != nil {
+		var err error
+		if body, err = json.Marshal(params); err != nil {
+			return nil, err
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.url(path),
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", contentTypeJSON)
+	if c.token != "" {
+		request.Header.Set(AuthHeader, c.token)
+	}
+	return request, nil
+}
+
+// do sends a request with the JSON encoding of params as body and
+// decodes the response into out
+func (c *Client) do(ctx context.Context, method, path string,
+	params interface{}, out responseBody) error {
+	request, err := c.newRequest(ctx, method, path, params)
+	if err != nil {
+		return err
+	}
+	return c.send(request, out)
+}
+
+// send sends request and decodes the response into out. Responses with
+// an error status or an error field are returned as *Error.
+func (c *Client) send(request *http.Request, out responseBody) error {
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) > 0 && !json.Valid(body) {
+		return fmt.Errorf("%s - invalid response\n\n%s", response.Status,
+			string(body))
+	}
+	if response.StatusCode >= http.StatusBadRequest || hasErrorField(body) {
+		return &Error{
+			URL:        request.URL.String(),
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Body:       body}
+	}
+
+	out.setBody(body)
+	if _, raw := out.(*Result); raw || len(body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s - invalid response - %v", response.Status, err)
+	}
+	return nil
+}
+
+// hasErrorField reports whether body is a JSON object with an error
+// field, which the vault also sends with status 200
+func hasErrorField(body []byte) bool {
+	var object map[string]json.RawMessage
+	if json.Unmarshal(body, &object) != nil {
+		return false
+	}
+	_, present := object["error"]
+	return present
+}
+
+// Download is a file sent by the vault. Body must be closed.
+type Download struct {
+	Name string // file name given by the vault
+	Body io.ReadCloser
+}
+
+// download sends a GET request for a file
+func (c *Client) download(ctx context.Context, path string) (*Download, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		request.Header.Set(AuthHeader, c.token)
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return nil, &Error{
+			URL:        request.URL.String(),
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			Body:       body}
+	}
+
+	_, params, err := mime.ParseMediaType(response.Header.Get("Content-Disposition"))
+	name := filepath.Base(params["filename"])
+	if err != nil || name == "." || name == string(filepath.Separator) {
+		response.Body.Close()
+		return nil, fmt.Errorf("filename not found")
+	}
+	return &Download{Name: name, Body: response.Body}, nil
+}
+
+// ListOptions pages through the lists of users, policies and AD
+// settings. Zero fields are not sent.
+type ListOptions struct {
+	Prefix    string   `json:"prefix,omitempty"`
+	Filters   string   `json:"filters,omitempty"`
+	MaxItems  int      `json:"max_items,omitempty"`
+	Fields    []string `json:"fields,omitempty"`
+	NextToken string   `json:"next_token,omitempty"`
+}
@@ -0,0 +1,184 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"bytes"
+	"cli/pkg/vault"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func init() {
+	addRoutes(
+		route{method: http.MethodPost, pattern: "GetAuditSetting",
+			handle: (*Vault).getAuditSettings},
+		route{method: http.MethodPost, pattern: "UpdateAuditSetting",
+			audit: "Audit settings updated", handle: (*Vault).updateAuditSettings},
+		route{method: http.MethodPost, pattern: "ListAuditMessages",
+			handle: (*Vault).listAuditMessages},
+		route{method: http.MethodPost, pattern: "GetAuditMessageTemplate",
+			handle: (*Vault).getAuditMessageTemplate},
+		route{method: http.MethodPost, pattern: "ListAuditMessageTemplates",
+			handle: (*Vault).listAuditMessageTemplates},
+		route{method: http.MethodGet, pattern: "GetAuditBundle",
+			audit: "Audit bundle downloaded", handle: (*Vault).downloadAuditBundle},
+	)
+}
+
+// auditSettings is the JSON of the audit settings. Retention is in
+// days, 0 keeping messages forever; the total size is not enforced.
+type auditSettings struct {
+	Retention int32 `json:"auditlog_retention"`
+	TotalSize int64 `json:"auditlog_totsize"`
+}
+
+// auditTemplate is the template of the audit messages of an endpoint
+type auditTemplate struct {
+	MessageID int    `json:"message_id"`
+	Template  string `json:"template"`
+}
+
+// auditTemplates returns the templates of the audited endpoints
+func auditTemplates() []auditTemplate {
+	templates := []auditTemplate{}
+	seen := map[string]bool{}
+	for _, rt := range routes {
+		if rt.audit != "" && !seen[rt.audit] {
+			seen[rt.audit] = true
+			templates = append(templates, auditTemplate{len(templates) + 1, rt.audit})
+		}
+	}
+	return templates
+}
+
+// addAuditMessage logs a request of user, failed if err is set
+func (v *Vault) addAuditMessage(user, message string, err error) {
+	info := map[string]interface{}{"status": "success"}
+	for _, template := range auditTemplates() {
+		if template.Template == message {
+			info["message_id"] = template.MessageID
+		}
+	}
+	if err != nil {
+		info["status"] = "failure"
+		info["error"] = err.Error()
+	}
+	v.audit = append(v.audit, vault.AuditMessage{CreatedAt: v.timestamp(), UserContext: user,
+		Message: message, Info: info})
+
+	if v.auditSettings.Retention > 0 {
+		oldest := v.now().AddDate(0, 0, -int(v.auditSettings.Retention)).
+			Format(time.RFC3339)
+		for len(v.audit) > 0 && v.audit[0].CreatedAt < oldest {
+			v.audit = v.audit[1:]
+		}
+	}
+}
+
+func (v *Vault) getAuditSettings(r *request) (interface{}, error) {
+	return v.auditSettings, nil
+}
+
+// updateAuditSettings answers with no content, as the vault does
+func (v *Vault) updateAuditSettings(r *request) (interface{}, error) {
+	var settings vault.AuditSettings
+	if err := r.decode(&settings); err != nil {
+		return nil, err
+	}
+	if settings.Retention != nil {
+		if *settings.Retention < 0 {
+			return nil, badRequest("Invalid retention %d", *settings.Retention)
+		}
+		v.auditSettings.Retention = *settings.Retention
+	}
+	if settings.TotalSize != nil {
+		if *settings.TotalSize < 0 {
+			return nil, badRequest("Invalid total size %d", *settings.TotalSize)
+		}
+		v.auditSettings.TotalSize = *settings.TotalSize
+	}
+	return nil, nil
+}
+
+// listAuditMessages lists the audit messages, newest first. The
+// filters are matched as text against the user and the message.
+func (v *Vault) listAuditMessages(r *request) (interface{}, error) {
+	var options vault.AuditListOptions
+	if err := r.decode(&options); err != nil {
+		return nil, err
+	}
+	filter := strings.ToLower(options.Filters)
+	messages := []interface{}{}
+	for i := len(v.audit) - 1; i >= 0; i-- {
+		message := v.audit[i]
+		if strings.Contains(strings.ToLower(message.UserContext+" "+message.Message),
+			filter) {
+			messages = append(messages, message)
+		}
+	}
+	page, nextToken, err := pageOf(messages, options.MaxItems, options.NextToken,
+		options.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"audit_messages": page, "next_token": nextToken}, nil
+}
+
+func (v *Vault) getAuditMessageTemplate(r *request) (interface{}, error) {
+	var request struct {
+		MessageID int `json:"message_id"`
+	}
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	templates := auditTemplates()
+	if request.MessageID < 1 || request.MessageID > len(templates) {
+		return nil, errNotFound
+	}
+	return templates[request.MessageID-1], nil
+}
+
+func (v *Vault) listAuditMessageTemplates(r *request) (interface{}, error) {
+	var options vault.AuditListOptions
+	if err := r.decode(&options); err != nil {
+		return nil, err
+	}
+	templates := []interface{}{}
+	for _, template := range auditTemplates() {
+		templates = append(templates, template)
+	}
+	page, nextToken, err := pageOf(templates, options.MaxItems, options.NextToken,
+		options.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"templates": page, "next_token": nextToken}, nil
+}
+
+// downloadAuditBundle sends the audit messages as a JSON Lines file
+func (v *Vault) downloadAuditBundle(r *request) (interface{}, error) {
+	var bundle bytes.Buffer
+	encoder := json.NewEncoder(&bundle)
+	for _, message := range v.audit {
+		encoder.Encode(message)
+	}
+	return &download{name: "audit-" + v.now().Format("20060102T150405Z") +
+		".jsonl", data: bundle.Bytes()}, nil
+}
@@ -0,0 +1,462 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	addRoutes(
+		route{method: http.MethodPost, pattern: "encrypt", handle: (*Vault).encrypt},
+		route{method: http.MethodPost, pattern: "decrypt", handle: (*Vault).decrypt},
+		route{method: http.MethodPost, pattern: "sign", handle: (*Vault).sign},
+		route{method: http.MethodPost, pattern: "verify", handle: (*Vault).verify},
+		route{method: http.MethodPost, pattern: "wrap", handle: (*Vault).wrap},
+		route{method: http.MethodPost, pattern: "unwrap", handle: (*Vault).unwrap},
+		route{method: http.MethodPost, pattern: "digest", handle: (*Vault).digest},
+		route{method: http.MethodPost, pattern: "mac/generate",
+			handle: (*Vault).generateMAC},
+		route{method: http.MethodPost, pattern: "mac/verify", handle: (*Vault).verifyMAC},
+		route{method: http.MethodPost, pattern: "batch/encrypt",
+			handle: batchEncrypt("encrypt")},
+		route{method: http.MethodPost, pattern: "batch/decrypt",
+			handle: batchEncrypt("decrypt")},
+		route{method: http.MethodPost, pattern: "batch/encrypt-decrypt",
+			handle: batchEncrypt("")},
+	)
+}
+
+// decodeBase64 decodes the base64 field name of a request
+func decodeBase64(name, value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, badRequest("Invalid %s - %v", name, err)
+	}
+	return data, nil
+}
+
+func encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// hashOf returns the hash a mode such as SHA-256 or HMAC-SHA512
+// names, SHA-256 if it names none
+func hashOf(mode string) (crypto.Hash, error) {
+	name := strings.NewReplacer("-", "", "_", "").Replace(strings.ToUpper(mode))
+	hashes := []struct {
+		name string
+		hash crypto.Hash
+	}{
+		{"SHA512", crypto.SHA512},
+		{"SHA384", crypto.SHA384},
+		{"SHA256", crypto.SHA256},
+		{"SHA224", crypto.SHA224},
+		{"SHA1", crypto.SHA1},
+	}
+	for _, h := range hashes {
+		if strings.Contains(name, h.name) {
+			return h.hash, nil
+		}
+	}
+	if strings.Contains(name, "SHA") {
+		return 0, badRequest("Unsupported mode %q", mode)
+	}
+	return crypto.SHA256, nil
+}
+
+// newGCM returns AES-GCM with the secret of version and nonces of
+// nonceSize bytes
+func newGCM(version *keyVersion, nonceSize int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(version.secret)
+	if err != nil {
+		return nil, err
+	}
+	if nonceSize == 0 {
+		return nil, badRequest("Invalid iv")
+	}
+	return cipher.NewGCMWithNonceSize(block, nonceSize)
+}
+
+// encryptGCM encrypts with AES-GCM. The data of the response is the
+// ciphertext followed by the tag, as Decrypt expects it.
+func (v *Vault) encryptGCM(request vault.EncryptRequest) (*vault.EncryptResponse, error) {
+	if !strings.EqualFold(request.Mode, "GCM") {
+		return nil, badRequest("Unsupported mode %q", request.Mode)
+	}
+	_, version, err := v.activeKey(request.KeyGUID, AlgorithmAES)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := decodeBase64("data", request.Data)
+	if err != nil {
+		return nil, err
+	}
+	aad, err := decodeBase64("aad", request.AAD)
+	if err != nil {
+		return nil, err
+	}
+	iv := v.random(12)
+	if request.IV != "" {
+		if iv, err = decodeBase64("iv", request.IV); err != nil {
+			return nil, err
+		}
+	}
+	gcm, err := newGCM(version, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := gcm.Seal(nil, iv, plaintext, aad)
+	tag := ciphertext[len(ciphertext)-gcm.Overhead():]
+	return &vault.EncryptResponse{Data: encode(ciphertext), IV: encode(iv),
+		Tag: encode(tag)}, nil
+}
+
+// decryptGCM decrypts data encrypted by encryptGCM. With a key GUID,
+// every version of the key is tried, latest first.
+func (v *Vault) decryptGCM(request vault.EncryptRequest) (*vault.EncryptResponse, error) {
+	if !strings.EqualFold(request.Mode, "GCM") {
+		return nil, badRequest("Unsupported mode %q", request.Mode)
+	}
+	k, version, err := v.activeKey(request.KeyGUID, AlgorithmAES)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := decodeBase64("data", request.Data)
+	if err != nil {
+		return nil, err
+	}
+	aad, err := decodeBase64("aad", request.AAD)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := decodeBase64("iv", request.IV)
+	if err != nil {
+		return nil, err
+	}
+	versions := []*keyVersion{version}
+	if k.guid == request.KeyGUID {
+		versions = k.versions
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		gcm, err := newGCM(versions[i], len(iv))
+		if err != nil {
+			return nil, err
+		}
+		if plaintext, err := gcm.Open(nil, iv, ciphertext, aad); err == nil {
+			return &vault.EncryptResponse{Data: encode(plaintext)}, nil
+		}
+	}
+	return nil, badRequest("Decryption failed - the data, iv or aad is not authentic")
+}
+
+func (v *Vault) encrypt(r *request) (interface{}, error) {
+	var request vault.EncryptRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	return v.encryptGCM(request)
+}
+
+func (v *Vault) decrypt(r *request) (interface{}, error) {
+	var request vault.EncryptRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	return v.decryptGCM(request)
+}
+
+// batchEncrypt returns the handler of a batch of records to encrypt
+// or decrypt, as operation or, if empty, the record says
+func batchEncrypt(operation string) handler {
+	return func(v *Vault, r *request) (interface{}, error) {
+		var records []vault.BatchEncryptRequest
+		if err := r.decode(&records); err != nil {
+			return nil, err
+		}
+		results := make([]vault.BatchResult, len(records))
+		for i, record := range records {
+			request := vault.EncryptRequest{KeyGUID: record.KeyGUID, Data: record.Data,
+				Mode: record.Mode, IV: record.IV, AAD: record.AAD}
+			var response *vault.EncryptResponse
+			var err error
+			op := operation
+			if op == "" {
+				op = record.Operation
+			}
+			switch op {
+			case "encrypt":
+				response, err = v.encryptGCM(request)
+			case "decrypt":
+				response, err = v.decryptGCM(request)
+			default:
+				err = badRequest("Invalid operation %q", record.Operation)
+			}
+			if err != nil {
+				results[i].Error = batchError(err)
+				continue
+			}
+			results[i] = vault.BatchResult{Data: response.Data, IV: response.IV,
+				Tag: response.Tag}
+		}
+		return results, nil
+	}
+}
+
+// batchError returns the error of a failed record
+func batchError(err error) json.RawMessage {
+	message, _ := json.Marshal(err.Error())
+	return message
+}
+
+// signDigest returns the digest signed for data with mode
+func signDigest(mode, data string) (crypto.Hash, []byte, error) {
+	hash, err := hashOf(mode)
+	if err != nil {
+		return 0, nil, err
+	}
+	message, err := decodeBase64("data", data)
+	if err != nil {
+		return 0, nil, err
+	}
+	h := hash.New()
+	h.Write(message)
+	return hash, h.Sum(nil), nil
+}
+
+// signerOpts returns the RSA padding of mode, PKCS #1 v1.5 unless
+// mode names PSS
+func signerOpts(mode string, hash crypto.Hash) crypto.SignerOpts {
+	if strings.Contains(strings.ToUpper(mode), "PSS") {
+		return &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	}
+	return hash
+}
+
+// sign signs the data with an RSA or EC key. The mode names the hash,
+// SHA-256 by default, and for RSA keys PSS padding.
+func (v *Vault) sign(r *request) (interface{}, error) {
+	var request vault.SignRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	_, version, err := v.activeKey(request.KeyGUID, AlgorithmRSA, AlgorithmEC)
+	if err != nil {
+		return nil, err
+	}
+	hash, digest, err := signDigest(request.Mode, request.Data)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := version.private.Sign(rand.Reader, digest,
+		signerOpts(request.Mode, hash))
+	if err != nil {
+		return nil, badRequest("Signing failed - %v", err)
+	}
+	return &vault.SignResponse{Signature: encode(signature)}, nil
+}
+
+// verify verifies a signature of sign. With a key GUID, the signature
+// of any version of the key is accepted.
+func (v *Vault) verify(r *request) (interface{}, error) {
+	var request vault.VerifyRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	k, version, err := v.activeKey(request.KeyGUID, AlgorithmRSA, AlgorithmEC)
+	if err != nil {
+		return nil, err
+	}
+	hash, digest, err := signDigest(request.Mode, request.Data)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := decodeBase64("signature", request.Signature)
+	if err != nil {
+		return nil, err
+	}
+	versions := []*keyVersion{version}
+	if k.guid == request.KeyGUID {
+		versions = k.versions
+	}
+	verified := false
+	for _, version := range versions {
+		switch public := version.private.Public().(type) {
+		case *rsa.PublicKey:
+			if opts, ok := signerOpts(request.Mode, hash).(*rsa.PSSOptions); ok {
+				verified = rsa.VerifyPSS(public, hash, digest, signature, opts) == nil
+			} else {
+				verified = rsa.VerifyPKCS1v15(public, hash, digest, signature) == nil
+			}
+		case *ecdsa.PublicKey:
+			verified = ecdsa.VerifyASN1(public, digest, signature)
+		}
+		if verified {
+			break
+		}
+	}
+	return &vault.VerifyResponse{Verified: verified}, nil
+}
+
+// mac returns the HMAC of data with the secret of version, the hash
+// named by mode
+func mac(version *keyVersion, mode, data string) ([]byte, error) {
+	hash, err := hashOf(mode)
+	if err != nil {
+		return nil, err
+	}
+	message, err := decodeBase64("data", data)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(hash.New, version.secret)
+	h.Write(message)
+	return h.Sum(nil), nil
+}
+
+func (v *Vault) generateMAC(r *request) (interface{}, error) {
+	var request vault.MACRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	_, version, err := v.activeKey(request.KeyGUID, AlgorithmHMAC, AlgorithmAES)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := mac(version, request.Mode, request.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &vault.MACResponse{MAC: encode(sum)}, nil
+}
+
+func (v *Vault) verifyMAC(r *request) (interface{}, error) {
+	var request vault.MACRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	k, version, err := v.activeKey(request.KeyGUID, AlgorithmHMAC, AlgorithmAES)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := decodeBase64("mac", request.MAC)
+	if err != nil {
+		return nil, err
+	}
+	versions := []*keyVersion{version}
+	if k.guid == request.KeyGUID {
+		versions = k.versions
+	}
+	for _, version := range versions {
+		sum, err := mac(version, request.Mode, request.Data)
+		if err != nil {
+			return nil, err
+		}
+		if hmac.Equal(sum, expected) {
+			return &vault.VerifyResponse{Verified: true}, nil
+		}
+	}
+	return &vault.VerifyResponse{Verified: false}, nil
+}
+
+func (v *Vault) digest(r *request) (interface{}, error) {
+	var request vault.DigestRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	if request.Mode == "" {
+		return nil, badRequest("mode is required")
+	}
+	hash, digest, err := signDigest(request.Mode, request.Data)
+	if err != nil {
+		return nil, err
+	}
+	if !hash.Available() {
+		return nil, badRequest("Unsupported mode %q", request.Mode)
+	}
+	return &vault.DigestResponse{Digest: encode(digest)}, nil
+}
+
+// wrap wraps a key with AES-GCM under an AES key. The wrapped key is
+// the nonce followed by the ciphertext and tag.
+func (v *Vault) wrap(r *request) (interface{}, error) {
+	var request vault.SignRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	_, version, err := v.activeKey(request.KeyGUID, AlgorithmAES)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decodeBase64("data", request.Data)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(version, 12)
+	if err != nil {
+		return nil, err
+	}
+	nonce := v.random(gcm.NonceSize())
+	return &vault.WrapResponse{Data: encode(gcm.Seal(nonce, nonce, data, nil))}, nil
+}
+
+func (v *Vault) unwrap(r *request) (interface{}, error) {
+	var request vault.SignRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	k, version, err := v.activeKey(request.KeyGUID, AlgorithmAES)
+	if err != nil {
+		return nil, err
+	}
+	data, err := decodeBase64("data", request.Data)
+	if err != nil {
+		return nil, err
+	}
+	versions := []*keyVersion{version}
+	if k.guid == request.KeyGUID {
+		versions = k.versions
+	}
+	for _, version := range versions {
+		gcm, err := newGCM(version, 12)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < gcm.NonceSize() {
+			break
+		}
+		nonce := data[:gcm.NonceSize()]
+		if key, err := gcm.Open(nil, nonce, data[gcm.NonceSize():], nil); err == nil {
+			return &vault.WrapResponse{Data: encode(key)}, nil
+		}
+	}
+	return nil, badRequest("Unwrapping failed - the data is not authentic")
+}
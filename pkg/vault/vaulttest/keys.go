@@ -0,0 +1,699 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"bytes"
+	"cli/pkg/vault"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"hash"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key states
+const (
+	KeyStateActive          = "Active"
+	KeyStateDeactivated     = "Deactivated"
+	KeyStatePendingDeletion = "Pending Deletion"
+)
+
+// Cryptographic algorithms of keys
+const (
+	AlgorithmAES  = "AES"
+	AlgorithmHMAC = "HMAC"
+	AlgorithmRSA  = "RSA"
+	AlgorithmEC   = "EC"
+)
+
+func init() {
+	addRoutes(
+		route{method: http.MethodPost, pattern: "key", audit: "Key created",
+			handle: (*Vault).createKey},
+		route{method: http.MethodPost, pattern: "generate_key_csr",
+			audit:  "Key generated with certificate signing request",
+			handle: (*Vault).generateKeyCSR},
+		route{method: http.MethodPost, pattern: "key_import", audit: "Key imported",
+			handle: (*Vault).importKey},
+		route{method: http.MethodPost, pattern: "clear_key_import", audit: "Key imported",
+			handle: (*Vault).importClearKey},
+		route{method: http.MethodGet, pattern: "key/{}", handle: (*Vault).getKey},
+		route{method: http.MethodPatch, pattern: "key/{}", audit: "Key updated",
+			handle: (*Vault).setKeyDescription},
+		route{method: http.MethodGet, pattern: "key/{}/value", audit: "Key value read",
+			handle: (*Vault).getKeyValue},
+		route{method: http.MethodGet, pattern: "key/{}/versions",
+			handle: (*Vault).getKeyVersions},
+		route{method: http.MethodGet, pattern: "keys/{}", handle: (*Vault).listKeys},
+		route{method: http.MethodPost, pattern: "key/{}/rotate", audit: "Key rotated",
+			handle: (*Vault).rotateKey},
+		route{method: http.MethodPost, pattern: "key/{}/state", audit: "Key state changed",
+			handle: (*Vault).updateKeyState},
+		route{method: http.MethodPost, pattern: "key/{}/delete",
+			audit: "Key deletion scheduled", handle: (*Vault).scheduleKeyDeletion},
+		route{method: http.MethodDelete, pattern: "key/{}/purge", audit: "Key purged",
+			handle: (*Vault).purgeKey},
+		route{method: http.MethodPost, pattern: "key/{}/export", audit: "Key exported",
+			handle: (*Vault).exportKey},
+		route{method: http.MethodGet, pattern: "key/{}/export/public",
+			handle: (*Vault).exportPublicKey},
+		route{method: http.MethodGet, pattern: "GetKeysetGUID",
+			handle: (*Vault).getKeysetGUID},
+		route{method: http.MethodGet, pattern: "GetHSMInfo", handle: (*Vault).getHSMInfo},
+		route{method: http.MethodGet, pattern: "GetPlatformInfo",
+			handle: (*Vault).getPlatformInfo},
+		route{method: http.MethodPost, pattern: "EnableKeysetHSM",
+			audit: "HSM enabled for keyset", handle: (*Vault).enableKeysetHSM},
+	)
+}
+
+type key struct {
+	guid         string
+	name         string
+	description  string
+	cipher       string
+	spec         cipherSpec
+	state        string
+	createdAt    string
+	deletionDate string
+	versions     []*keyVersion // oldest first
+}
+
+// keyVersion is a version of a key, with its own GUID. Symmetric keys
+// hold secret, asymmetric keys private.
+type keyVersion struct {
+	guid      string
+	version   int
+	createdAt string
+	secret    []byte
+	private   crypto.Signer
+}
+
+func (k *key) latest() *keyVersion {
+	return k.versions[len(k.versions)-1]
+}
+
+// keyDetails is the JSON of a key
+type keyDetails struct {
+	KeyGUID                string `json:"key_guid"`
+	Name                   string `json:"name"`
+	Description            string `json:"description"`
+	KeysetGUID             string `json:"keyset_guid"`
+	Cipher                 string `json:"cipher"`
+	CryptographicAlgorithm string `json:"cryptographic_algorithm"`
+	KeyLength              int    `json:"key_length"`
+	Status                 string `json:"status"`
+	Version                int    `json:"version"`
+	VersionGUID            string `json:"version_guid"`
+	CreatedAt              string `json:"created_at"`
+	DeletionDate           string `json:"deletion_date,omitempty"`
+}
+
+func (v *Vault) keyDetails(k *key) keyDetails {
+	return keyDetails{
+		KeyGUID:                k.guid,
+		Name:                   k.name,
+		Description:            k.description,
+		KeysetGUID:             v.keysetGUID,
+		Cipher:                 k.cipher,
+		CryptographicAlgorithm: k.spec.algorithm,
+		KeyLength:              k.spec.bits,
+		Status:                 k.state,
+		Version:                k.latest().version,
+		VersionGUID:            k.latest().guid,
+		CreatedAt:              k.createdAt,
+		DeletionDate:           k.deletionDate}
+}
+
+// cipherSpec is the algorithm and size of the keys of a cipher
+type cipherSpec struct {
+	algorithm string
+	bits      int
+}
+
+// parseCipher parses a cipher such as AES-256, HMAC-SHA256, RSA-2048
+// or EC-P256. The size defaults to 256 bits for AES, HMAC and EC and
+// 2048 for RSA.
+func parseCipher(cipher string) (cipherSpec, error) {
+	name := strings.NewReplacer("-", "", "_", "").Replace(strings.ToUpper(cipher))
+	sizes := map[string][]int{
+		AlgorithmAES:  {256, 128, 192},
+		AlgorithmHMAC: {256, 384, 512},
+		AlgorithmRSA:  {2048, 3072, 4096},
+		AlgorithmEC:   {256, 384, 521},
+	}
+	for _, algorithm := range []string{AlgorithmAES, AlgorithmHMAC, AlgorithmRSA,
+		"ECDSA", AlgorithmEC} {
+		if !strings.HasPrefix(name, algorithm) {
+			continue
+		}
+		size := strings.TrimPrefix(name, algorithm)
+		if algorithm == "ECDSA" {
+			algorithm = AlgorithmEC
+		}
+		size = strings.TrimPrefix(strings.TrimPrefix(size, "SHA"), "P")
+		if size == "" {
+			return cipherSpec{algorithm, sizes[algorithm][0]}, nil
+		}
+		bits, _ := strconv.Atoi(size)
+		for _, supported := range sizes[algorithm] {
+			if bits == supported {
+				return cipherSpec{algorithm, bits}, nil
+			}
+		}
+		break
+	}
+	return cipherSpec{}, badRequest("Unsupported cipher %q", cipher)
+}
+
+func (s cipherSpec) symmetric() bool {
+	return s.algorithm == AlgorithmAES || s.algorithm == AlgorithmHMAC
+}
+
+// generate returns a version of a key of spec with new key material
+func (v *Vault) generate(s cipherSpec) (*keyVersion, error) {
+	version := &keyVersion{guid: v.newGUID(), createdAt: v.timestamp()}
+	var err error
+	switch s.algorithm {
+	case AlgorithmRSA:
+		version.private, err = rsa.GenerateKey(rand.Reader, s.bits)
+	case AlgorithmEC:
+		curve := map[int]elliptic.Curve{256: elliptic.P256(), 384: elliptic.P384(),
+			521: elliptic.P521()}[s.bits]
+		version.private, err = ecdsa.GenerateKey(curve, rand.Reader)
+	default:
+		version.secret = v.random(s.bits / 8)
+	}
+	return version, err
+}
+
+// addKey adds a key named name with its first version
+func (v *Vault) addKey(name, description, keysetGUID, cipher string, spec cipherSpec,
+	version *keyVersion) (*key, error) {
+	if name == "" {
+		return nil, badRequest("Key name is required")
+	}
+	if keysetGUID != "" && keysetGUID != v.keysetGUID {
+		return nil, errNotFound
+	}
+	for _, k := range v.keys {
+		if k.name == name {
+			return nil, errorf(http.StatusConflict, "Key %s already exists", name)
+		}
+	}
+	version.version = 1
+	k := &key{guid: v.newGUID(), name: name, description: description, cipher: cipher,
+		spec: spec, state: KeyStateActive, createdAt: v.timestamp(),
+		versions: []*keyVersion{version}}
+	v.keys[k.guid] = k
+	return k, nil
+}
+
+// findKey returns a key and its version by key GUID, selecting the
+// latest version, or by key version GUID
+func (v *Vault) findKey(guid string) (*key, *keyVersion, error) {
+	if k, ok := v.keys[guid]; ok {
+		return k, k.latest(), nil
+	}
+	for _, k := range v.keys {
+		for _, version := range k.versions {
+			if version.guid == guid {
+				return k, version, nil
+			}
+		}
+	}
+	return nil, nil, errNotFound
+}
+
+// activeKey returns the key and version of guid for a cryptographic
+// operation, which the key must support
+func (v *Vault) activeKey(guid string, algorithms ...string) (*key, *keyVersion, error) {
+	k, version, err := v.findKey(guid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if k.state != KeyStateActive {
+		return nil, nil, badRequest("Key %s is not active", k.name)
+	}
+	for _, algorithm := range algorithms {
+		if k.spec.algorithm == algorithm {
+			return k, version, nil
+		}
+	}
+	return nil, nil, badRequest("Key %s of cipher %s does not support the operation",
+		k.name, k.cipher)
+}
+
+func (v *Vault) createKey(r *request) (interface{}, error) {
+	var request vault.CreateKeyRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	spec, err := parseCipher(request.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	version, err := v.generate(spec)
+	if err != nil {
+		return nil, err
+	}
+	k, err := v.addKey(request.Name, request.Description, request.KeysetGUID,
+		request.Cipher, spec, version)
+	if err != nil {
+		return nil, err
+	}
+	return v.keyDetails(k), nil
+}
+
+func (v *Vault) generateKeyCSR(r *request) (interface{}, error) {
+	var request vault.GenerateKeyRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	spec, err := parseCipher(request.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	if spec.symmetric() {
+		return nil, badRequest("Cipher %s is not asymmetric", request.Cipher)
+	}
+	version, err := v.generate(spec)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.CertificateRequest{Subject: parseDN(request.SubjectDN)}
+	for _, san := range strings.Split(request.SANs, ",") {
+		san = strings.TrimSpace(san)
+		switch ip := net.ParseIP(san); {
+		case san == "":
+		case ip != nil:
+			template.IPAddresses = append(template.IPAddresses, ip)
+		case strings.Contains(san, "@"):
+			template.EmailAddresses = append(template.EmailAddresses, san)
+		default:
+			template.DNSNames = append(template.DNSNames, san)
+		}
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, template, version.private)
+	if err != nil {
+		return nil, badRequest("Error creating certificate signing request - %v", err)
+	}
+	name := template.Subject.CommonName
+	if name == "" {
+		name = "key-" + version.guid
+	}
+	k, err := v.addKey(name, "", request.KeysetGUID, request.Cipher, spec, version)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		keyDetails
+		CSR string `json:"csr"`
+	}{v.keyDetails(k), string(pem.EncodeToMemory(&pem.Block{
+		Type: "CERTIFICATE REQUEST", Bytes: csr}))}, nil
+}
+
+// parseDN parses a distinguished name such as "CN=app,O=Example"
+func parseDN(dn string) pkix.Name {
+	name := pkix.Name{}
+	for _, attribute := range strings.Split(dn, ",") {
+		pair := strings.SplitN(attribute, "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		value := strings.TrimSpace(pair[1])
+		switch strings.ToUpper(strings.TrimSpace(pair[0])) {
+		case "CN":
+			name.CommonName = value
+		case "O":
+			name.Organization = append(name.Organization, value)
+		case "OU":
+			name.OrganizationalUnit = append(name.OrganizationalUnit, value)
+		case "C":
+			name.Country = append(name.Country, value)
+		case "ST":
+			name.Province = append(name.Province, value)
+		case "L":
+			name.Locality = append(name.Locality, value)
+		}
+	}
+	return name
+}
+
+// oaepHash returns the hash of RSA-OAEP key wrapping, SHA-1 unless
+// SHA-256 is requested
+func oaepHash(sha256Requested bool) hash.Hash {
+	if sha256Requested {
+		return sha256.New()
+	}
+	return sha1.New()
+}
+
+func (v *Vault) importKey(r *request) (interface{}, error) {
+	var request vault.ImportKeyRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	spec, err := parseCipher(request.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	if !spec.symmetric() {
+		return nil, badRequest("Only symmetric keys can be imported wrapped")
+	}
+	_, wrappingKey, err := v.activeKey(request.WrappingKeyGUID, AlgorithmRSA)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(request.KeyMaterial)
+	if err != nil {
+		return nil, badRequest("Invalid key material - %v", err)
+	}
+	secret, err := rsa.DecryptOAEP(oaepHash(request.SHA256 != nil && *request.SHA256),
+		rand.Reader, wrappingKey.private.(*rsa.PrivateKey), wrapped, nil)
+	if err != nil {
+		return nil, badRequest("Error unwrapping key material - %v", err)
+	}
+	return v.importSecret(request.Name, request.Description, request.KeysetGUID,
+		request.Cipher, spec, secret)
+}
+
+func (v *Vault) importClearKey(r *request) (interface{}, error) {
+	var request vault.ImportClearKeyRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	spec, err := parseCipher(request.Cipher)
+	if err != nil {
+		return nil, err
+	}
+	if !spec.symmetric() {
+		return nil, badRequest("Only symmetric keys can be imported")
+	}
+	secret, err := base64.StdEncoding.DecodeString(request.KeyMaterial)
+	if err != nil {
+		return nil, badRequest("Invalid key material - %v", err)
+	}
+	return v.importSecret(request.Name, request.Description, request.KeysetGUID,
+		request.Cipher, spec, secret)
+}
+
+func (v *Vault) importSecret(name, description, keysetGUID, cipher string,
+	spec cipherSpec, secret []byte) (interface{}, error) {
+	if len(secret)*8 != spec.bits && spec.algorithm == AlgorithmAES {
+		return nil, badRequest("Key material is not a %d bit key", spec.bits)
+	}
+	k, err := v.addKey(name, description, keysetGUID, cipher, spec,
+		&keyVersion{guid: v.newGUID(), createdAt: v.timestamp(), secret: secret})
+	if err != nil {
+		return nil, err
+	}
+	return v.keyDetails(k), nil
+}
+
+func (v *Vault) getKey(r *request) (interface{}, error) {
+	k, _, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	return v.keyDetails(k), nil
+}
+
+func (v *Vault) setKeyDescription(r *request) (interface{}, error) {
+	k, _, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		Description *string `json:"description"`
+	}
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	if request.Description == nil {
+		return nil, badRequest("description is required")
+	}
+	k.description = *request.Description
+	return v.keyDetails(k), nil
+}
+
+func (v *Vault) getKeyValue(r *request) (interface{}, error) {
+	k, version, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	material := version.secret
+	if material == nil {
+		if material, err = x509.MarshalPKCS8PrivateKey(version.private); err != nil {
+			return nil, err
+		}
+	}
+	return map[string]interface{}{
+		"key_guid":     k.guid,
+		"version":      version.version,
+		"key_material": base64.StdEncoding.EncodeToString(material)}, nil
+}
+
+func (v *Vault) getKeyVersions(r *request) (interface{}, error) {
+	k, _, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	versions := []map[string]interface{}{}
+	for _, version := range k.versions {
+		versions = append(versions, map[string]interface{}{
+			"version_guid": version.guid,
+			"version":      version.version,
+			"created_at":   version.createdAt})
+	}
+	return map[string]interface{}{"key_guid": k.guid, "versions": versions}, nil
+}
+
+func (v *Vault) listKeys(r *request) (interface{}, error) {
+	if r.params[0] != v.keysetGUID {
+		return nil, errNotFound
+	}
+	var options vault.ListKeysOptions
+	if err := r.decode(&options); err != nil {
+		return nil, err
+	}
+	keys := []keyDetails{}
+	for _, k := range v.keys {
+		if options.CryptographicAlgorithm != "" &&
+			!strings.EqualFold(options.CryptographicAlgorithm, k.spec.algorithm) {
+			continue
+		}
+		if options.Status != "" && !strings.EqualFold(options.Status, k.state) {
+			continue
+		}
+		keys = append(keys, v.keyDetails(k))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+	return map[string]interface{}{"keys": keys}, nil
+}
+
+func (v *Vault) rotateKey(r *request) (interface{}, error) {
+	k, _, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	if k.state != KeyStateActive {
+		return nil, badRequest("Key %s is not active", k.name)
+	}
+	version, err := v.generate(k.spec)
+	if err != nil {
+		return nil, err
+	}
+	version.version = k.latest().version + 1
+	k.versions = append(k.versions, version)
+	return v.keyDetails(k), nil
+}
+
+func (v *Vault) updateKeyState(r *request) (interface{}, error) {
+	k, _, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		State string `json:"state"`
+	}
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	if k.state == KeyStatePendingDeletion {
+		return nil, errorf(http.StatusConflict, "Key %s is pending deletion", k.name)
+	}
+	switch strings.ToLower(request.State) {
+	case "enable":
+		k.state = KeyStateActive
+	case "disable":
+		k.state = KeyStateDeactivated
+	default:
+		return nil, badRequest("Invalid key state %q", request.State)
+	}
+	return v.keyDetails(k), nil
+}
+
+func (v *Vault) scheduleKeyDeletion(r *request) (interface{}, error) {
+	k, _, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	var request vault.DeleteKeyRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(request.Operation) {
+	case "schedule_destroy":
+		if request.RetentionPeriod < 1 {
+			return nil, badRequest("Invalid retention period %d", request.RetentionPeriod)
+		}
+		k.state = KeyStatePendingDeletion
+		k.deletionDate = v.now().AddDate(0, 0, request.RetentionPeriod).
+			Format(time.RFC3339)
+	case "cancel_destroy":
+		if k.state != KeyStatePendingDeletion {
+			return nil, errorf(http.StatusConflict, "Key %s is not pending deletion",
+				k.name)
+		}
+		k.state = KeyStateDeactivated
+		k.deletionDate = ""
+	default:
+		return nil, badRequest("Invalid operation %q", request.Operation)
+	}
+	return v.keyDetails(k), nil
+}
+
+func (v *Vault) purgeKey(r *request) (interface{}, error) {
+	k, ok := v.keys[r.params[0]]
+	if !ok {
+		return nil, errNotFound
+	}
+	delete(v.keys, k.guid)
+	return map[string]string{"result": "Key " + k.name + " purged"}, nil
+}
+
+func (v *Vault) exportKey(r *request) (interface{}, error) {
+	k, version, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	if version.secret == nil {
+		return nil, badRequest("Only symmetric keys can be exported")
+	}
+	publicKey, err := multipartFile(r, "public_key")
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		return nil, badRequest("public_key is not a PEM file")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, badRequest("Invalid public key - %v", err)
+	}
+	rsaKey, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, badRequest("public_key is not an RSA key")
+	}
+	wrapped, err := rsa.EncryptOAEP(oaepHash(r.query.Get("sha256") == "yes"),
+		rand.Reader, rsaKey, version.secret, nil)
+	if err != nil {
+		return nil, badRequest("Error wrapping key - %v", err)
+	}
+	return map[string]interface{}{
+		"key_guid":    k.guid,
+		"version":     version.version,
+		"wrapped_key": base64.StdEncoding.EncodeToString(wrapped)}, nil
+}
+
+// multipartFile returns the file field of a multipart/form-data body
+func multipartFile(r *request, field string) ([]byte, error) {
+	_, params, err := mime.ParseMediaType(r.header.Get("Content-Type"))
+	if err != nil || params["boundary"] == "" {
+		return nil, badRequest("Expected a multipart/form-data request")
+	}
+	reader := multipart.NewReader(bytes.NewReader(r.body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, badRequest("%s is required", field)
+		}
+		if part.FormName() == field {
+			return io.ReadAll(part)
+		}
+	}
+}
+
+func (v *Vault) exportPublicKey(r *request) (interface{}, error) {
+	k, version, err := v.findKey(r.params[0])
+	if err != nil {
+		return nil, err
+	}
+	if version.private == nil {
+		return nil, badRequest("Key %s is not asymmetric", k.name)
+	}
+	der, err := x509.MarshalPKIXPublicKey(version.private.Public())
+	if err != nil {
+		return nil, err
+	}
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if r.query.Get("download") == "yes" {
+		return &download{name: k.name + ".pem", data: publicKey}, nil
+	}
+	return map[string]interface{}{
+		"key_guid":   k.guid,
+		"version":    version.version,
+		"public_key": string(publicKey)}, nil
+}
+
+func (v *Vault) getKeysetGUID(r *request) (interface{}, error) {
+	return map[string]string{"keyset_guid": v.keysetGUID}, nil
+}
+
+func (v *Vault) getHSMInfo(r *request) (interface{}, error) {
+	return map[string]interface{}{"hsm_enabled": false, "partitions": []string{}}, nil
+}
+
+func (v *Vault) getPlatformInfo(r *request) (interface{}, error) {
+	return map[string]interface{}{
+		"product": "Mock Cryptographic APIs Vault",
+		"version": vault.APIVersion,
+		"nodes":   []string{}}, nil
+}
+
+func (v *Vault) enableKeysetHSM(r *request) (interface{}, error) {
+	return nil, badRequest("The mock vault has no HSM")
+}
@@ -0,0 +1,283 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	addRoutes(
+		route{method: http.MethodPost, pattern: "CreatePolicy",
+			audit: "Access policy created", handle: (*Vault).createAccessPolicy},
+		route{method: http.MethodPost, pattern: "UpdatePolicy",
+			audit: "Access policy updated", handle: (*Vault).updateAccessPolicy},
+		route{method: http.MethodPost, pattern: "GetPolicy",
+			handle: (*Vault).getAccessPolicy},
+		route{method: http.MethodPost, pattern: "ListPolicies",
+			handle: (*Vault).listAccessPolicies},
+		route{method: http.MethodPost, pattern: "ListPolicyVersions",
+			handle: (*Vault).listPolicyVersions},
+		route{method: http.MethodPost, pattern: "SetPolicyVersion",
+			audit: "Access policy version set", handle: (*Vault).setPolicyVersion},
+		route{method: http.MethodPost, pattern: "DeletePolicy",
+			audit: "Access policy deleted", handle: (*Vault).deleteAccessPolicy},
+	)
+}
+
+// accessPolicy is an access policy with its versions. The policy is
+// not enforced.
+type accessPolicy struct {
+	id       string
+	revision int // incremented by every change
+	current  int // current version
+	versions []policyVersion
+}
+
+type policyVersion struct {
+	vault.AccessPolicy
+	createdAt string
+}
+
+// policyDetails is the JSON of a version of an access policy
+type policyDetails struct {
+	PolicyID  string `json:"policy_id"`
+	Revision  int    `json:"revision"`
+	Version   int    `json:"version"`
+	Current   bool   `json:"current"`
+	CreatedAt string `json:"created_at"`
+	vault.AccessPolicy
+}
+
+func (p *accessPolicy) details(version int) policyDetails {
+	return policyDetails{
+		PolicyID:     p.id,
+		Revision:     p.revision,
+		Version:      version,
+		Current:      version == p.current,
+		CreatedAt:    p.versions[version-1].createdAt,
+		AccessPolicy: p.versions[version-1].AccessPolicy}
+}
+
+// addVersion adds policy, created at createdAt, as the current version
+// of p
+func (p *accessPolicy) addVersion(policy vault.AccessPolicy, createdAt string) {
+	p.versions = append(p.versions, policyVersion{AccessPolicy: policy,
+		createdAt: createdAt})
+	p.current = len(p.versions)
+	p.revision++
+}
+
+func (v *Vault) accessPolicy(policyID string) (*accessPolicy, error) {
+	policy, ok := v.accessPolicies[policyID]
+	if !ok {
+		return nil, errNotFound
+	}
+	return policy, nil
+}
+
+func (v *Vault) createAccessPolicy(r *request) (interface{}, error) {
+	var policy vault.AccessPolicy
+	if err := r.decode(&policy); err != nil {
+		return nil, err
+	}
+	if policy.Name == "" || policy.Role == "" {
+		return nil, badRequest("name and role are required")
+	}
+	for _, existing := range v.accessPolicies {
+		if existing.versions[existing.current-1].Name == policy.Name {
+			return nil, errorf(http.StatusConflict, "Policy %s already exists", policy.Name)
+		}
+	}
+	created := &accessPolicy{id: v.newGUID()}
+	created.addVersion(policy, v.timestamp())
+	v.accessPolicies[created.id] = created
+	return created.details(created.current), nil
+}
+
+func (v *Vault) updateAccessPolicy(r *request) (interface{}, error) {
+	var request vault.UpdateAccessPolicyRequest
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	json.Unmarshal(r.body, &fields)
+
+	policy, err := v.accessPolicy(request.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Revision != policy.revision {
+		return nil, errorf(http.StatusConflict,
+			"Revision %d is not the current revision %d", request.Revision,
+			policy.revision)
+	}
+	updated := policy.versions[policy.current-1].AccessPolicy
+	if request.Role != "" {
+		updated.Role = request.Role
+	}
+	if request.TokenizationPermissions != nil {
+		updated.TokenizationPermissions = request.TokenizationPermissions
+	}
+	if desc, ok := fields["desc"]; ok {
+		updated.Description = request.Description
+		if string(desc) == "null" {
+			updated.Description = ""
+		}
+	}
+	if request.Principals != nil {
+		updated.Principals = request.Principals
+	}
+	if request.Tags != nil {
+		updated.Tags = request.Tags
+	}
+	policy.addVersion(updated, v.timestamp())
+	return policy.details(policy.current), nil
+}
+
+func (v *Vault) getAccessPolicy(r *request) (interface{}, error) {
+	var request vault.PolicyVersion
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	policy, err := v.accessPolicy(request.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	version := request.Version
+	if version == 0 {
+		version = policy.current
+	}
+	if version < 0 || version > len(policy.versions) {
+		return nil, errNotFound
+	}
+	return policy.details(version), nil
+}
+
+func (v *Vault) listAccessPolicies(r *request) (interface{}, error) {
+	var options vault.ListOptions
+	if err := r.decode(&options); err != nil {
+		return nil, err
+	}
+	policies := []interface{}{}
+	for _, policy := range v.accessPolicies {
+		details := policy.details(policy.current)
+		if strings.HasPrefix(details.Name, options.Prefix) {
+			policies = append(policies, details)
+		}
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].(policyDetails).Name < policies[j].(policyDetails).Name
+	})
+	page, nextToken, err := pageOf(policies, options.MaxItems, options.NextToken,
+		options.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"policies": page, "next_token": nextToken}, nil
+}
+
+func (v *Vault) listPolicyVersions(r *request) (interface{}, error) {
+	var request vault.PolicyVersion
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	policy, err := v.accessPolicy(request.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	versions := []map[string]interface{}{}
+	for i := range policy.versions {
+		versions = append(versions, map[string]interface{}{
+			"version":    i + 1,
+			"current":    i+1 == policy.current,
+			"created_at": policy.versions[i].createdAt})
+	}
+	return map[string]interface{}{"policy_id": policy.id, "versions": versions}, nil
+}
+
+func (v *Vault) setPolicyVersion(r *request) (interface{}, error) {
+	var request vault.PolicyVersion
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	policy, err := v.accessPolicy(request.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Version < 1 || request.Version > len(policy.versions) {
+		return nil, badRequest("Policy has no version %d", request.Version)
+	}
+	policy.current = request.Version
+	policy.revision++
+	return policy.details(policy.current), nil
+}
+
+func (v *Vault) deleteAccessPolicy(r *request) (interface{}, error) {
+	var request vault.PolicyVersion
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	policy, err := v.accessPolicy(request.PolicyID)
+	if err != nil {
+		return nil, err
+	}
+	delete(v.accessPolicies, policy.id)
+	return map[string]string{"result": "Policy deleted"}, nil
+}
+
+// pageOf returns the page of items a list request asks for, with only
+// fields if given, and the token of the next page. Tokens are offsets.
+func pageOf(items []interface{}, maxItems int, nextToken string,
+	fields []string) ([]interface{}, string, error) {
+	offset := 0
+	if nextToken != "" {
+		var err error
+		offset, err = strconv.Atoi(nextToken)
+		if err != nil || offset < 0 || offset > len(items) {
+			return nil, "", badRequest("Invalid next_token %q", nextToken)
+		}
+	}
+	items = items[offset:]
+	next := ""
+	if maxItems > 0 && maxItems < len(items) {
+		items = items[:maxItems]
+		next = strconv.Itoa(offset + maxItems)
+	}
+	if len(fields) == 0 {
+		return items, next, nil
+	}
+	selected := []interface{}{}
+	for _, item := range items {
+		data, _ := json.Marshal(item)
+		object := map[string]interface{}{}
+		json.Unmarshal(data, &object)
+		kept := map[string]interface{}{}
+		for _, field := range fields {
+			if value, ok := object[field]; ok {
+				kept[field] = value
+			}
+		}
+		selected = append(selected, kept)
+	}
+	return selected, next, nil
+}
@@ -0,0 +1,137 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Server is a mock vault served over TLS on a local port, for tests
+type Server struct {
+	*httptest.Server
+	Vault *Vault
+}
+
+// NewServer starts a mock vault. Close stops it.
+func NewServer(config Config) *Server {
+	v := New(config)
+	return &Server{Server: httptest.NewTLSServer(v), Vault: v}
+}
+
+// Host returns the host:port of the server
+func (s *Server) Host() string {
+	return s.Listener.Addr().String()
+}
+
+// LoginURL returns the login URL of the server
+func (s *Server) LoginURL() string {
+	return LoginURL(s.Host(), s.Vault.VaultID())
+}
+
+// CACertPEM returns the certificate of the server, which cryptocli
+// login takes as CA certificate
+func (s *Server) CACertPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE",
+		Bytes: s.Certificate().Raw})
+}
+
+// Client returns an SDK client of the server authenticating with
+// token, e.g. from Vault.NewSession
+func (s *Server) Client(token string) *vault.Client {
+	return vault.NewClient(s.Host(), token, s.Server.Client())
+}
+
+// LoginURL returns the login URL of the vault vaultID at host:port
+func LoginURL(host, vaultID string) string {
+	return (&url.URL{Scheme: "https", Host: host,
+		Path: apiRoot + "Login/" + vaultID + "/"}).String()
+}
+
+// NewCertificate returns a self-signed TLS certificate for localhost
+// and hosts, and the certificate in PEM format to verify it with. An
+// empty or unspecified host, such as 0.0.0.0, stands for all addresses
+// of the machine: those of its network interfaces and its host name.
+func NewCertificate(hosts ...string) (tls.Certificate, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "Mock Cryptographic APIs Vault"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+			ips, names := machineAddresses()
+			template.IPAddresses = append(template.IPAddresses, ips...)
+			template.DNSNames = append(template.DNSNames, names...)
+		} else if ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// machineAddresses returns the addresses of the network interfaces of
+// the machine, but the loopback ones, and its host name
+func machineAddresses() ([]net.IP, []string) {
+	ips := []net.IP{}
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() {
+				ips = append(ips, ipNet.IP)
+			}
+		}
+	}
+	names := []string{}
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		names = append(names, hostname)
+	}
+	return ips, names
+}
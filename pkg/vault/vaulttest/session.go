@@ -0,0 +1,84 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+func init() {
+	addRoutes(
+		route{method: http.MethodPost, pattern: "Login/{}", public: true,
+			audit: "User logged in", handle: (*Vault).login},
+		route{method: http.MethodPost, pattern: "Renew", audit: "Session renewed",
+			handle: (*Vault).renew},
+	)
+}
+
+// login logs in with the credentials of a configured user, or with
+// any OpenID Connect ID token. The ID token is not verified; its email
+// or subject claim names the user.
+func (v *Vault) login(r *request) (interface{}, error) {
+	if r.params[0] != v.config.VaultID {
+		return nil, errNotFound
+	}
+	var login vault.LoginRequest
+	if err := r.decode(&login); err != nil {
+		return nil, err
+	}
+	switch {
+	case login.IDToken != "":
+		r.user = idTokenUser(login.IDToken)
+	case login.Username != "" && v.config.Users[login.Username] == login.Password:
+		r.user = login.Username
+	default:
+		r.user = login.Username
+		return nil, errorf(http.StatusUnauthorized, "Invalid username or password")
+	}
+	session := v.newSession(r.user)
+	return &session, nil
+}
+
+// idTokenUser returns the user an ID token names
+func idTokenUser(idToken string) string {
+	claims := struct {
+		Email   string `json:"email"`
+		Subject string `json:"sub"`
+	}{}
+	if parts := strings.Split(idToken, "."); len(parts) == 3 {
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(payload, &claims)
+	}
+	switch {
+	case claims.Email != "":
+		return claims.Email
+	case claims.Subject != "":
+		return claims.Subject
+	}
+	return "oidc-user"
+}
+
+// renew replaces the session of the request by a new one
+func (v *Vault) renew(r *request) (interface{}, error) {
+	delete(v.sessions, r.token)
+	session := v.newSession(r.user)
+	return &session, nil
+}
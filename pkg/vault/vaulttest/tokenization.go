@@ -0,0 +1,533 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func init() {
+	addRoutes(
+		route{method: http.MethodPost, pattern: "token",
+			handle: tokenizeOne((*Vault).tokenize)},
+		route{method: http.MethodPost, pattern: "detoken",
+			handle: tokenizeOne((*Vault).detokenize)},
+		route{method: http.MethodPost, pattern: "detoken_mask",
+			handle: tokenizeOne((*Vault).detokenizeMask)},
+		route{method: http.MethodPost, pattern: "mask", handle: tokenizeOne((*Vault).mask)},
+		route{method: http.MethodPost, pattern: "rekey", handle: tokenizeOne((*Vault).rekey)},
+		route{method: http.MethodPost, pattern: "batch/token",
+			handle: tokenizeBatch((*Vault).tokenize)},
+		route{method: http.MethodPost, pattern: "batch/detoken",
+			handle: tokenizeBatch((*Vault).detokenize)},
+		route{method: http.MethodPost, pattern: "batch/mask",
+			handle: tokenizeBatch((*Vault).mask)},
+		route{method: http.MethodPost, pattern: "batch/rekey",
+			handle: tokenizeBatch((*Vault).rekey)},
+		route{method: http.MethodPost, pattern: "CreateTokenPolicy",
+			audit: "Tokenization policy saved", handle: (*Vault).createTokenPolicy},
+		route{method: http.MethodGet, pattern: "GetTokenPolicy/{}",
+			handle: (*Vault).getTokenPolicy},
+		route{method: http.MethodGet, pattern: "GetTokenPolicies",
+			handle: (*Vault).listTokenPolicies},
+		route{method: http.MethodDelete, pattern: "DeleteTokenPolicy/{}",
+			audit: "Tokenization policy deleted", handle: (*Vault).deleteTokenPolicy},
+		route{method: http.MethodPost, pattern: "CreateMaskPolicy",
+			audit: "Mask policy saved", handle: (*Vault).createMaskPolicy},
+		route{method: http.MethodGet, pattern: "GetMaskPolicy/{}",
+			handle: (*Vault).getMaskPolicy},
+		route{method: http.MethodGet, pattern: "GetMaskPolicies",
+			handle: (*Vault).listMaskPolicies},
+		route{method: http.MethodDelete, pattern: "DeleteMaskPolicy/{}",
+			audit: "Mask policy deleted", handle: (*Vault).deleteMaskPolicy},
+		route{method: http.MethodPost, pattern: "GetTokenizationInfo",
+			handle: (*Vault).getTokenizationInfo},
+		route{method: http.MethodPost, pattern: "GetTokenizationSettings",
+			handle: (*Vault).getTokenizationSettings},
+		route{method: http.MethodPost, pattern: "UpdateTokenizationSettings",
+			audit: "Tokenization settings updated", handle: (*Vault).updateTokenizationSettings},
+	)
+}
+
+// Bits of the charset options of tokenization policies
+const (
+	CharsetOptionSpace       = 1
+	CharsetOptionPunctuation = 2
+	CharsetOptionDigits      = 4
+	CharsetOptionLetters     = 8
+	CharsetOptionFullwidth   = 16
+)
+
+const (
+	digits      = "0123456789"
+	letters     = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	punctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+	fullwidth   = "０１２３４５６７８９ＡＢＣＤＥＦＧＨＩＪＫＬＭＮＯＰＱＲＳＴＵＶＷＸＹＺ" +
+		"ａｂｃｄｅｆｇｈｉｊｋｌｍｎｏｐｑｒｓｔｕｖｗｘｙｚ"
+)
+
+// charsets are the named character sets of policies
+var charsets = map[string]string{
+	"numeric":      digits,
+	"digits":       digits,
+	"alphabetic":   letters,
+	"alpha":        letters,
+	"letters":      letters,
+	"alphanumeric": digits + letters,
+	"printable":    digits + letters + punctuation,
+}
+
+// alphabet returns the characters a policy replaces: those of a named
+// charset such as numeric or alphanumeric, or else the characters of
+// charset itself, and those the charset options add. Other characters
+// are kept, which preserves the format of the data.
+func alphabet(charset string, options []string) ([]rune, error) {
+	chars, named := charsets[strings.ToLower(charset)]
+	if !named {
+		chars = charset
+	}
+	for _, option := range options {
+		bits, err := strconv.Atoi(option)
+		if err != nil || bits < 0 || bits >= 2*CharsetOptionFullwidth {
+			return nil, badRequest("Invalid charset option %q", option)
+		}
+		for bit, added := range map[int]string{
+			CharsetOptionSpace:       " ",
+			CharsetOptionPunctuation: punctuation,
+			CharsetOptionDigits:      digits,
+			CharsetOptionLetters:     letters,
+			CharsetOptionFullwidth:   fullwidth,
+		} {
+			if bits&bit != 0 {
+				chars += added
+			}
+		}
+	}
+	seen := map[rune]bool{}
+	runes := []rune{}
+	for _, c := range chars {
+		if !seen[c] {
+			seen[c] = true
+			runes = append(runes, c)
+		}
+	}
+	if len(runes) < 2 {
+		return nil, badRequest("Charset %q has less than two characters", charset)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	return runes, nil
+}
+
+// substitute replaces each character of data in alphabet, outside the
+// preserved prefix and suffix, by the character shifted by a keystream
+// of secret, forward to tokenize or backward to detokenize. The
+// keystream depends on the policy, the length of data and the
+// position, so tokens are deterministic and reversible.
+func substitute(secret []byte, policy, data string, alphabet []rune, prefix, suffix int,
+	forward bool) string {
+	index := map[rune]int{}
+	for i, c := range alphabet {
+		index[c] = i
+	}
+	runes := []rune(data)
+	n := len(alphabet)
+	for i := prefix; i < len(runes)-suffix; i++ {
+		position, ok := index[runes[i]]
+		if !ok {
+			continue
+		}
+		h := hmac.New(sha256.New, secret)
+		fmt.Fprintf(h, "%s\x00%d\x00%d", policy, len(runes), i)
+		shift := int(binary.BigEndian.Uint64(h.Sum(nil)) % uint64(n))
+		if !forward {
+			shift = n - shift
+		}
+		runes[i] = alphabet[(position+shift)%n]
+	}
+	return string(runes)
+}
+
+// tokenPolicy returns a tokenization policy by name
+func (v *Vault) tokenPolicy(name string) (*vault.TokenizationPolicy, error) {
+	policy, ok := v.tokenPolicies[name]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "Tokenization policy %s does not exist", name)
+	}
+	return policy, nil
+}
+
+// tokenKey returns the key version tokenizing with policy, the version
+// keyGUID if given or else the latest version of the policy key
+func (v *Vault) tokenKey(policy *vault.TokenizationPolicy,
+	keyGUID string) (*keyVersion, error) {
+	if keyGUID == "" {
+		keyGUID = policy.KeyGUID
+	}
+	k, version, err := v.activeKey(keyGUID, AlgorithmAES, AlgorithmHMAC)
+	if err != nil {
+		return nil, err
+	}
+	if k.guid != policy.KeyGUID {
+		return nil, badRequest("Key %s is not the key of policy %s", keyGUID, policy.Name)
+	}
+	return version, nil
+}
+
+// tokenRef is the reference of a token in v.tokens
+func tokenRef(policy, token string) string {
+	return policy + "\x00" + token
+}
+
+// tokenize returns the token of request.TokenData and records the key
+// version of the token for detokenize and rekey
+func (v *Vault) tokenize(request vault.TokenizeRequest) (string, error) {
+	policy, err := v.tokenPolicy(request.PolicyName)
+	if err != nil {
+		return "", err
+	}
+	version, err := v.tokenKey(policy, request.KeyGUID)
+	if err != nil {
+		return "", err
+	}
+	chars, err := alphabet(policy.Charset, policy.CharsetOption)
+	if err != nil {
+		return "", err
+	}
+	token := substitute(version.secret, policy.Name, request.TokenData, chars,
+		policy.PreservedPrefixLength, policy.PreservedSuffixLength, true)
+	v.tokens[tokenRef(policy.Name, token)] = version.guid
+	return token, nil
+}
+
+// detokenize returns the data of a token, detokenized with the key
+// version request.KeyGUID, or the version that made the token
+func (v *Vault) detokenize(request vault.TokenizeRequest) (string, error) {
+	policy, err := v.tokenPolicy(request.PolicyName)
+	if err != nil {
+		return "", err
+	}
+	keyGUID := request.KeyGUID
+	if keyGUID == "" {
+		keyGUID = v.tokens[tokenRef(policy.Name, request.TokenData)]
+	}
+	version, err := v.tokenKey(policy, keyGUID)
+	if err != nil {
+		return "", err
+	}
+	chars, err := alphabet(policy.Charset, policy.CharsetOption)
+	if err != nil {
+		return "", err
+	}
+	return substitute(version.secret, policy.Name, request.TokenData, chars,
+		policy.PreservedPrefixLength, policy.PreservedSuffixLength, false), nil
+}
+
+// rekey tokenizes the data of a token again with the latest key
+// version of its policy
+func (v *Vault) rekey(request vault.TokenizeRequest) (string, error) {
+	data, err := v.detokenize(vault.TokenizeRequest{PolicyName: request.PolicyName,
+		TokenData: request.TokenData})
+	if err != nil {
+		return "", err
+	}
+	return v.tokenize(vault.TokenizeRequest{PolicyName: request.PolicyName,
+		TokenData: data})
+}
+
+// mask masks request.TokenData with the mask policy request.PolicyName
+func (v *Vault) mask(request vault.TokenizeRequest) (string, error) {
+	policy, ok := v.maskPolicies[request.PolicyName]
+	if !ok {
+		return "", errorf(http.StatusNotFound, "Mask policy %s does not exist",
+			request.PolicyName)
+	}
+	chars, err := alphabet(policy.Charset, nil)
+	if err != nil {
+		return "", err
+	}
+	masked := map[rune]bool{}
+	for _, c := range chars {
+		masked[c] = true
+	}
+	maskChar, _ := utf8.DecodeRuneInString(policy.MaskChar)
+	runes := []rune(request.TokenData)
+	for i := policy.PreservedPrefixLength; i < len(runes)-policy.PreservedSuffixLength; i++ {
+		if masked[runes[i]] {
+			runes[i] = maskChar
+		}
+	}
+	return string(runes), nil
+}
+
+// detokenizeMask detokenizes a token and masks the data, the policy
+// name giving the tokenization and mask policies separated by a comma
+func (v *Vault) detokenizeMask(request vault.TokenizeRequest) (string, error) {
+	names := strings.Split(request.PolicyName, ",")
+	if len(names) != 2 {
+		return "", badRequest("policyName must name a tokenization and a mask policy " +
+			"separated by a comma")
+	}
+	data, err := v.detokenize(vault.TokenizeRequest{
+		PolicyName: strings.TrimSpace(names[0]), TokenData: request.TokenData,
+		KeyGUID: request.KeyGUID})
+	if err != nil {
+		return "", err
+	}
+	return v.mask(vault.TokenizeRequest{PolicyName: strings.TrimSpace(names[1]),
+		TokenData: data})
+}
+
+// tokenizeOne returns the handler of a request to tokenize one value
+// with operation
+func tokenizeOne(operation func(*Vault, vault.TokenizeRequest) (string, error)) handler {
+	return func(v *Vault, r *request) (interface{}, error) {
+		var request vault.TokenizeRequest
+		if err := r.decode(&request); err != nil {
+			return nil, err
+		}
+		data, err := operation(v, request)
+		if err != nil {
+			return nil, err
+		}
+		return &vault.TokenizeResponse{TokenData: data}, nil
+	}
+}
+
+// tokenizeBatch returns the handler of a batch of records to tokenize
+// with operation
+func tokenizeBatch(operation func(*Vault, vault.TokenizeRequest) (string, error)) handler {
+	return func(v *Vault, r *request) (interface{}, error) {
+		var records []vault.TokenizeRequest
+		if err := r.decode(&records); err != nil {
+			return nil, err
+		}
+		results := make([]vault.BatchResult, len(records))
+		for i, record := range records {
+			data, err := operation(v, record)
+			if err != nil {
+				results[i].Error = batchError(err)
+				continue
+			}
+			results[i].TokenData = data
+		}
+		return results, nil
+	}
+}
+
+func (v *Vault) createTokenPolicy(r *request) (interface{}, error) {
+	policy := &vault.TokenizationPolicy{}
+	if err := r.decode(policy); err != nil {
+		return nil, err
+	}
+	if policy.Name == "" {
+		return nil, badRequest("name is required")
+	}
+	if _, err := alphabet(policy.Charset, policy.CharsetOption); err != nil {
+		return nil, err
+	}
+	k, _, err := v.activeKey(policy.KeyGUID, AlgorithmAES, AlgorithmHMAC)
+	if err != nil {
+		return nil, err
+	}
+	policy.KeyGUID = k.guid
+	_, exists := v.tokenPolicies[policy.Name]
+	if err := checkSave("Tokenization", policy.Name, policy.IsNew, exists); err != nil {
+		return nil, err
+	}
+	v.tokenPolicies[policy.Name] = policy
+	policy.IsNew = false
+	return policy, nil
+}
+
+func (v *Vault) createMaskPolicy(r *request) (interface{}, error) {
+	policy := &vault.MaskPolicy{}
+	if err := r.decode(policy); err != nil {
+		return nil, err
+	}
+	if policy.Name == "" {
+		return nil, badRequest("name is required")
+	}
+	if _, err := alphabet(policy.Charset, nil); err != nil {
+		return nil, err
+	}
+	if utf8.RuneCountInString(policy.MaskChar) != 1 {
+		return nil, badRequest("maskChar must be one character")
+	}
+	_, exists := v.maskPolicies[policy.Name]
+	if err := checkSave("Mask", policy.Name, policy.IsNew, exists); err != nil {
+		return nil, err
+	}
+	v.maskPolicies[policy.Name] = policy
+	policy.IsNew = false
+	return policy, nil
+}
+
+// checkSave checks that a new policy does not exist, and that an
+// updated one does
+func checkSave(kind, name string, isNew, exists bool) error {
+	if isNew && exists {
+		return errorf(http.StatusConflict, "%s policy %s already exists", kind, name)
+	}
+	if !isNew && !exists {
+		return errorf(http.StatusNotFound, "%s policy %s does not exist", kind, name)
+	}
+	return nil
+}
+
+func (v *Vault) getTokenPolicy(r *request) (interface{}, error) {
+	return v.tokenPolicy(r.params[0])
+}
+
+func (v *Vault) getMaskPolicy(r *request) (interface{}, error) {
+	policy, ok := v.maskPolicies[r.params[0]]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "Mask policy %s does not exist",
+			r.params[0])
+	}
+	return policy, nil
+}
+
+func (v *Vault) deleteTokenPolicy(r *request) (interface{}, error) {
+	if _, err := v.tokenPolicy(r.params[0]); err != nil {
+		return nil, err
+	}
+	delete(v.tokenPolicies, r.params[0])
+	return map[string]string{"result": "Tokenization policy " + r.params[0] + " deleted"}, nil
+}
+
+func (v *Vault) deleteMaskPolicy(r *request) (interface{}, error) {
+	if _, err := v.getMaskPolicy(r); err != nil {
+		return nil, err
+	}
+	delete(v.maskPolicies, r.params[0])
+	return map[string]string{"result": "Mask policy " + r.params[0] + " deleted"}, nil
+}
+
+func (v *Vault) listTokenPolicies(r *request) (interface{}, error) {
+	policies := map[string]interface{}{}
+	for name, policy := range v.tokenPolicies {
+		policies[name] = policy
+	}
+	return listPolicies(policies, r)
+}
+
+func (v *Vault) listMaskPolicies(r *request) (interface{}, error) {
+	policies := map[string]interface{}{}
+	for name, policy := range v.maskPolicies {
+		policies[name] = policy
+	}
+	return listPolicies(policies, r)
+}
+
+// listPolicies returns the policies matching the query of a list
+// request: name contained in the policy name, _ordering by name or
+// -name, _offset, _limit and _counts
+func listPolicies(policies map[string]interface{}, r *request) (interface{}, error) {
+	filter := strings.ToLower(r.query.Get("name"))
+	names := []string{}
+	for name := range policies {
+		if strings.Contains(strings.ToLower(name), filter) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	switch ordering := r.query.Get("_ordering"); ordering {
+	case "", "name":
+	case "-name":
+		sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	default:
+		return nil, badRequest("Invalid ordering %q", ordering)
+	}
+	count := len(names)
+	offset, limit := 0, len(names)
+	var err error
+	if value := r.query.Get("_offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return nil, badRequest("Invalid offset %q", value)
+		}
+	}
+	if value := r.query.Get("_limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 0 {
+			return nil, badRequest("Invalid limit %q", value)
+		}
+	}
+	if offset > len(names) {
+		offset = len(names)
+	}
+	names = names[offset:]
+	if limit < len(names) {
+		names = names[:limit]
+	}
+
+	result := map[string]interface{}{}
+	list := []interface{}{}
+	for _, name := range names {
+		list = append(list, policies[name])
+	}
+	result["policies"] = list
+	if r.query.Get("_counts") == "true" {
+		result["count"] = count
+	}
+	return result, nil
+}
+
+func (v *Vault) getTokenizationInfo(r *request) (interface{}, error) {
+	return map[string]int{
+		"tokenization_policies": len(v.tokenPolicies),
+		"mask_policies":         len(v.maskPolicies),
+		"keys":                  len(v.keys)}, nil
+}
+
+// tokenizationSettings is the JSON of the tokenization settings
+type tokenizationSettings struct {
+	DegradedModeAvailability bool `json:"degraded_mode_availability"`
+	OIDCEnabled              bool `json:"oidc_enabled"`
+	Revision                 int  `json:"revision"`
+}
+
+func (v *Vault) getTokenizationSettings(r *request) (interface{}, error) {
+	return v.tokenSettings, nil
+}
+
+func (v *Vault) updateTokenizationSettings(r *request) (interface{}, error) {
+	var settings vault.TokenizationSettings
+	if err := r.decode(&settings); err != nil {
+		return nil, err
+	}
+	if settings.Revision != v.tokenSettings.Revision {
+		return nil, errorf(http.StatusConflict,
+			"Revision %d is not the current revision %d", settings.Revision,
+			v.tokenSettings.Revision)
+	}
+	if settings.DegradedModeAvailability != nil {
+		v.tokenSettings.DegradedModeAvailability = *settings.DegradedModeAvailability
+	}
+	if settings.OIDCEnabled != nil {
+		v.tokenSettings.OIDCEnabled = *settings.OIDCEnabled
+	}
+	v.tokenSettings.Revision++
+	return v.tokenSettings, nil
+}
@@ -0,0 +1,188 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"net/http"
+	"sort"
+	"time"
+)
+
+func init() {
+	addRoutes(
+		route{method: http.MethodPost, pattern: "CreatePersonalAccessToken",
+			audit:  "Personal access token created",
+			handle: (*Vault).createPersonalAccessToken},
+		route{method: http.MethodPost, pattern: "UpdatePersonalAccessToken",
+			audit:  "Personal access token updated",
+			handle: (*Vault).updatePersonalAccessToken},
+		route{method: http.MethodPost, pattern: "GetPersonalAccessToken",
+			handle: (*Vault).getPersonalAccessToken},
+		route{method: http.MethodPost, pattern: "ListPersonalAccessTokens",
+			handle: (*Vault).listPersonalAccessTokens},
+		route{method: http.MethodPost, pattern: "DeletePersonalAccessToken",
+			audit:  "Personal access token deleted",
+			handle: (*Vault).deletePersonalAccessToken},
+	)
+}
+
+// personalAccessToken is a personal access token of a user. The token
+// authenticates requests as the access token of a session does until
+// it is revoked or expires.
+type personalAccessToken struct {
+	token       string
+	user        string
+	name        string
+	description string
+	expiry      int64 // Unix time
+	revoked     bool
+}
+
+// patDetails is the JSON of a personal access token, without the token
+type patDetails struct {
+	Name        string `json:"name"`
+	User        string `json:"user"`
+	Description string `json:"description"`
+	Expiry      int64  `json:"expiry"`
+	Revoked     bool   `json:"revoked"`
+}
+
+func (p *personalAccessToken) details() patDetails {
+	return patDetails{Name: p.name, User: p.user, Description: p.description,
+		Expiry: p.expiry, Revoked: p.revoked}
+}
+
+// valid reports whether p authenticates requests at now
+func (p *personalAccessToken) valid(now time.Time) bool {
+	return !p.revoked && now.Unix() < p.expiry
+}
+
+// NewPersonalAccessToken creates the personal access token name of
+// user, expiring at expiry, without a request and returns the token
+func (v *Vault) NewPersonalAccessToken(user, name string, expiry time.Time) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	pat := &personalAccessToken{token: v.newToken(), user: user, name: name,
+		expiry: expiry.Unix()}
+	v.pats[pat.token] = pat
+	return pat.token
+}
+
+// RevokePersonalAccessToken revokes the personal access token name of
+// user without a request
+func (v *Vault) RevokePersonalAccessToken(user, name string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if pat, ok := v.personalAccessToken(user, name); ok {
+		pat.revoked = true
+	}
+}
+
+func (v *Vault) personalAccessToken(user, name string) (*personalAccessToken, bool) {
+	for _, pat := range v.pats {
+		if pat.user == user && pat.name == name {
+			return pat, true
+		}
+	}
+	return nil, false
+}
+
+func (v *Vault) createPersonalAccessToken(r *request) (interface{}, error) {
+	var request vault.PersonalAccessToken
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	if request.Name == "" || request.Expiry == nil {
+		return nil, badRequest("name and expiry are required")
+	}
+	if _, ok := v.personalAccessToken(r.user, request.Name); ok {
+		return nil, errorf(http.StatusConflict,
+			"Personal access token %s already exists", request.Name)
+	}
+	pat := &personalAccessToken{token: v.newToken(), user: r.user, name: request.Name,
+		expiry: *request.Expiry}
+	if request.Description != nil {
+		pat.description = *request.Description
+	}
+	v.pats[pat.token] = pat
+	return struct {
+		patDetails
+		Token string `json:"token"`
+	}{pat.details(), pat.token}, nil
+}
+
+func (v *Vault) updatePersonalAccessToken(r *request) (interface{}, error) {
+	var request vault.PersonalAccessToken
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	pat, ok := v.personalAccessToken(r.user, request.Name)
+	if !ok {
+		return nil, errNotFound
+	}
+	if request.Description != nil {
+		pat.description = *request.Description
+	}
+	if request.Expiry != nil {
+		pat.expiry = *request.Expiry
+	}
+	if request.Revoked != nil {
+		pat.revoked = *request.Revoked
+	}
+	return pat.details(), nil
+}
+
+func (v *Vault) getPersonalAccessToken(r *request) (interface{}, error) {
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	pat, ok := v.personalAccessToken(r.user, request.Name)
+	if !ok {
+		return nil, errNotFound
+	}
+	return pat.details(), nil
+}
+
+func (v *Vault) listPersonalAccessTokens(r *request) (interface{}, error) {
+	pats := []patDetails{}
+	for _, pat := range v.pats {
+		if pat.user == r.user {
+			pats = append(pats, pat.details())
+		}
+	}
+	sort.Slice(pats, func(i, j int) bool { return pats[i].Name < pats[j].Name })
+	return map[string]interface{}{"personal_access_tokens": pats}, nil
+}
+
+func (v *Vault) deletePersonalAccessToken(r *request) (interface{}, error) {
+	var request struct {
+		Name string `json:"name"`
+	}
+	if err := r.decode(&request); err != nil {
+		return nil, err
+	}
+	pat, ok := v.personalAccessToken(r.user, request.Name)
+	if !ok {
+		return nil, errNotFound
+	}
+	delete(v.pats, pat.token)
+	return nil, nil
+}
@@ -0,0 +1,373 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vaulttest provides a mock Cryptographic APIs Vault for tests
+// and demos. The mock serves the /token/1.0/ endpoints cryptocli uses
+// from an in-memory store: sessions, personal access tokens, keys,
+// encryption with AES-GCM, signing, format preserving tokenization,
+// masking, access policies and audit messages. It answers with the
+// request and response shapes of package vault.
+//
+// In tests, NewServer starts a mock vault on a local TLS port:
+//
+//	server := vaulttest.NewServer(vaulttest.Config{})
+//	defer server.Close()
+//	client := server.Client(server.Vault.NewSession(vaulttest.DefaultUsername))
+//
+// The mock does not enforce access policies, and keys are not
+// protected. It must not hold real data.
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of Config
+const (
+	DefaultVaultID        = "00000000-0000-4000-8000-000000000001"
+	DefaultUsername       = "admin@example.com"
+	DefaultPassword       = "password"
+	DefaultSessionTimeout = time.Hour
+)
+
+// Config configures a mock vault. Zero fields take the defaults.
+type Config struct {
+	// VaultID is the vault ID of the login URL
+	VaultID string
+	// Users maps the usernames that may log in to their passwords
+	Users map[string]string
+	// SessionTimeout is the lifetime of access tokens
+	SessionTimeout time.Duration
+	// Now returns the current time, e.g. a fixed time for repeatable
+	// results. Defaults to time.Now.
+	Now func() time.Time
+	// Rand is the source of GUIDs, access tokens, secret keys and IVs.
+	// Defaults to crypto/rand; RSA and EC keys are always random.
+	Rand io.Reader
+}
+
+// Vault is a mock vault, serving the vault API as an http.Handler. A
+// Vault is safe for concurrent use.
+type Vault struct {
+	config Config
+
+	mu             sync.Mutex
+	sessions       map[string]session // by access token
+	keysetGUID     string
+	keys           map[string]*key // by key GUID
+	tokenPolicies  map[string]*vault.TokenizationPolicy
+	maskPolicies   map[string]*vault.MaskPolicy
+	tokens         map[string]string // key version GUID by policy and token
+	tokenSettings  tokenizationSettings
+	accessPolicies map[string]*accessPolicy // by policy ID
+	audit          []vault.AuditMessage
+	auditSettings  auditSettings
+	pats           map[string]*personalAccessToken // by token
+}
+
+type session struct {
+	user      string
+	expiresAt time.Time
+}
+
+// New returns an empty mock vault
+func New(config Config) *Vault {
+	if config.VaultID == "" {
+		config.VaultID = DefaultVaultID
+	}
+	if config.Users == nil {
+		config.Users = map[string]string{DefaultUsername: DefaultPassword}
+	}
+	if config.SessionTimeout == 0 {
+		config.SessionTimeout = DefaultSessionTimeout
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+	if config.Rand == nil {
+		config.Rand = rand.Reader
+	}
+	v := &Vault{
+		config:         config,
+		sessions:       map[string]session{},
+		keys:           map[string]*key{},
+		tokenPolicies:  map[string]*vault.TokenizationPolicy{},
+		maskPolicies:   map[string]*vault.MaskPolicy{},
+		tokens:         map[string]string{},
+		tokenSettings:  tokenizationSettings{Revision: 1},
+		accessPolicies: map[string]*accessPolicy{},
+		pats:           map[string]*personalAccessToken{},
+	}
+	v.keysetGUID = v.newGUID()
+	return v
+}
+
+// VaultID returns the vault ID of the login URL
+func (v *Vault) VaultID() string {
+	return v.config.VaultID
+}
+
+// NewSession starts a session of user without logging in and returns
+// its access token
+func (v *Vault) NewSession(user string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.newSession(user).AccessToken
+}
+
+func (v *Vault) newSession(user string) vault.Session {
+	token := v.newToken()
+	expiresAt := v.now().Add(v.config.SessionTimeout).Truncate(time.Second)
+	v.sessions[token] = session{user: user, expiresAt: expiresAt}
+	return vault.Session{AccessToken: token, ExpiresAt: expiresAt.Format(time.RFC3339),
+		User: user}
+}
+
+// apiError is an error response. An empty message sends no body, as
+// the vault does to deny actions and for resources it cannot find.
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	if e.message == "" {
+		return http.StatusText(e.status)
+	}
+	return e.message
+}
+
+func errorf(status int, format string, args ...interface{}) error {
+	return &apiError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+var errNotFound = &apiError{status: http.StatusNotFound}
+
+func badRequest(format string, args ...interface{}) error {
+	return errorf(http.StatusBadRequest, format, args...)
+}
+
+// request is a request to an endpoint
+type request struct {
+	user   string
+	token  string
+	params []string // path segments matched by {}
+	query  url.Values
+	header http.Header
+	body   []byte
+	host   string
+}
+
+// decode decodes the JSON body of r into v. An empty body leaves v
+// unchanged.
+func (r *request) decode(v interface{}) error {
+	if len(r.body) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return badRequest("Invalid request - %v", err)
+	}
+	return nil
+}
+
+// download is a file answered by an endpoint
+type download struct {
+	name string
+	data []byte
+}
+
+// handler serves an endpoint with v.mu held. A nil result answers
+// 204 No Content.
+type handler func(v *Vault, r *request) (interface{}, error)
+
+type route struct {
+	method  string
+	pattern string // path below /token/1.0/, {} matching a segment
+	public  bool   // served without an access token
+	audit   string // audit message of the request
+	handle  handler
+}
+
+var routes []route
+
+// addRoutes adds the routes of an API area
+func addRoutes(area ...route) {
+	routes = append(routes, area...)
+}
+
+func (rt route) match(method, path string) ([]string, bool) {
+	if method != rt.method {
+		return nil, false
+	}
+	patternSegments := strings.Split(strings.Trim(rt.pattern, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(patternSegments) {
+		return nil, false
+	}
+	var params []string
+	for i, segment := range patternSegments {
+		if segment == "{}" {
+			params = append(params, segments[i])
+		} else if segment != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+const apiRoot = "/token/" + vault.APIVersion + "/"
+
+// ServeHTTP serves the vault API
+func (v *Vault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, apiRoot) {
+		writeError(w, errNotFound)
+		return
+	}
+	path = strings.TrimPrefix(path, apiRoot)
+	for _, rt := range routes {
+		params, ok := rt.match(r.Method, path)
+		if !ok {
+			continue
+		}
+		for i := range params {
+			params[i], _ = url.PathUnescape(params[i])
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, badRequest("Error reading request - %v", err))
+			return
+		}
+		v.serve(w, rt, &request{params: params, query: r.URL.Query(),
+			header: r.Header, body: body, host: r.Host}, r.Header.Get(vault.AuthHeader))
+		return
+	}
+	writeError(w, errorf(http.StatusNotImplemented,
+		"%s %s is not supported by the mock vault", r.Method, r.URL.Path))
+}
+
+func (v *Vault) serve(w http.ResponseWriter, rt route, r *request, token string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !rt.public {
+		user, ok := v.authenticate(token)
+		if !ok {
+			writeError(w, errorf(http.StatusUnauthorized, "Invalid or expired access token"))
+			return
+		}
+		r.user = user
+		r.token = token
+	}
+
+	result, err := rt.handle(v, r)
+	if rt.audit != "" {
+		v.addAuditMessage(r.user, rt.audit, err)
+	}
+	switch {
+	case err != nil:
+		writeError(w, err)
+	case result == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		if file, ok := result.(*download); ok {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition",
+				fmt.Sprintf("attachment; filename=%q", file.name))
+			w.Write(file.data)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// authenticate returns the user of the session or valid personal
+// access token token
+func (v *Vault) authenticate(token string) (string, bool) {
+	if session, ok := v.sessions[token]; ok {
+		if !v.now().After(session.expiresAt) {
+			return session.user, true
+		}
+		delete(v.sessions, token)
+	} else if pat, ok := v.pats[token]; ok && pat.valid(v.now()) {
+		return pat.user, true
+	}
+	return "", false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = &apiError{status: http.StatusInternalServerError, message: err.Error()}
+	}
+	if e.message == "" {
+		w.WriteHeader(e.status)
+		return
+	}
+	writeJSON(w, e.status, map[string]string{"error": e.message})
+}
+
+// random returns n bytes from the random source of v
+func (v *Vault) random(n int) []byte {
+	b := make([]byte, n)
+	io.ReadFull(v.config.Rand, b)
+	return b
+}
+
+// newGUID returns a random UUID
+func (v *Vault) newGUID() string {
+	b := v.random(16)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func (v *Vault) newToken() string {
+	return hex.EncodeToString(v.random(32))
+}
+
+// now returns the current time in UTC
+func (v *Vault) now() time.Time {
+	return v.config.Now().UTC()
+}
+
+// timestamp returns the current time as the vault formats it
+func (v *Vault) timestamp() string {
+	return v.now().Format(time.RFC3339)
+}
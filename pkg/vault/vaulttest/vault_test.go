@@ -0,0 +1,255 @@
+/*
+ Copyright 2025 Entrust Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vaulttest
+
+import (
+	"cli/pkg/vault"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLogin(t *testing.T) {
+	server := NewServer(Config{})
+	defer server.Close()
+	ctx := context.Background()
+
+	_, err := server.Client("").Login(ctx, DefaultVaultID,
+		vault.LoginRequest{Username: DefaultUsername, Password: "wrong"})
+	var vaultError *vault.Error
+	if !errors.As(err, &vaultError) || vaultError.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("login with a wrong password: got %v, want 401", err)
+	}
+
+	session, err := server.Client("").Login(ctx, DefaultVaultID,
+		vault.LoginRequest{Username: DefaultUsername, Password: DefaultPassword})
+	if err != nil {
+		t.Fatal(err)
+	}
+	renewed, err := server.Client(session.AccessToken).Renew(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = server.Client(session.AccessToken).GetKeysetGUID(ctx); err == nil {
+		t.Error("the access token is still valid after renewal")
+	}
+	if _, err = server.Client(renewed.AccessToken).GetKeysetGUID(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+// newKey creates a key of cipher and returns its GUID
+func newKey(t *testing.T, client *vault.Client, name, cipher string) string {
+	response, err := client.CreateKey(context.Background(),
+		vault.CreateKeyRequest{Name: name, Cipher: cipher})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key struct {
+		KeyGUID string `json:"key_guid"`
+	}
+	if err = response.Decode(&key); err != nil {
+		t.Fatal(err)
+	}
+	return key.KeyGUID
+}
+
+func TestEncrypt(t *testing.T) {
+	server := NewServer(Config{})
+	defer server.Close()
+	client := server.Client(server.Vault.NewSession(DefaultUsername))
+	ctx := context.Background()
+	keyGUID := newKey(t, client, "aes", "AES-256")
+
+	plaintext := base64.StdEncoding.EncodeToString([]byte("secret"))
+	aad := base64.StdEncoding.EncodeToString([]byte("context"))
+	encrypted, err := client.Encrypt(ctx, vault.EncryptRequest{KeyGUID: keyGUID,
+		Data: plaintext, Mode: "GCM", AAD: aad})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// data encrypted before a rotation stays decryptable
+	if _, err = client.RotateKey(ctx, keyGUID); err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := client.Decrypt(ctx, vault.EncryptRequest{KeyGUID: keyGUID,
+		Data: encrypted.Data, Mode: "GCM", IV: encrypted.IV, AAD: aad})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Data != plaintext {
+		t.Errorf("decrypted %q, want %q", decrypted.Data, plaintext)
+	}
+
+	_, err = client.Decrypt(ctx, vault.EncryptRequest{KeyGUID: keyGUID,
+		Data: encrypted.Data, Mode: "GCM", IV: encrypted.IV})
+	if err == nil {
+		t.Error("decryption without the aad succeeded")
+	}
+}
+
+func TestSign(t *testing.T) {
+	server := NewServer(Config{})
+	defer server.Close()
+	client := server.Client(server.Vault.NewSession(DefaultUsername))
+	ctx := context.Background()
+	data := base64.StdEncoding.EncodeToString([]byte("message"))
+
+	for _, cipher := range []string{"RSA-2048", "EC-P256"} {
+		keyGUID := newKey(t, client, cipher, cipher)
+		signed, err := client.Sign(ctx, vault.SignRequest{KeyGUID: keyGUID, Data: data})
+		if err != nil {
+			t.Fatal(err)
+		}
+		verified, err := client.Verify(ctx, vault.VerifyRequest{KeyGUID: keyGUID,
+			Data: data, Signature: signed.Signature})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !verified.Verified {
+			t.Errorf("%s signature not verified", cipher)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	server := NewServer(Config{})
+	defer server.Close()
+	client := server.Client(server.Vault.NewSession(DefaultUsername))
+	ctx := context.Background()
+	keyGUID := newKey(t, client, "tokens", "AES-256")
+
+	_, err := client.CreateTokenizationPolicy(ctx, vault.TokenizationPolicy{
+		Name: "ssn", KeyGUID: keyGUID, Charset: "numeric", PreservedSuffixLength: 4,
+		IsNew: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const ssn = "123-45-6789"
+	token, err := client.Tokenize(ctx, vault.TokenizeRequest{PolicyName: "ssn",
+		TokenData: ssn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token.TokenData) != len(ssn) || token.TokenData[3] != '-' ||
+		token.TokenData[6:] != "-6789" || token.TokenData == ssn {
+		t.Errorf("token %q does not preserve the format of %q", token.TokenData, ssn)
+	}
+
+	if _, err = client.RotateKey(ctx, keyGUID); err != nil {
+		t.Fatal(err)
+	}
+	rekeyed, err := client.Rekey(ctx, vault.TokenizeRequest{PolicyName: "ssn",
+		TokenData: token.TokenData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tokenData := range []string{token.TokenData, rekeyed.TokenData} {
+		data, err := client.Detokenize(ctx, vault.TokenizeRequest{PolicyName: "ssn",
+			TokenData: tokenData})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data.TokenData != ssn {
+			t.Errorf("detokenized %q to %q, want %q", tokenData, data.TokenData, ssn)
+		}
+	}
+}
+
+func TestPersonalAccessToken(t *testing.T) {
+	now := time.Date(2099, 1, 1, 12, 0, 0, 0, time.UTC)
+	server := NewServer(Config{Now: func() time.Time { return now }})
+	defer server.Close()
+	ctx := context.Background()
+
+	valid := server.Vault.NewPersonalAccessToken(DefaultUsername, "ci", now.Add(time.Hour))
+	revoked := server.Vault.NewPersonalAccessToken(DefaultUsername, "revoked",
+		now.Add(time.Hour))
+	server.Vault.RevokePersonalAccessToken(DefaultUsername, "revoked")
+	expired := server.Vault.NewPersonalAccessToken(DefaultUsername, "expired",
+		now.Add(-time.Hour))
+
+	details, err := server.Client(valid).GetPersonalAccessToken(ctx, "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.User != DefaultUsername || details.Revoked ||
+		details.Expiry != float64(now.Add(time.Hour).Unix()) {
+		t.Errorf("got %+v, want the details of ci", details)
+	}
+	for name, token := range map[string]string{"revoked": revoked, "expired": expired,
+		"unknown": "0123"} {
+		_, err := server.Client(token).ListPersonalAccessTokens(ctx)
+		var vaultError *vault.Error
+		if !errors.As(err, &vaultError) || vaultError.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s token: got %v, want 401", name, err)
+		}
+	}
+
+	// a token created through the API authenticates until deleted
+	client := server.Client(server.Vault.NewSession(DefaultUsername))
+	expiry := now.Add(time.Hour).Unix()
+	response, err := client.CreatePersonalAccessToken(ctx,
+		vault.PersonalAccessToken{Name: "api", Expiry: &expiry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created struct {
+		Token string `json:"token"`
+	}
+	if err = response.Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = server.Client(created.Token).GetKeysetGUID(ctx); err != nil {
+		t.Error(err)
+	}
+	if _, err = client.DeletePersonalAccessToken(ctx, "api"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = server.Client(created.Token).GetKeysetGUID(ctx); err == nil {
+		t.Error("the deleted personal access token is still valid")
+	}
+}
+
+func TestNewCertificateAllAddresses(t *testing.T) {
+	cert, _, err := NewCertificate("0.0.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ips, names := machineAddresses()
+	for _, host := range append([]string{"localhost", "127.0.0.1"}, names...) {
+		if err := leaf.VerifyHostname(host); err != nil {
+			t.Errorf("VerifyHostname(%s): %v", host, err)
+		}
+	}
+	for _, ip := range ips {
+		if err := leaf.VerifyHostname(ip.String()); err != nil {
+			t.Errorf("VerifyHostname(%s): %v", ip, err)
+		}
+	}
+	if err := leaf.VerifyHostname("0.0.0.0"); err == nil {
+		t.Errorf("VerifyHostname(0.0.0.0) succeeded")
+	}
+}